@@ -5,12 +5,15 @@ import (
 	"embed"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/rpc"
 	"time"
 
 	"github.com/BernardN38/socialstream-backend/authentication_service/handler"
 	rabbitmq_consumer "github.com/BernardN38/socialstream-backend/authentication_service/rabbitmq/consumer"
 	rabbitmq_producer "github.com/BernardN38/socialstream-backend/authentication_service/rabbitmq/producer"
+	rpc_server "github.com/BernardN38/socialstream-backend/authentication_service/rpc/server"
 	"github.com/BernardN38/socialstream-backend/authentication_service/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
@@ -37,6 +40,11 @@ func New() *Application {
 		log.Fatal(err)
 	}
 
+	// start the rpc server, the user service reserves renamed handles here
+	l, err := net.Listen("tcp", ":8081")
+	if err != nil {
+		log.Fatal(err)
+	}
 	//connect to postgres db
 	db, err := sql.Open("postgres", config.PostgresDsn)
 	if err != nil {
@@ -72,6 +80,21 @@ func New() *Application {
 		}
 	}(rabbitConsumer)
 
+	_, err = rpc_server.NewRpcServer(authService)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			go rpc.ServeConn(conn)
+		}
+	}()
+
 	tokenManager := jwtauth.New("HS256", []byte(config.JwtSecret), nil)
 
 	//init handler, inject service
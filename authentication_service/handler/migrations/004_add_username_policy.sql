@@ -0,0 +1,27 @@
+-- +goose Up
+-- handles differing only by case were allowed before, the oldest keeps its
+-- handle and the others get their id appended so the index can be built.
+-- The user service applies the same rule to its copy.
+UPDATE users
+SET username = users.username || '_' || users.id
+WHERE EXISTS (
+    SELECT 1 FROM users older
+    WHERE LOWER(older.username) = LOWER(users.username) AND older.id < users.id
+);
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+-- existing handles are grandfathered, new and renamed ones must comply
+ALTER TABLE users
+    ADD CONSTRAINT chk_username_format CHECK (username ~ '^[A-Za-z0-9_]{5,30}$') NOT VALID;
+
+CREATE TABLE username_holds
+(
+    username   text NOT NULL,
+    user_id    int NOT NULL,
+    held_until TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_username_holds_username ON username_holds(LOWER(username), held_until);
+
+-- +goose Down
+DROP TABLE username_holds;
+ALTER TABLE users DROP CONSTRAINT chk_username_format;
+DROP INDEX idx_users_username_lower;
@@ -53,6 +53,10 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, authService *s
 	if err != nil {
 		return nil, err
 	}
+	err = channel.QueueBind(queue.Name, "user.export.requested", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
 	return &RabbitMQConsumer{
 		conn:        conn,
 		channel:     channel,
@@ -105,6 +109,17 @@ func (c *RabbitMQConsumer) Consume() error {
 				}
 				c.authService.DeleteUser(ctx, deleteUserReq["userId"])
 				log.Println("auth service deleted user with id: ", deleteUserReq["userId"])
+			case "user.export.requested":
+				var exportReq service.DataExportRequestedMessage
+				err := json.Unmarshal(msg.Body, &exportReq)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.authService.ExportUserData(ctx, exportReq.ExportId, exportReq.UserId)
+				if err != nil {
+					log.Println(err)
+				}
 			default:
 				log.Println("did not recognize topic:", msg.RoutingKey)
 			}
@@ -0,0 +1,87 @@
+package rpc_server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/rpc"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/authentication_service/service"
+)
+
+// Reasons a rename is refused, reported in RenameUserResp.Rejected
+const (
+	RenameRejectedInvalid  = "invalid"
+	RenameRejectedReserved = "reserved"
+	RenameRejectedTaken    = "taken"
+	RenameRejectedConflict = "conflict"
+)
+
+type RpcServer struct {
+	authService *service.AuthSerice
+}
+
+type RenameUserReq struct {
+	UserId      int32
+	OldUsername string
+	NewUsername string
+	// HeldUntil is zero when the old handle is not held, for a change of
+	// case only
+	HeldUntil time.Time
+}
+
+// RenameUserResp is empty when the rename was applied.
+type RenameUserResp struct {
+	Rejected string
+}
+
+func NewRpcServer(authService *service.AuthSerice) (*RpcServer, error) {
+	s := &RpcServer{
+		authService: authService,
+	}
+	err := rpc.Register(s)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RenameUser reserves the new handle for the user service, which commits its
+// own copy of the rename only once this succeeds.
+func (s *RpcServer) RenameUser(req RenameUserReq, reply *RenameUserResp) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	err := s.authService.RenameUser(ctx, service.RenameUserInput(req))
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, service.ErrInvalidUsername):
+		reply.Rejected = RenameRejectedInvalid
+	case errors.Is(err, service.ErrReservedUsername):
+		reply.Rejected = RenameRejectedReserved
+	case errors.Is(err, service.ErrUsernameHeld):
+		reply.Rejected = RenameRejectedTaken
+	case errors.Is(err, service.ErrRenameConflict):
+		reply.Rejected = RenameRejectedConflict
+	default:
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// RevertRename undoes a RenameUser whose user service side failed to commit.
+func (s *RpcServer) RevertRename(req RenameUserReq, reply *bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	err := s.authService.RevertRename(ctx, service.RenameUserInput(req))
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = true
+	return nil
+}
@@ -1,6 +1,11 @@
 package service
 
-import "github.com/go-playground/validator/v10"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
 
 type CreateUserInput struct {
 	Username  string `json:"username" validate:"required"`
@@ -23,6 +28,38 @@ type UserCreatedMessage struct {
 	LastName  string `json:"lastName"`
 }
 
+// UserLoggedInMessage lets the user service reactivate an account that is
+// still inside its deactivation grace period.
+type UserLoggedInMessage struct {
+	UserId int32 `json:"userId"`
+}
+
+// RenameUserInput is a rename asked for by the user service.
+type RenameUserInput struct {
+	UserId      int32     `json:"userId"`
+	OldUsername string    `json:"oldUsername"`
+	NewUsername string    `json:"newUsername"`
+	HeldUntil   time.Time `json:"heldUntil"`
+}
+
+type DataExportRequestedMessage struct {
+	ExportId int32 `json:"exportId"`
+	UserId   int32 `json:"userId"`
+}
+
+// DataExportPartMessage carries the account record for a user's data export.
+type DataExportPartMessage struct {
+	ExportId int32            `json:"exportId"`
+	Service  string           `json:"service"`
+	Files    []DataExportFile `json:"files"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type DataExportFile struct {
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
+}
+
 func Validate(input *interface{}) error {
 	validate := validator.New()
 	err := validate.Struct(input)
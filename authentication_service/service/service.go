@@ -45,6 +45,19 @@ func (a *AuthSerice) CreateUser(ctx context.Context, createUserInput CreateUserI
 	if role == "" {
 		role = "user"
 	}
+	err := ValidateUsername(createUserInput.Username)
+	if err != nil {
+		return err
+	}
+	// handles recently given up by a rename stay with their old owner
+	held, err := a.authDbQuries.IsUsernameHeld(ctx, createUserInput.Username)
+	if err != nil {
+		log.Println(err)
+		return errors.New("database error")
+	}
+	if held {
+		return ErrUsernameHeld
+	}
 	user, err := a.authDbQuries.CreateUser(ctx, users.CreateUserParams{
 		Username: createUserInput.Username,
 		Password: createUserInput.Password,
@@ -76,6 +89,100 @@ func (a *AuthSerice) CreateUser(ctx context.Context, createUserInput CreateUserI
 	return nil
 }
 
+// RenameUser applies a rename asked for by the user service before it
+// commits its side, this service owns the handle. It is refused unless the
+// user still has OldUsername and the new handle passes the policy, is not
+// held for someone else and is not taken. The old handle is held against
+// new registrations until HeldUntil, a zero HeldUntil keeps no hold.
+func (a *AuthSerice) RenameUser(ctx context.Context, input RenameUserInput) error {
+	tx, err := a.authDb.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := a.authDbQuries.WithTx(tx)
+
+	user, err := txQuries.GetUserByIdForUpdate(ctx, input.UserId)
+	if err != nil {
+		return err
+	}
+	if user.Username != input.OldUsername {
+		return ErrRenameConflict
+	}
+	// grandfathered handles are checked too, the format constraint applies
+	// to every update of the row
+	err = ValidateUsername(input.NewUsername)
+	if err != nil {
+		return err
+	}
+	held, err := txQuries.IsUsernameHeldByOther(ctx, users.IsUsernameHeldByOtherParams{
+		Username: input.NewUsername,
+		UserID:   input.UserId,
+	})
+	if err != nil {
+		return err
+	}
+	if held {
+		return ErrUsernameHeld
+	}
+	err = txQuries.UpdateUsername(ctx, users.UpdateUsernameParams{
+		ID:       input.UserId,
+		Username: input.NewUsername,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrUsernameHeld
+		}
+		return err
+	}
+	if !input.HeldUntil.IsZero() {
+		err = txQuries.CreateUsernameHold(ctx, users.CreateUsernameHoldParams{
+			Username:  input.OldUsername,
+			UserID:    input.UserId,
+			HeldUntil: input.HeldUntil,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RevertRename undoes a RenameUser the user service could not commit. It
+// does nothing once the user no longer has NewUsername.
+func (a *AuthSerice) RevertRename(ctx context.Context, input RenameUserInput) error {
+	tx, err := a.authDb.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := a.authDbQuries.WithTx(tx)
+
+	user, err := txQuries.GetUserByIdForUpdate(ctx, input.UserId)
+	if err != nil {
+		return err
+	}
+	if user.Username != input.NewUsername {
+		return nil
+	}
+	err = txQuries.UpdateUsername(ctx, users.UpdateUsernameParams{
+		ID:       input.UserId,
+		Username: input.OldUsername,
+	})
+	if err != nil {
+		return err
+	}
+	err = txQuries.DeleteUsernameHold(ctx, users.DeleteUsernameHoldParams{
+		UserID:   input.UserId,
+		Username: input.OldUsername,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (a *AuthSerice) LoginUser(ctx context.Context, loginUserInput LoginUserInput) (users.User, error) {
 	user, err := a.authDbQuries.GetUserByUsername(ctx, loginUserInput.Username)
 	if err != nil {
@@ -84,6 +191,47 @@ func (a *AuthSerice) LoginUser(ctx context.Context, loginUserInput LoginUserInpu
 	if user.Password != loginUserInput.Password {
 		return users.User{}, errors.New("unathorized")
 	}
+	message, err := json.Marshal(UserLoggedInMessage{UserId: user.ID})
+	if err != nil {
+		return users.User{}, err
+	}
+	a.rabbitmqProducer.Publish("user.loggedin", message)
 	user.Password = ""
 	return user, nil
 }
+
+// ExportUserData answers a data export request with the account record. The
+// password is never included.
+func (a *AuthSerice) ExportUserData(ctx context.Context, exportId int32, userId int32) error {
+	part := DataExportPartMessage{
+		ExportId: exportId,
+		Service:  "auth",
+		Files:    []DataExportFile{},
+	}
+	user, err := a.authDbQuries.GetUserById(ctx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load account"
+	} else {
+		account, err := json.Marshal(struct {
+			Id       int32  `json:"id"`
+			Username string `json:"username"`
+			Email    string `json:"email"`
+			Role     string `json:"role"`
+		}{
+			Id:       user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+			Role:     user.Role,
+		})
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "account.json", Content: account})
+	}
+	message, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	return a.rabbitmqProducer.Publish("user.export.part", message)
+}
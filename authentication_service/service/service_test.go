@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -22,9 +25,13 @@ type TestDatabase struct {
 }
 
 type MockRabbitmqProducer struct {
+	topics   []string
+	messages [][]byte
 }
 
-func (m *MockRabbitmqProducer) Publish(string, []byte) error {
+func (m *MockRabbitmqProducer) Publish(topic string, message []byte) error {
+	m.topics = append(m.topics, topic)
+	m.messages = append(m.messages, message)
 	return nil
 }
 func (m *MockRabbitmqProducer) Close() error {
@@ -296,3 +303,257 @@ func TestLoginUserPasswordHidden(t *testing.T) {
 
 	TearDown(t, db)
 }
+
+func TestLoginUserPublishesLoginEvent(t *testing.T) {
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	username := "testLoginUsername"
+	password := "testLoginPassword"
+	email := "testLoginEmail"
+	_, err := authService.authDbQuries.CreateUser(ctx, users.CreateUserParams{
+		Username: username,
+		Password: password,
+		Email:    email,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	_, err = authService.LoginUser(ctx, LoginUserInput{
+		Username: username,
+		Password: "wrongpassword",
+	})
+	if err == nil {
+		t.Error(err)
+	}
+	if len(mockRabbitmqProducer.topics) != 0 {
+		t.Error("login event published for failed login")
+	}
+
+	_, err = authService.LoginUser(ctx, LoginUserInput{
+		Username: username,
+		Password: password,
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(mockRabbitmqProducer.topics) != 1 || mockRabbitmqProducer.topics[0] != "user.loggedin" {
+		t.Errorf("expected user.loggedin to be published, got %v", mockRabbitmqProducer.topics)
+	}
+	TearDown(t, db)
+}
+
+func TestExportUserDataHidesPassword(t *testing.T) {
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	password := "testExportPassword"
+	user, err := authService.authDbQuries.CreateUser(ctx, users.CreateUserParams{
+		Username: "testExportUsername",
+		Password: password,
+		Email:    "testExportEmail",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = authService.ExportUserData(ctx, 1, user.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(mockRabbitmqProducer.topics) != 1 || mockRabbitmqProducer.topics[0] != "user.export.part" {
+		t.Fatalf("expected user.export.part to be published, got %v", mockRabbitmqProducer.topics)
+	}
+	var part DataExportPartMessage
+	err = json.Unmarshal(mockRabbitmqProducer.messages[0], &part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if part.Error != "" || len(part.Files) != 1 {
+		t.Errorf("unexpected export part %+v", part)
+	}
+	if strings.Contains(string(mockRabbitmqProducer.messages[0]), password) {
+		t.Error("password included in data export")
+	}
+	TearDown(t, db)
+}
+
+func TestCreateUserUsernamePolicy(t *testing.T) {
+	testCases := []struct {
+		username string
+		err      error
+	}{
+		{username: "abc", err: ErrInvalidUsername},
+		{username: "has space", err: ErrInvalidUsername},
+		{username: "dash-name", err: ErrInvalidUsername},
+		{username: "Support", err: ErrReservedUsername},
+		{username: "ADMIN", err: ErrReservedUsername},
+	}
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	for _, v := range testCases {
+		err := authService.CreateUser(ctx, CreateUserInput{
+			Username: v.username,
+			Email:    "testPolicy@test.com",
+			Password: "testPassword",
+		}, "user")
+		if !errors.Is(err, v.err) {
+			t.Errorf("username %q: expected %v, got %v", v.username, v.err, err)
+		}
+	}
+
+	TearDown(t, db)
+}
+
+func TestCreateUserUsernameCaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	err := authService.CreateUser(ctx, CreateUserInput{
+		Username: "testUsername",
+		Email:    "testEmail@test.com",
+		Password: "testPassword",
+	}, "user")
+	if err != nil {
+		t.Error(err)
+	}
+	err = authService.CreateUser(ctx, CreateUserInput{
+		Username: "TESTUSERNAME",
+		Email:    "testEmail2@test.com",
+		Password: "testPassword",
+	}, "user")
+	if err == nil {
+		t.Error("username differing only in case allowed")
+	}
+
+	TearDown(t, db)
+}
+
+func TestCreateUserHeldUsername(t *testing.T) {
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	user, err := authService.authDbQuries.CreateUser(ctx, users.CreateUserParams{
+		Username: "testOldName",
+		Password: "testPassword",
+		Email:    "testEmail@test.com",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	err = authService.RenameUser(ctx, RenameUserInput{
+		UserId:      user.ID,
+		OldUsername: "testOldName",
+		NewUsername: "testNewName",
+		HeldUntil:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	err = authService.CreateUser(ctx, CreateUserInput{
+		Username: "testOldName",
+		Email:    "testEmail2@test.com",
+		Password: "testPassword",
+	}, "user")
+	if !errors.Is(err, ErrUsernameHeld) {
+		t.Errorf("expected held username to be rejected, got %v", err)
+	}
+	_, err = authService.LoginUser(ctx, LoginUserInput{
+		Username: "testNewName",
+		Password: "testPassword",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	TearDown(t, db)
+}
+
+func TestRenameUser(t *testing.T) {
+	ctx := context.Background()
+	db := SetupDatabase(t)
+	mockRabbitmqProducer := &MockRabbitmqProducer{}
+	authService := New(db, mockRabbitmqProducer)
+
+	user, err := authService.authDbQuries.CreateUser(ctx, users.CreateUserParams{
+		Username: "testRenamed",
+		Password: "testPassword",
+		Email:    "testEmail@test.com",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	_, err = authService.authDbQuries.CreateUser(ctx, users.CreateUserParams{
+		Username: "testTakenName",
+		Password: "testPassword",
+		Email:    "testEmail2@test.com",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	testCases := []struct {
+		oldUsername string
+		newUsername string
+		err         error
+	}{
+		{"testRenamed", "TESTTAKENNAME", ErrUsernameHeld},
+		{"testRenamed", "admin", ErrReservedUsername},
+		{"testRenamed", "bad name", ErrInvalidUsername},
+		{"testStaleName", "testFreshName", ErrRenameConflict},
+		{"testRenamed", "TestRenamed", nil},
+	}
+	for _, v := range testCases {
+		err := authService.RenameUser(ctx, RenameUserInput{
+			UserId:      user.ID,
+			OldUsername: v.oldUsername,
+			NewUsername: v.newUsername,
+		})
+		if !errors.Is(err, v.err) {
+			t.Errorf("rename %q to %q: expected %v, got %v", v.oldUsername, v.newUsername, v.err, err)
+		}
+	}
+
+	renamed := RenameUserInput{
+		UserId:      user.ID,
+		OldUsername: "TestRenamed",
+		NewUsername: "testFreshName",
+		HeldUntil:   time.Now().Add(time.Hour),
+	}
+	err = authService.RenameUser(ctx, renamed)
+	if err != nil {
+		t.Error(err)
+	}
+	err = authService.RevertRename(ctx, renamed)
+	if err != nil {
+		t.Error(err)
+	}
+	reverted, err := authService.authDbQuries.GetUserById(ctx, user.ID)
+	if err != nil {
+		t.Error(err)
+	}
+	if reverted.Username != "TestRenamed" {
+		t.Errorf("expected reverted username TestRenamed, got %s", reverted.Username)
+	}
+	held, err := authService.authDbQuries.IsUsernameHeld(ctx, "TestRenamed")
+	if err != nil {
+		t.Error(err)
+	}
+	if held {
+		t.Error("reverted rename kept a hold on the old username")
+	}
+
+	TearDown(t, db)
+}
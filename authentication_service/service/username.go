@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidUsername  = errors.New("username must be 5 to 30 characters of letters, numbers or underscores")
+	ErrReservedUsername = errors.New("username is reserved")
+	ErrUsernameHeld     = errors.New("username is not available")
+	ErrRenameConflict   = errors.New("username was changed by another request")
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{5,30}$`)
+
+// reservedUsernames can never be registered, compared case-insensitively.
+// Keep in sync with the user service.
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"api":           {},
+	"help":          {},
+	"moderator":     {},
+	"official":      {},
+	"root":          {},
+	"security":      {},
+	"settings":      {},
+	"socialstream":  {},
+	"staff":         {},
+	"support":       {},
+	"system":        {},
+	"null":          {},
+	"undefined":     {},
+}
+
+// ValidateUsername applies the handle policy shared with the user service.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrInvalidUsername
+	}
+	if _, ok := reservedUsernames[strings.ToLower(username)]; ok {
+		return ErrReservedUsername
+	}
+	return nil
+}
@@ -10,7 +10,7 @@ WHERE id = $1 LIMIT 1;
 -- name: GetUserByUsername :one
 SELECT *
 FROM users
-WHERE username = $1 LIMIT 1;
+WHERE LOWER(username) = LOWER(sqlc.arg(username)::text) LIMIT 1;
 
 -- name: GetUserPasswordAndId :one
 SELECT id, password
@@ -35,4 +35,35 @@ VALUES ($1, $2, $3, $4) RETURNING *;
 -- name: DeleteUser :exec
 DELETE
 FROM users
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: UpdateUsername :exec
+UPDATE users SET username = $2 WHERE id = $1;
+
+-- name: CreateUsernameHold :exec
+INSERT INTO username_holds(username, user_id, held_until)
+VALUES ($1, $2, $3);
+
+-- name: IsUsernameHeld :one
+SELECT EXISTS (
+    SELECT 1 FROM username_holds
+    WHERE LOWER(username) = LOWER(sqlc.arg(username)::text) AND held_until > NOW()
+)::bool;
+
+-- name: GetUserByIdForUpdate :one
+SELECT *
+FROM users
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: IsUsernameHeldByOther :one
+SELECT EXISTS (
+    SELECT 1 FROM username_holds
+    WHERE LOWER(username) = LOWER(sqlc.arg(username)::text)
+      AND held_until > NOW()
+      AND user_id <> sqlc.arg(user_id)::int
+)::bool;
+
+-- name: DeleteUsernameHold :exec
+DELETE FROM username_holds
+WHERE user_id = $1 AND LOWER(username) = LOWER(sqlc.arg(username)::text);
@@ -6,4 +6,13 @@ CREATE TABLE users
     email      text NOT NULL UNIQUE,
     password   text NOT NULL,
     role text NOT NULL
-);
\ No newline at end of file
+);
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+
+CREATE TABLE username_holds
+(
+    username   text NOT NULL,
+    user_id    int NOT NULL,
+    held_until TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_username_holds_username ON username_holds(LOWER(username), held_until);
@@ -1,10 +1,12 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.19.1
+//   sqlc v1.22.0
 
 package users
 
-import ()
+import (
+	"time"
+)
 
 type User struct {
 	ID       int32  `json:"id"`
@@ -13,3 +15,9 @@ type User struct {
 	Password string `json:"password"`
 	Role     string `json:"role"`
 }
+
+type UsernameHold struct {
+	Username  string    `json:"username"`
+	UserID    int32     `json:"userId"`
+	HeldUntil time.Time `json:"heldUntil"`
+}
@@ -1,12 +1,13 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.19.1
+//   sqlc v1.22.0
 // source: query.sql
 
 package users
 
 import (
 	"context"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -39,6 +40,22 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const createUsernameHold = `-- name: CreateUsernameHold :exec
+INSERT INTO username_holds(username, user_id, held_until)
+VALUES ($1, $2, $3)
+`
+
+type CreateUsernameHoldParams struct {
+	Username  string    `json:"username"`
+	UserID    int32     `json:"userId"`
+	HeldUntil time.Time `json:"heldUntil"`
+}
+
+func (q *Queries) CreateUsernameHold(ctx context.Context, arg CreateUsernameHoldParams) error {
+	_, err := q.db.ExecContext(ctx, createUsernameHold, arg.Username, arg.UserID, arg.HeldUntil)
+	return err
+}
+
 const deleteUser = `-- name: DeleteUser :exec
 DELETE
 FROM users
@@ -50,6 +67,21 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const deleteUsernameHold = `-- name: DeleteUsernameHold :exec
+DELETE FROM username_holds
+WHERE user_id = $1 AND LOWER(username) = LOWER($2::text)
+`
+
+type DeleteUsernameHoldParams struct {
+	UserID   int32  `json:"userId"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) DeleteUsernameHold(ctx context.Context, arg DeleteUsernameHoldParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUsernameHold, arg.UserID, arg.Username)
+	return err
+}
+
 const getAllUsers = `-- name: GetAllUsers :many
 SELECT id, username, email, role
 FROM users
@@ -109,10 +141,30 @@ func (q *Queries) GetUserById(ctx context.Context, id int32) (User, error) {
 	return i, err
 }
 
+const getUserByIdForUpdate = `-- name: GetUserByIdForUpdate :one
+SELECT id, username, email, password, role
+FROM users
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserByIdForUpdate(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIdForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Password,
+		&i.Role,
+	)
+	return i, err
+}
+
 const getUserByUsername = `-- name: GetUserByUsername :one
 SELECT id, username, email, password, role
 FROM users
-WHERE username = $1 LIMIT 1
+WHERE LOWER(username) = LOWER($1::text) LIMIT 1
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -159,6 +211,41 @@ func (q *Queries) GetUserRoleByUserId(ctx context.Context, id int32) (string, er
 	return role, err
 }
 
+const isUsernameHeld = `-- name: IsUsernameHeld :one
+SELECT EXISTS (
+    SELECT 1 FROM username_holds
+    WHERE LOWER(username) = LOWER($1::text) AND held_until > NOW()
+)::bool
+`
+
+func (q *Queries) IsUsernameHeld(ctx context.Context, username string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUsernameHeld, username)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const isUsernameHeldByOther = `-- name: IsUsernameHeldByOther :one
+SELECT EXISTS (
+    SELECT 1 FROM username_holds
+    WHERE LOWER(username) = LOWER($1::text)
+      AND held_until > NOW()
+      AND user_id <> $2::int
+)::bool
+`
+
+type IsUsernameHeldByOtherParams struct {
+	Username string `json:"username"`
+	UserID   int32  `json:"userId"`
+}
+
+func (q *Queries) IsUsernameHeldByOther(ctx context.Context, arg IsUsernameHeldByOtherParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUsernameHeldByOther, arg.Username, arg.UserID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id, username, email, password, role
 FROM users
@@ -193,3 +280,17 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	}
 	return items, nil
 }
+
+const updateUsername = `-- name: UpdateUsername :exec
+UPDATE users SET username = $2 WHERE id = $1
+`
+
+type UpdateUsernameParams struct {
+	ID       int32  `json:"id"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) error {
+	_, err := q.db.ExecContext(ctx, updateUsername, arg.ID, arg.Username)
+	return err
+}
@@ -32,17 +32,22 @@ class MediaProcessor:
             external_id_full = data["externalIdFull"]
             external_id_compressed = data["externalIdCompressed"]
             content_type = data["contentType"]
+            rendition = data.get("rendition", "")
             print("message received by image compressing worker: ", data)
             guess = guess_extension(content_type)
             extension = guess.strip('.')
 
             image_bytes = self.image_uploader.get_image_from_s3(external_id_full)
-            if len(image_bytes.getvalue()) < 1024*1024:
+            if rendition == "banner":
+                # banners always get the fixed cover rendition, whatever their size
+                self.crop_upload_banner(image_bytes, media_id, external_id_full, external_id_compressed)
+                print("Banner rendition created, media id:", media_id)
+            elif len(image_bytes.getvalue()) < 1024*1024:
                 ch.basic_ack(delivery_tag=method.delivery_tag)
                 print("image small enough, compression skipped")
                 self.image_uploader.upload_image_to_s3(image_bytes,media_id,external_id_full,external_id_compressed, content_type)
                 return
-            if extension == "jpg":
+            elif extension == "jpg":
                 self.compress_upload_jpeg(image_bytes, media_id, external_id_full, external_id_compressed)
             elif extension == "heif":
                 self.compress_convert_upload_heic(image_bytes, image_id, external_id_full, external_id_compressed)
@@ -105,6 +110,26 @@ class MediaProcessor:
         self.image_uploader.upload_image_to_s3(out, image_id, "image/jpeg")
         return
 
+    def crop_upload_banner(self, image_bytes, media_id, external_id_full, external_id_compressed):
+        image = Image.open(image_bytes).convert("RGB")
+        # center crop to 3:1 then scale to the banner size
+        target_ratio = 3
+        if image.width / image.height > target_ratio:
+            new_width = int(image.height * target_ratio)
+            left = (image.width - new_width) // 2
+            image = image.crop((left, 0, left + new_width, image.height))
+        else:
+            new_height = int(image.width / target_ratio)
+            top = (image.height - new_height) // 2
+            image = image.crop((0, top, image.width, top + new_height))
+        image = image.resize((1500, 500))
+        out = BytesIO()
+        image.save(out, "jpeg", optimize=True, quality=80)
+        out.seek(0)
+        self.image_uploader.upload_image_to_s3(out, media_id, external_id_full, external_id_compressed, "image/jpeg")
+        out.close()
+        return
+
     def resize_image(self, image):
         if image.width > 1920 or image.height > 1080:
                 new_width, new_height = 1920, 1080
@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
@@ -70,7 +71,12 @@ func New() *Application {
 		log.Fatal(err)
 	}
 
-	rpcClient, err := rpc_client.New(userServiceRpcClient)
+	postServiceRpcClient, err := ConnectToRpcServer("post-service:8081", 5, 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rpcClient, err := rpc_client.New(userServiceRpcClient, postServiceRpcClient)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -84,6 +90,7 @@ func New() *Application {
 	if err != nil {
 		log.Fatal(err)
 	}
+	go mediaService.RunUnclaimedMediaSweep(context.Background(), 10*time.Minute)
 
 	go func() {
 
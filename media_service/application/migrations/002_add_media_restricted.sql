@@ -0,0 +1,7 @@
+-- +goose Up
+-- set for media attached to posts that are not public, serving it then needs
+-- the post service to confirm the viewer can read one of those posts
+ALTER TABLE media ADD COLUMN is_restricted BOOLEAN NOT NULL DEFAULT false;
+
+-- +goose Down
+ALTER TABLE media DROP COLUMN is_restricted;
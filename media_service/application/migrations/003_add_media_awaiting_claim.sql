@@ -0,0 +1,9 @@
+-- +goose Up
+-- set for post attachments until the post service confirms a post, draft or
+-- scheduled post holds on to them, unclaimed ones are swept
+ALTER TABLE media ADD COLUMN awaiting_claim BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX idx_media_awaiting_claim ON media(upload_date) WHERE awaiting_claim;
+
+-- +goose Down
+DROP INDEX idx_media_awaiting_claim;
+ALTER TABLE media DROP COLUMN awaiting_claim;
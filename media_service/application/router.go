@@ -19,13 +19,20 @@ func SetupRouter(h *handler.Handler, tm *jwtauth.JWTAuth) *chi.Mux {
 
 	r.Get("/api/v1/media/health", h.CheckHealth)
 	r.Get("/api/v1/media/users/{userId}", h.GetUserProfileImage)
+	r.Get("/api/v1/media/users/{userId}/bannerImage", h.GetUserBannerImage)
 	r.Get("/api/v1/media/all", h.GetAllMedia)
-	r.Get("/api/v1/media/{mediaId}", h.GetMedia)
+	// Optionally authenticated routes
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(tm))
+		r.Get("/api/v1/media/{mediaId}", h.GetMedia)
+	})
 	// Protected routes
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(tm))
 		r.Use(jwtauth.Authenticator)
+		r.Use(h.TrackPresence)
 		r.Post("/api/v1/media/users/{userId}/profileImage", h.UploadUserProfileImage)
+		r.Post("/api/v1/media/users/{userId}/bannerImage", h.UploadUserBannerImage)
 	})
 	return r
 }
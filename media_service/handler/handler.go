@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,6 +15,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/jwtauth/v5"
 	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
 )
 
 type Handler struct {
@@ -41,13 +45,29 @@ func (h *Handler) GetAllMedia(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetUserProfileImage(w http.ResponseWriter, r *http.Request) {
+	h.getProfileMedia(w, r, h.mediaService.GetUserProfileImage)
+}
+
+func (h *Handler) UploadUserProfileImage(w http.ResponseWriter, r *http.Request) {
+	h.uploadProfileMedia(w, r, h.mediaService.UploadUserProfileImage)
+}
+
+func (h *Handler) GetUserBannerImage(w http.ResponseWriter, r *http.Request) {
+	h.getProfileMedia(w, r, h.mediaService.GetUserBannerImage)
+}
+
+func (h *Handler) UploadUserBannerImage(w http.ResponseWriter, r *http.Request) {
+	h.uploadProfileMedia(w, r, h.mediaService.UploadUserBannerImage)
+}
+
+func (h *Handler) getProfileMedia(w http.ResponseWriter, r *http.Request, get func(context.Context, int32) (*minio.Object, error)) {
 	userId := chi.URLParam(r, "userId")
 	userIdInt, err := strconv.Atoi(userId)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	minioObject, err := h.mediaService.GetUserProfileImage(r.Context(), int32(userIdInt))
+	minioObject, err := get(r.Context(), int32(userIdInt))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNoContent)
 		return
@@ -64,7 +84,7 @@ func (h *Handler) GetUserProfileImage(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *Handler) UploadUserProfileImage(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) uploadProfileMedia(w http.ResponseWriter, r *http.Request, upload func(context.Context, int32, multipart.File, *multipart.FileHeader) error) {
 	userId := chi.URLParam(r, "userId")
 	userIdInt, err := strconv.Atoi(userId)
 	if err != nil {
@@ -97,10 +117,10 @@ func (h *Handler) UploadUserProfileImage(w http.ResponseWriter, r *http.Request)
 		return
 	}
 	defer file.Close()
-	err = h.mediaService.UploadUserProfileImage(r.Context(), int32(ctxUserId), file, header)
+	err = upload(r.Context(), int32(ctxUserId), file, header)
 	if err != nil {
 		log.Println(err)
-		http.Error(w, "Unable to proccess upload", http.StatusBadRequest)
+		http.Error(w, "Unable to proccess upload: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 }
@@ -112,13 +132,26 @@ func (h *Handler) GetMedia(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	restricted, err := h.mediaService.CheckMediaAccess(r.Context(), viewerIdFromContext(r), int32(convertedMediaId))
+	if errors.Is(err, service.ErrMediaNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	object, err := h.mediaService.GetMediaCompressed(r.Context(), int32(convertedMediaId))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	// Set cache-related headers
-	w.Header().Set("Cache-Control", "public, max-age=600") // Cache for 1 day (86400 seconds)
+	// Set cache-related headers, shared caches must not keep restricted media
+	cacheControl := "public, max-age=600"
+	if restricted {
+		cacheControl = "private, max-age=600"
+	}
+	w.Header().Set("Cache-Control", cacheControl)
 	w.Header().Set("Expires", time.Now().Add(time.Minute*10).Format(http.TimeFormat))
 	w.Header().Set("ETag", uuid.NewString())
 	_, err = io.Copy(w, object)
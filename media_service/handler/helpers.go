@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// viewerIdFromContext returns the user id of a verified token when one was
+// sent, or 0 for anonymous requests on routes where auth is optional.
+func viewerIdFromContext(r *http.Request) int32 {
+	token, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil || token == nil {
+		return 0
+	}
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0
+	}
+	return int32(userId)
+}
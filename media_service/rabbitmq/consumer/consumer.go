@@ -37,6 +37,19 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, mediaService *
 		conn.Close()
 		return nil, err
 	}
+	err = channel.ExchangeDeclare(
+		"user_events",
+		"topic",
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
 	queue, err := channel.QueueDeclare(
 		queueName,
 		false,
@@ -62,6 +75,10 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, mediaService *
 	if err != nil {
 		return nil, err
 	}
+	err = channel.QueueBind(queue.Name, "user.export.requested", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	return &RabbitMQConsumer{
 		conn:         conn,
@@ -156,6 +173,21 @@ func (c *RabbitMQConsumer) Consume() error {
 					continue
 				}
 				msg.Ack(true)
+			case "user.export.requested":
+				exportMsg := DataExportRequestedMsg{}
+				err := json.Unmarshal(msg.Body, &exportMsg)
+				if err != nil {
+					log.Println(err)
+					msg.Nack(false, false)
+					continue
+				}
+				err = c.mediaService.ExportUserData(ctx, exportMsg.ExportId, exportMsg.UserId)
+				if err != nil {
+					log.Println(err)
+					msg.Nack(false, false)
+					continue
+				}
+				msg.Ack(false)
 			default:
 				log.Println("did not recognize topic:", msg.RoutingKey)
 			}
@@ -13,3 +13,8 @@ type MediaCompressedMsg struct {
 type MediaDeletedMsg struct {
 	MediaId int32 `json:"mediaId"`
 }
+
+type DataExportRequestedMsg struct {
+	ExportId int32 `json:"exportId"`
+	UserId   int32 `json:"userId"`
+}
@@ -1,6 +1,10 @@
 package rabbitmq_producer
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
 
 type UserProfileImageUploadMsg struct {
 	UserId  int32 `json:"userId"`
@@ -11,6 +15,8 @@ type MediaUploadedMsg struct {
 	ExternalIdFull       uuid.UUID `json:"externalIdFull"`
 	ExternalIdCompressed uuid.UUID `json:"externalIdCompressed"`
 	ContentType          string    `json:"contentType"`
+	// Rendition selects the image processor's output, empty for the default
+	Rendition string `json:"rendition,omitempty"`
 }
 type ExternalIdDeletedMsg struct {
 	ExternalId uuid.UUID `json:"externalId"`
@@ -18,3 +24,22 @@ type ExternalIdDeletedMsg struct {
 type MediaIdDeletedMsg struct {
 	MediaId int32 `json:"mediaId"`
 }
+
+// DataExportPartMsg lists the user's original media objects for a data
+// export, the user service streams them into the archive.
+type DataExportPartMsg struct {
+	ExportId int32              `json:"exportId"`
+	Service  string             `json:"service"`
+	Files    []DataExportFile   `json:"files"`
+	Objects  []DataExportObject `json:"objects"`
+	Error    string             `json:"error,omitempty"`
+}
+type DataExportFile struct {
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
+}
+type DataExportObject struct {
+	Name   string `json:"name"`
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
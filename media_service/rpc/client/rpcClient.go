@@ -1,6 +1,7 @@
 package rpc_client
 
 import (
+	"context"
 	"net/rpc"
 
 	"github.com/google/uuid"
@@ -14,11 +15,26 @@ type ImageUpload struct {
 
 type RpcClient struct {
 	userServiceRpcClient *rpc.Client
+	postServiceRpcClient *rpc.Client
 }
 
-func New(userServiceClient *rpc.Client) (*RpcClient, error) {
+// call makes an rpc call that gives up when ctx is done. net/rpc has no
+// deadlines of its own, a call the server never answers would otherwise
+// block forever. The reply is left to the abandoned call.
+func call(ctx context.Context, client *rpc.Client, serviceMethod string, args any, reply any) error {
+	pending := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case done := <-pending.Done:
+		return done.Error
+	}
+}
+
+func New(userServiceClient *rpc.Client, postServiceClient *rpc.Client) (*RpcClient, error) {
 	return &RpcClient{
 		userServiceRpcClient: userServiceClient,
+		postServiceRpcClient: postServiceClient,
 	}, nil
 }
 
@@ -47,3 +63,56 @@ func (rc *RpcClient) UpdateUserProfileImage(imageUpdate ProfileImageUpdateInput)
 	}
 	return nil
 }
+
+func (rc *RpcClient) GetUserBannerImageIdRpc(userId int32) (int32, error) {
+	var reply int32
+	err := rc.userServiceRpcClient.Call("RpcServer.GetUserBannerImageId", userId, &reply)
+	if err != nil {
+		return 0, err
+	}
+	return reply, nil
+}
+
+type BannerImageUpdateInput struct {
+	UserId  int32
+	MediaId int32
+}
+
+func (rc *RpcClient) UpdateUserBannerImage(imageUpdate BannerImageUpdateInput) error {
+	var reply error
+	err := rc.userServiceRpcClient.Call("RpcServer.UpdateUserBannerImageId", imageUpdate, &reply)
+	if err != nil {
+		return err
+	}
+	if reply != nil {
+		return reply
+	}
+	return nil
+}
+
+type MediaAccessReq struct {
+	MediaId  int32
+	ViewerId int32
+}
+
+// CanViewMedia asks the post service whether the viewer can read a post the
+// media is attached to.
+func (rc *RpcClient) CanViewMedia(ctx context.Context, req MediaAccessReq) (bool, error) {
+	var reply bool
+	err := call(ctx, rc.postServiceRpcClient, "RpcServer.CanViewMedia", req, &reply)
+	if err != nil {
+		return false, err
+	}
+	return reply, nil
+}
+
+// GetClaimedMedia asks the post service which of the media ids a post,
+// draft or scheduled post holds on to.
+func (rc *RpcClient) GetClaimedMedia(ctx context.Context, mediaIds []int32) ([]int32, error) {
+	var claimed []int32
+	err := call(ctx, rc.postServiceRpcClient, "RpcServer.GetClaimedMedia", mediaIds, &claimed)
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
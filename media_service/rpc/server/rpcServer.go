@@ -14,6 +14,8 @@ type ImageUpload struct {
 	UserId      int32
 	ContentType string
 	Size        int64
+	// AwaitingClaim is set by the post service for attachments
+	AwaitingClaim bool
 }
 
 // Handler is the struct which exposes the User Server methods
@@ -40,6 +42,7 @@ func (s *RpcServer) UploadImage(payload ImageUpload, reply *int32) error {
 		UserId:        payload.UserId,
 		ContentType:   payload.ContentType,
 		ContentLength: payload.Size,
+		AwaitingClaim: payload.AwaitingClaim,
 	})
 	if err != nil {
 		return err
@@ -55,3 +58,26 @@ func (s *RpcServer) DeleteImage(payload uuid.UUID, reply *error) error {
 	reply = &err
 	return nil
 }
+
+// RestrictMedia marks media attached to a post that is not public, or to a
+// scheduled post or draft. The post service calls it before the post is
+// committed so the media is never served without an access check.
+func (s *RpcServer) RestrictMedia(mediaIds []int32, reply *bool) error {
+	err := s.mediaService.RestrictMedia(context.Background(), mediaIds)
+	if err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
+
+// UnrestrictMedia lifts the restriction from media of a scheduled post or
+// draft that went live as a public post.
+func (s *RpcServer) UnrestrictMedia(mediaIds []int32, reply *bool) error {
+	err := s.mediaService.UnrestrictMedia(context.Background(), mediaIds)
+	if err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
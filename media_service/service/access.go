@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	rpc_client "github.com/BernardN38/socialstream-backend/media_service/rpc/client"
+)
+
+var ErrMediaNotFound = errors.New("media not found")
+
+// RestrictMedia marks media attached to posts that are not public, and to
+// scheduled posts and drafts until they are published.
+func (m *MediaService) RestrictMedia(ctx context.Context, mediaIds []int32) error {
+	if len(mediaIds) == 0 {
+		return nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	return m.mediaQueries.RestrictMedia(timeoutCtx, mediaIds)
+}
+
+// UnrestrictMedia serves media without an access check again, once the
+// scheduled post or draft holding it is published as a public post.
+func (m *MediaService) UnrestrictMedia(ctx context.Context, mediaIds []int32) error {
+	if len(mediaIds) == 0 {
+		return nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	return m.mediaQueries.UnrestrictMedia(timeoutCtx, mediaIds)
+}
+
+// CheckMediaAccess reports whether the media is restricted. Restricted media
+// is served to its uploader and to viewers the post service allows, anyone
+// else gets ErrMediaNotFound so its existence is not revealed. viewerId is 0
+// for anonymous requests.
+func (m *MediaService) CheckMediaAccess(ctx context.Context, viewerId int32, mediaId int32) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	access, err := m.mediaQueries.GetMediaAccess(timeoutCtx, mediaId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrMediaNotFound
+	}
+	if err != nil {
+		return false, err
+	}
+	if !access.IsRestricted || (viewerId > 0 && viewerId == access.UserID) {
+		return access.IsRestricted, nil
+	}
+	if viewerId <= 0 {
+		return true, ErrMediaNotFound
+	}
+	ok, err := m.rpcClient.CanViewMedia(timeoutCtx, rpc_client.MediaAccessReq{
+		MediaId:  mediaId,
+		ViewerId: viewerId,
+	})
+	if err != nil {
+		return true, err
+	}
+	if !ok {
+		return true, ErrMediaNotFound
+	}
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/media_service/rabbitmq/producer"
+	media_sql "github.com/BernardN38/socialstream-backend/media_service/sql/media"
+	"github.com/minio/minio-go/v7"
+)
+
+// ExportUserData answers a data export request with the user's media
+// metadata and references to the original, uncompressed objects.
+func (m *MediaService) ExportUserData(ctx context.Context, exportId int32, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	part := rabbitmq_producer.DataExportPartMsg{
+		ExportId: exportId,
+		Service:  "media",
+		Files:    []rabbitmq_producer.DataExportFile{},
+		Objects:  []rabbitmq_producer.DataExportObject{},
+	}
+	media, err := m.mediaQueries.GetMediaByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load media"
+		return m.publishDataExportPart(part)
+	}
+	if media == nil {
+		media = []media_sql.Medium{}
+	}
+	metadata, err := json.Marshal(media)
+	if err != nil {
+		return err
+	}
+	part.Files = append(part.Files, rabbitmq_producer.DataExportFile{Name: "media.json", Content: metadata})
+	for _, medium := range media {
+		key := medium.ExternalUuidFull.String()
+		info, err := m.minioClient.StatObject(timeoutCtx, m.config.MinioBucketName, key, minio.StatObjectOptions{})
+		if err != nil {
+			// the original may already be gone, export what is left
+			log.Println(err)
+			continue
+		}
+		part.Objects = append(part.Objects, rabbitmq_producer.DataExportObject{
+			Name:   fmt.Sprintf("originals/%d%s", medium.MediaID, extensionForContentType(info.ContentType)),
+			Bucket: m.config.MinioBucketName,
+			Key:    key,
+		})
+	}
+	return m.publishDataExportPart(part)
+}
+
+func (m *MediaService) publishDataExportPart(part rabbitmq_producer.DataExportPartMsg) error {
+	msg, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	return m.rabbitmqProducer.Publish("media_events", "media.export.part", msg)
+}
+
+func extensionForContentType(contentType string) string {
+	extensions, err := mime.ExtensionsByType(contentType)
+	if err != nil || len(extensions) == 0 {
+		return ""
+	}
+	return extensions[0]
+}
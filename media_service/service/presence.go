@@ -0,0 +1,22 @@
+package service
+
+import (
+	"encoding/json"
+)
+
+type UserActiveMsg struct {
+	UserId int32 `json:"userId"`
+}
+
+// ReportActivity hands the request's user to the user service as a
+// user.active event, presence is kept there.
+func (m *MediaService) ReportActivity(userId int32) error {
+	if userId <= 0 {
+		return nil
+	}
+	msg, err := json.Marshal(UserActiveMsg{UserId: userId})
+	if err != nil {
+		return err
+	}
+	return m.rabbitmqProducer.Publish("user_events", "user.active", msg)
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime/multipart"
+
+	rpc_client "github.com/BernardN38/socialstream-backend/media_service/rpc/client"
+)
+
+var ErrUnsupportedImage = errors.New("unsupported image format")
+
+// profileMedia describes one kind of image shown on a user's profile. The
+// media id of the current image is owned by the user service.
+type profileMedia struct {
+	name string
+	// aspect ratio bounds as width / height, zero leaves the side unbounded
+	minAspectRatio float64
+	maxAspectRatio float64
+	// rendition tells the image processor which output to produce
+	rendition  string
+	getMediaId func(userId int32) (int32, error)
+	setMediaId func(userId int32, mediaId int32) error
+}
+
+func (m *MediaService) profileImage() profileMedia {
+	return profileMedia{
+		name:       "profile image",
+		getMediaId: m.rpcClient.GetUserProfileImageIdRpc,
+		setMediaId: func(userId int32, mediaId int32) error {
+			return m.rpcClient.UpdateUserProfileImage(rpc_client.ProfileImageUpdateInput{
+				UserId:  userId,
+				MediaId: mediaId,
+			})
+		},
+	}
+}
+
+// bannerImage is the wide cover shown at the top of the profile, cropped to
+// 3:1 by the image processor.
+func (m *MediaService) bannerImage() profileMedia {
+	return profileMedia{
+		name:           "banner image",
+		minAspectRatio: 2,
+		maxAspectRatio: 4,
+		rendition:      "banner",
+		getMediaId:     m.rpcClient.GetUserBannerImageIdRpc,
+		setMediaId: func(userId int32, mediaId int32) error {
+			return m.rpcClient.UpdateUserBannerImage(rpc_client.BannerImageUpdateInput{
+				UserId:  userId,
+				MediaId: mediaId,
+			})
+		},
+	}
+}
+
+// validate checks the image dimensions against the aspect ratio bounds and
+// rewinds the file for the upload.
+func (p profileMedia) validate(file multipart.File) error {
+	if p.minAspectRatio == 0 && p.maxAspectRatio == 0 {
+		return nil
+	}
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	if config.Width == 0 || config.Height == 0 {
+		return ErrUnsupportedImage
+	}
+	ratio := float64(config.Width) / float64(config.Height)
+	if (p.minAspectRatio > 0 && ratio < p.minAspectRatio) || (p.maxAspectRatio > 0 && ratio > p.maxAspectRatio) {
+		return fmt.Errorf("%s aspect ratio must be between %.0f:1 and %.0f:1", p.name, p.minAspectRatio, p.maxAspectRatio)
+	}
+	return nil
+}
@@ -30,6 +30,9 @@ type ImageUpload struct {
 	UserId        int32
 	ContentType   string
 	ContentLength int64
+	// AwaitingClaim marks a post attachment that is swept unless the post
+	// service claims it
+	AwaitingClaim bool
 }
 type MediaUpdate struct {
 	MediaId       int32     `json:"mediaId"`
@@ -128,6 +131,7 @@ func (m *MediaService) UploadMedia(ctx context.Context, payload ImageUpload) (*i
 		UserID:                 payload.UserId,
 		CompressionStatus:      "started",
 		IsActive:               true,
+		AwaitingClaim:          payload.AwaitingClaim,
 	})
 	infoCh := make(chan minio.UploadInfo)
 	errCh := make(chan error)
@@ -281,12 +285,14 @@ func (m *MediaService) DeleteExternalId(ctx context.Context, externalId uuid.UUI
 	}
 }
 
-func (m *MediaService) GetUserProfileImage(ctx context.Context, userId int32) (*minio.Object, error) {
+// getProfileMedia returns the user's current image of the given kind,
+// compressed when the rendition is ready and the original until then.
+func (m *MediaService) getProfileMedia(ctx context.Context, userId int32, kind profileMedia) (*minio.Object, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
 	defer cancel()
 	respCh := make(chan *minio.Object)
 	errCh := make(chan error)
-	mediaId, err := m.rpcClient.GetUserProfileImageIdRpc(userId)
+	mediaId, err := kind.getMediaId(userId)
 	if err != nil {
 		return nil, err
 	}
@@ -320,8 +326,14 @@ func (m *MediaService) GetUserProfileImage(ctx context.Context, userId int32) (*
 	}
 }
 
-func (m *MediaService) UploadUserProfileImage(ctx context.Context, userId int32, image multipart.File, imageHeader *multipart.FileHeader) error {
-
+// uploadProfileMedia stores a new image of the given kind for the user. An
+// existing media row is reused and its previous objects are deleted, a new
+// one is registered with the user service over rpc.
+func (m *MediaService) uploadProfileMedia(ctx context.Context, userId int32, image multipart.File, imageHeader *multipart.FileHeader, kind profileMedia) error {
+	err := kind.validate(image)
+	if err != nil {
+		return err
+	}
 	timeoutCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
 	defer cancel()
 
@@ -340,7 +352,7 @@ func (m *MediaService) UploadUserProfileImage(ctx context.Context, userId int32,
 	go func() {
 		externalIdFull := uuid.New()
 		externalIdCompressed := uuid.New()
-		previousMediaId, err := m.rpcClient.GetUserProfileImageIdRpc(userId)
+		previousMediaId, err := kind.getMediaId(userId)
 		if err != nil {
 			errCh <- err
 			return
@@ -401,10 +413,7 @@ func (m *MediaService) UploadUserProfileImage(ctx context.Context, userId int32,
 				errCh <- err
 				return
 			}
-			err = m.rpcClient.UpdateUserProfileImage(rpc_client.ProfileImageUpdateInput{
-				UserId:  userId,
-				MediaId: newId,
-			})
+			err = kind.setMediaId(userId, newId)
 			if err != nil {
 				errCh <- err
 				return
@@ -425,6 +434,7 @@ func (m *MediaService) UploadUserProfileImage(ctx context.Context, userId int32,
 			ExternalIdFull:       externalIdFull,
 			ExternalIdCompressed: externalIdCompressed,
 			ContentType:          imageHeader.Header.Get("Content-Type"),
+			Rendition:            kind.rendition,
 		}
 		msgBytes, err := json.Marshal(msg)
 		if err != nil {
@@ -466,3 +476,19 @@ func (m *MediaService) UpdateCompressionStatus(ctx context.Context, mediaId int3
 	}
 	return nil
 }
+
+func (m *MediaService) GetUserProfileImage(ctx context.Context, userId int32) (*minio.Object, error) {
+	return m.getProfileMedia(ctx, userId, m.profileImage())
+}
+
+func (m *MediaService) UploadUserProfileImage(ctx context.Context, userId int32, image multipart.File, imageHeader *multipart.FileHeader) error {
+	return m.uploadProfileMedia(ctx, userId, image, imageHeader, m.profileImage())
+}
+
+func (m *MediaService) GetUserBannerImage(ctx context.Context, userId int32) (*minio.Object, error) {
+	return m.getProfileMedia(ctx, userId, m.bannerImage())
+}
+
+func (m *MediaService) UploadUserBannerImage(ctx context.Context, userId int32, image multipart.File, imageHeader *multipart.FileHeader) error {
+	return m.uploadProfileMedia(ctx, userId, image, imageHeader, m.bannerImage())
+}
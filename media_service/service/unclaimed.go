@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	media_sql "github.com/BernardN38/socialstream-backend/media_service/sql/media"
+)
+
+const (
+	// unclaimedMediaGrace is how long a post attachment can wait for its
+	// post, draft or scheduled post to commit before it is swept
+	unclaimedMediaGrace     = time.Hour
+	unclaimedMediaBatchSize = 100
+)
+
+// RunUnclaimedMediaSweep deletes post attachments that nothing claimed
+// every interval. They are left behind when an upload's reply never reached
+// the post service or the post failed after its media was uploaded.
+func (m *MediaService) RunUnclaimedMediaSweep(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := m.sweepUnclaimedMedia(ctx)
+			if err != nil {
+				log.Println("sweep unclaimed media:", err)
+			}
+		}
+	}
+}
+
+// sweepUnclaimedMedia checks a batch of attachments past the grace period
+// with the post service. Claimed ones stop awaiting a claim, the rest are
+// deleted. Media that fails to delete is retried on the next sweep.
+func (m *MediaService) sweepUnclaimedMedia(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	mediaIds, err := m.mediaQueries.GetUnclaimedMediaIds(timeoutCtx, media_sql.GetUnclaimedMediaIdsParams{
+		UploadedBefore: time.Now().Add(-unclaimedMediaGrace),
+		Limit:          unclaimedMediaBatchSize,
+	})
+	if err != nil {
+		return err
+	}
+	if len(mediaIds) == 0 {
+		return nil
+	}
+	claimed, err := m.rpcClient.GetClaimedMedia(timeoutCtx, mediaIds)
+	if err != nil {
+		return err
+	}
+	err = m.mediaQueries.ClaimMedia(timeoutCtx, claimed)
+	if err != nil {
+		return err
+	}
+	isClaimed := make(map[int32]bool, len(claimed))
+	for _, mediaId := range claimed {
+		isClaimed[mediaId] = true
+	}
+	for _, mediaId := range mediaIds {
+		if isClaimed[mediaId] {
+			continue
+		}
+		err = m.DeleteMedia(ctx, mediaId)
+		if err != nil {
+			log.Println("delete unclaimed media:", mediaId, err)
+		}
+	}
+	return nil
+}
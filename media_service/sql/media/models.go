@@ -1,6 +1,6 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.20.0
+//   sqlc v1.22.0
 
 package media_sql
 
@@ -18,4 +18,6 @@ type Medium struct {
 	CompressionStatus      string    `json:"compressionStatus"`
 	UploadDate             time.Time `json:"uploadDate"`
 	IsActive               bool      `json:"isActive"`
+	IsRestricted           bool      `json:"isRestricted"`
+	AwaitingClaim          bool      `json:"awaitingClaim"`
 }
@@ -1,19 +1,30 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.20.0
+//   sqlc v1.22.0
 // source: query.sql
 
 package media_sql
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const claimMedia = `-- name: ClaimMedia :exec
+UPDATE media SET awaiting_claim = false WHERE media_id = ANY($1::int[])
+`
+
+func (q *Queries) ClaimMedia(ctx context.Context, mediaIds []int32) error {
+	_, err := q.db.ExecContext(ctx, claimMedia, pq.Array(mediaIds))
+	return err
+}
+
 const createMedia = `-- name: CreateMedia :one
-INSERT INTO media(external_uuid_full,external_uuid_compressed,user_id,compression_status, is_active)
-VALUES ($1,$2,$3,$4,$5) RETURNING media_id
+INSERT INTO media(external_uuid_full,external_uuid_compressed,user_id,compression_status, is_active, awaiting_claim)
+VALUES ($1,$2,$3,$4,$5,$6) RETURNING media_id
 `
 
 type CreateMediaParams struct {
@@ -22,6 +33,7 @@ type CreateMediaParams struct {
 	UserID                 int32     `json:"userId"`
 	CompressionStatus      string    `json:"compressionStatus"`
 	IsActive               bool      `json:"isActive"`
+	AwaitingClaim          bool      `json:"awaitingClaim"`
 }
 
 func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (int32, error) {
@@ -31,6 +43,7 @@ func (q *Queries) CreateMedia(ctx context.Context, arg CreateMediaParams) (int32
 		arg.UserID,
 		arg.CompressionStatus,
 		arg.IsActive,
+		arg.AwaitingClaim,
 	)
 	var media_id int32
 	err := row.Scan(&media_id)
@@ -81,9 +94,10 @@ func (q *Queries) DeleteMediaByIdWithExternalIds(ctx context.Context, mediaID in
 }
 
 const getAllMedia = `-- name: GetAllMedia :many
-SELECT media_id, external_uuid_full, external_uuid_compressed, user_id, compression_status, upload_date, is_active FROM media
+SELECT media_id, external_uuid_full, external_uuid_compressed, user_id, compression_status, upload_date, is_active, is_restricted, awaiting_claim FROM media WHERE NOT is_restricted
 `
 
+// restricted media is only listed through the access check of GetMedia
 func (q *Queries) GetAllMedia(ctx context.Context) ([]Medium, error) {
 	rows, err := q.db.QueryContext(ctx, getAllMedia)
 	if err != nil {
@@ -101,6 +115,8 @@ func (q *Queries) GetAllMedia(ctx context.Context) ([]Medium, error) {
 			&i.CompressionStatus,
 			&i.UploadDate,
 			&i.IsActive,
+			&i.IsRestricted,
+			&i.AwaitingClaim,
 		); err != nil {
 			return nil, err
 		}
@@ -165,6 +181,112 @@ func (q *Queries) GetFullExternalId(ctx context.Context, mediaID int32) (uuid.UU
 	return external_uuid_full, err
 }
 
+const getMediaAccess = `-- name: GetMediaAccess :one
+SELECT user_id, is_restricted FROM media WHERE media_id = $1
+`
+
+type GetMediaAccessRow struct {
+	UserID       int32 `json:"userId"`
+	IsRestricted bool  `json:"isRestricted"`
+}
+
+func (q *Queries) GetMediaAccess(ctx context.Context, mediaID int32) (GetMediaAccessRow, error) {
+	row := q.db.QueryRowContext(ctx, getMediaAccess, mediaID)
+	var i GetMediaAccessRow
+	err := row.Scan(&i.UserID, &i.IsRestricted)
+	return i, err
+}
+
+const getMediaByUserId = `-- name: GetMediaByUserId :many
+SELECT media_id, external_uuid_full, external_uuid_compressed, user_id, compression_status, upload_date, is_active, is_restricted, awaiting_claim FROM media WHERE user_id = $1 ORDER BY media_id
+`
+
+func (q *Queries) GetMediaByUserId(ctx context.Context, userID int32) ([]Medium, error) {
+	rows, err := q.db.QueryContext(ctx, getMediaByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Medium
+	for rows.Next() {
+		var i Medium
+		if err := rows.Scan(
+			&i.MediaID,
+			&i.ExternalUuidFull,
+			&i.ExternalUuidCompressed,
+			&i.UserID,
+			&i.CompressionStatus,
+			&i.UploadDate,
+			&i.IsActive,
+			&i.IsRestricted,
+			&i.AwaitingClaim,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnclaimedMediaIds = `-- name: GetUnclaimedMediaIds :many
+SELECT media_id FROM media
+WHERE awaiting_claim AND upload_date < $2
+ORDER BY upload_date, media_id
+LIMIT $1
+`
+
+type GetUnclaimedMediaIdsParams struct {
+	Limit          int32     `json:"limit"`
+	UploadedBefore time.Time `json:"uploadedBefore"`
+}
+
+func (q *Queries) GetUnclaimedMediaIds(ctx context.Context, arg GetUnclaimedMediaIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getUnclaimedMediaIds, arg.Limit, arg.UploadedBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var media_id int32
+		if err := rows.Scan(&media_id); err != nil {
+			return nil, err
+		}
+		items = append(items, media_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restrictMedia = `-- name: RestrictMedia :exec
+UPDATE media SET is_restricted = true WHERE media_id = ANY($1::int[])
+`
+
+func (q *Queries) RestrictMedia(ctx context.Context, mediaIds []int32) error {
+	_, err := q.db.ExecContext(ctx, restrictMedia, pq.Array(mediaIds))
+	return err
+}
+
+const unrestrictMedia = `-- name: UnrestrictMedia :exec
+UPDATE media SET is_restricted = false WHERE media_id = ANY($1::int[])
+`
+
+func (q *Queries) UnrestrictMedia(ctx context.Context, mediaIds []int32) error {
+	_, err := q.db.ExecContext(ctx, unrestrictMedia, pq.Array(mediaIds))
+	return err
+}
+
 const updateCompressedExternalId = `-- name: UpdateCompressedExternalId :exec
 UPDATE media SET external_uuid_compressed = $2 WHERE external_uuid_compressed = $1
 `
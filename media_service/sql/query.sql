@@ -1,6 +1,6 @@
 -- name: CreateMedia :one
-INSERT INTO media(external_uuid_full,external_uuid_compressed,user_id,compression_status, is_active)
-VALUES ($1,$2,$3,$4,$5) RETURNING media_id;
+INSERT INTO media(external_uuid_full,external_uuid_compressed,user_id,compression_status, is_active, awaiting_claim)
+VALUES ($1,$2,$3,$4,$5,$6) RETURNING media_id;
 
 -- name: GetFullExternalId :one
 SELECT external_uuid_full FROM media WHERE media_id = $1;
@@ -52,4 +52,26 @@ SET compression_status = $2
 WHERE media_id = $1;
 
 -- name: GetAllMedia :many
-SELECT * FROM media;
+-- restricted media is only listed through the access check of GetMedia
+SELECT * FROM media WHERE NOT is_restricted;
+
+-- name: GetMediaByUserId :many
+SELECT * FROM media WHERE user_id = $1 ORDER BY media_id;
+
+-- name: RestrictMedia :exec
+UPDATE media SET is_restricted = true WHERE media_id = ANY(sqlc.arg(media_ids)::int[]);
+
+-- name: UnrestrictMedia :exec
+UPDATE media SET is_restricted = false WHERE media_id = ANY(sqlc.arg(media_ids)::int[]);
+
+-- name: GetMediaAccess :one
+SELECT user_id, is_restricted FROM media WHERE media_id = $1;
+
+-- name: GetUnclaimedMediaIds :many
+SELECT media_id FROM media
+WHERE awaiting_claim AND upload_date < sqlc.arg(uploaded_before)
+ORDER BY upload_date, media_id
+LIMIT $1;
+
+-- name: ClaimMedia :exec
+UPDATE media SET awaiting_claim = false WHERE media_id = ANY(sqlc.arg(media_ids)::int[]);
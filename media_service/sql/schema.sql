@@ -8,4 +8,7 @@ CREATE TABLE media (
     upload_date TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
     is_active BOOLEAN NOT NULL
 );
-CREATE INDEX idx_media_minio_uuid ON media(minio_uuid);
\ No newline at end of file
+CREATE INDEX idx_media_minio_uuid ON media(external_uuid_compressed);
+ALTER TABLE media ADD COLUMN is_restricted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE media ADD COLUMN awaiting_claim BOOLEAN NOT NULL DEFAULT false;
+CREATE INDEX idx_media_awaiting_claim ON media(upload_date) WHERE awaiting_claim;
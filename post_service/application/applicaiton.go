@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
@@ -70,7 +71,11 @@ func New() *Application {
 	if err != nil {
 		log.Fatal(err)
 	}
-	rpcClient, err := rpc_client.New(medaiServiceRpcClient)
+	userServiceRpcClient, err := ConnectToRpcServer("user-service:8081", 5, 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rpcClient, err := rpc_client.New(medaiServiceRpcClient, userServiceRpcClient)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -86,11 +91,20 @@ func New() *Application {
 		DB:       0,  // use default DB
 	})
 	//init service layer
-	postService, err := service.New(db, rdb, rpcClient, rabbitmqProducer, service.PostServiceConfig{})
+	postService, err := service.New(db, rdb, rpcClient, rabbitmqProducer, service.PostServiceConfig{
+		EditWindow:     config.EditWindow,
+		DraftRetention: config.DraftRetention,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	go postService.RunReactionReconcileJob(context.Background(), 10*time.Second)
+	go postService.RunScheduledPostPublisher(context.Background(), 5*time.Second)
+	go postService.RunDraftCleanupJob(context.Background(), time.Hour)
+	go postService.RunPollCloser(context.Background(), 5*time.Second)
+	go postService.RunFollowsBackfill(context.Background(), time.Minute)
+
 	// init rabbitmq Consumer and inject userService to handle messages
 	rabbitConsumer, err := rabbitmq_consumer.NewRabbitMQConsumer(rabbitmqConn, "post-service", postService)
 	if err != nil {
@@ -2,6 +2,7 @@ package application
 
 import (
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,6 +12,9 @@ type config struct {
 	PostgresDsn string `validate:"required"`
 	JwtSecret   string `validate:"required"`
 	RabbitUrl   string `validate:"required"`
+	EditWindow  time.Duration
+	// DraftRetention is how long a draft is kept after its last save
+	DraftRetention time.Duration
 }
 
 func (c *config) Validate() error {
@@ -27,11 +31,31 @@ func getEnvConfig() (*config, error) {
 	postgresDsn := os.Getenv("postgresDsn")
 	jwtSecret := os.Getenv("jwtSecret")
 	rabbitUrl := os.Getenv("rabbitmqUrl")
+	// posts can be edited for 15 minutes unless postEditWindow says otherwise
+	editWindow := 15 * time.Minute
+	if v := os.Getenv("postEditWindow"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		editWindow = parsed
+	}
+	// drafts not saved for 30 days are deleted unless draftRetention says otherwise
+	draftRetention := 30 * 24 * time.Hour
+	if v := os.Getenv("draftRetention"); v != "" {
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, err
+		}
+		draftRetention = parsed
+	}
 	config := config{
-		Port:        port,
-		PostgresDsn: postgresDsn,
-		JwtSecret:   jwtSecret,
-		RabbitUrl:   rabbitUrl,
+		Port:           port,
+		PostgresDsn:    postgresDsn,
+		JwtSecret:      jwtSecret,
+		RabbitUrl:      rabbitUrl,
+		EditWindow:     editWindow,
+		DraftRetention: draftRetention,
 	}
 	err := config.Validate()
 	if err != nil {
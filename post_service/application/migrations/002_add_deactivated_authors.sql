@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE deactivated_authors
+(
+    user_id int PRIMARY KEY,
+    deactivated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE deactivated_authors;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN author_badge text NOT NULL DEFAULT '';
+
+CREATE TABLE author_badges
+(
+    user_id int PRIMARY KEY,
+    badge text NOT NULL,
+    verified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE author_badges;
+ALTER TABLE posts DROP COLUMN author_badge;
@@ -0,0 +1,24 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN comment_count int NOT NULL DEFAULT 0;
+
+CREATE TABLE comments
+(
+    id SERIAL PRIMARY KEY,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    parent_comment_id int REFERENCES comments(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    author_badge text NOT NULL DEFAULT '',
+    body text NOT NULL,
+    reply_count int NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+CREATE INDEX idx_comments_post_id ON comments(post_id, id) WHERE parent_comment_id IS NULL;
+CREATE INDEX idx_comments_parent_comment_id ON comments(parent_comment_id, id);
+CREATE INDEX idx_comments_user_id ON comments(user_id);
+
+-- +goose Down
+DROP TABLE comments;
+ALTER TABLE posts DROP COLUMN comment_count;
@@ -0,0 +1,24 @@
+-- +goose Up
+CREATE TABLE reactions
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    reaction text NOT NULL CHECK (reaction IN ('like', 'love', 'laugh', 'wow', 'sad', 'angry')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id, reaction)
+);
+CREATE INDEX idx_reactions_post_id_created_at ON reactions(post_id, created_at DESC, user_id DESC);
+CREATE INDEX idx_reactions_user_id ON reactions(user_id);
+
+-- aggregate reconciled from the redis counters, read when they are cold
+CREATE TABLE post_reaction_counts
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    reaction text NOT NULL,
+    count int NOT NULL,
+    PRIMARY KEY (post_id, reaction)
+);
+
+-- +goose Down
+DROP TABLE post_reaction_counts;
+DROP TABLE reactions;
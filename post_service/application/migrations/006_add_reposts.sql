@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN kind text NOT NULL DEFAULT 'post';
+ALTER TABLE posts ADD CONSTRAINT chk_posts_kind CHECK (kind IN ('post', 'repost', 'quote'));
+-- reposts and quotes keep their row when the original goes, the null
+-- original_post_id is rendered as a tombstone
+ALTER TABLE posts ADD COLUMN original_post_id int REFERENCES posts(id) ON DELETE SET NULL;
+ALTER TABLE posts ADD COLUMN repost_count int NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN quote_count int NOT NULL DEFAULT 0;
+CREATE INDEX idx_posts_original_post_id ON posts(original_post_id);
+CREATE UNIQUE INDEX idx_posts_one_repost ON posts(user_id, original_post_id) WHERE kind = 'repost';
+
+-- +goose Down
+DROP INDEX idx_posts_one_repost;
+DROP INDEX idx_posts_original_post_id;
+ALTER TABLE posts DROP COLUMN quote_count;
+ALTER TABLE posts DROP COLUMN repost_count;
+ALTER TABLE posts DROP COLUMN original_post_id;
+ALTER TABLE posts DROP CONSTRAINT chk_posts_kind;
+ALTER TABLE posts DROP COLUMN kind;
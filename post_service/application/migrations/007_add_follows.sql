@@ -0,0 +1,23 @@
+-- +goose Up
+-- copy of the follow graph owned by the user service, kept in sync from
+-- user_events to build home timelines
+CREATE TABLE follows
+(
+    follower_id int NOT NULL,
+    followee_id int NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id)
+);
+CREATE INDEX idx_follows_followee_id ON follows(followee_id, follower_id);
+
+CREATE TABLE follower_counts
+(
+    user_id int PRIMARY KEY,
+    count int NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_posts_user_id_id ON posts(user_id, id DESC);
+
+-- +goose Down
+DROP INDEX idx_posts_user_id_id;
+DROP TABLE follower_counts;
+DROP TABLE follows;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- matches the (created_at, id) order used by cursor paged user post pages
+CREATE INDEX idx_posts_user_id_created_at_id ON posts(user_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX idx_posts_user_id_created_at_id;
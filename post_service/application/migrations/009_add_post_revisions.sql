@@ -0,0 +1,18 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP;
+
+-- every version of a post replaced by an edit, the current one stays on posts
+CREATE TABLE post_revisions
+(
+    revision_id SERIAL PRIMARY KEY,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    body text NOT NULL,
+    media_id int,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id, revision_id DESC);
+
+-- +goose Down
+DROP TABLE post_revisions;
+ALTER TABLE posts DROP COLUMN edited_at;
@@ -0,0 +1,33 @@
+-- +goose Up
+-- tags are stored lowercased without the leading #
+CREATE TABLE hashtags
+(
+    hashtag_id SERIAL PRIMARY KEY,
+    tag text NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE post_hashtags
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    hashtag_id int NOT NULL REFERENCES hashtags(hashtag_id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, hashtag_id)
+);
+CREATE INDEX idx_post_hashtags_hashtag_id ON post_hashtags(hashtag_id, post_id DESC);
+
+-- tag existing posts with the same rules extractHashtags uses
+INSERT INTO hashtags(tag)
+SELECT DISTINCT lower(m[2])
+FROM posts, regexp_matches(posts.body, '(^|[^[:alnum:]_&#/])#([[:alnum:]_]+)', 'g') AS m
+WHERE char_length(m[2]) <= 50 AND m[2] ~ '[[:alpha:]]'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO post_hashtags(post_id, hashtag_id)
+SELECT DISTINCT posts.id, hashtags.hashtag_id
+FROM posts, regexp_matches(posts.body, '(^|[^[:alnum:]_&#/])#([[:alnum:]_]+)', 'g') AS m
+JOIN hashtags ON hashtags.tag = lower(m[2])
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DROP TABLE post_hashtags;
+DROP TABLE hashtags;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- resolved @mentions, offsets are in characters with end exclusive and
+-- cover the @ so clients can turn the range into a link
+CREATE TABLE post_mentions
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    start_offset int NOT NULL,
+    end_offset int NOT NULL,
+    PRIMARY KEY (post_id, start_offset)
+);
+CREATE INDEX idx_post_mentions_user_id ON post_mentions(user_id, post_id DESC);
+
+-- +goose Down
+DROP TABLE post_mentions;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- ordered attachments of a post. posts.media_id keeps the first one for
+-- readers that only know about a single attachment
+CREATE TABLE post_media
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position int NOT NULL,
+    media_id int NOT NULL,
+    alt_text text NOT NULL DEFAULT '' CHECK (char_length(alt_text) <= 1000),
+    PRIMARY KEY (post_id, position)
+);
+
+INSERT INTO post_media(post_id, position, media_id)
+SELECT id, 0, media_id FROM posts WHERE media_id IS NOT NULL;
+
+ALTER TABLE post_revisions ADD COLUMN media_ids int[] NOT NULL DEFAULT '{}';
+UPDATE post_revisions SET media_ids = ARRAY[media_id] WHERE media_id IS NOT NULL;
+
+-- +goose Down
+ALTER TABLE post_revisions DROP COLUMN media_ids;
+DROP TABLE post_media;
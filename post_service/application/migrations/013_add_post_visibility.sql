@@ -0,0 +1,12 @@
+-- +goose Up
+-- who can read a post besides its author: everyone, the author's followers,
+-- the users mentioned in it, or nobody
+ALTER TABLE posts ADD COLUMN visibility text NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'followers', 'mentioned', 'private'));
+CREATE INDEX idx_post_media_media_id ON post_media(media_id);
+CREATE INDEX idx_post_revisions_media_ids ON post_revisions USING gin(media_ids);
+
+-- +goose Down
+DROP INDEX idx_post_revisions_media_ids;
+DROP INDEX idx_post_media_media_id;
+ALTER TABLE posts DROP COLUMN visibility;
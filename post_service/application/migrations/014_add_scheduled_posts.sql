@@ -0,0 +1,27 @@
+-- +goose Up
+-- posts waiting for publish_at. Attachments are uploaded when the post is
+-- scheduled, the publisher turns the row into a post and keeps it as
+-- published with the id of that post
+CREATE TABLE scheduled_posts
+(
+    id SERIAL PRIMARY KEY,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    body text NOT NULL,
+    visibility text NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'followers', 'mentioned', 'private')),
+    media_ids int[] NOT NULL DEFAULT '{}',
+    alt_texts text[] NOT NULL DEFAULT '{}',
+    publish_at TIMESTAMP NOT NULL,
+    status text NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'published', 'failed')),
+    post_id int REFERENCES posts(id) ON DELETE SET NULL,
+    attempts int NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_scheduled_posts_due ON scheduled_posts(publish_at, id) WHERE status = 'scheduled';
+CREATE INDEX idx_scheduled_posts_user_id ON scheduled_posts(user_id, publish_at);
+
+-- +goose Down
+DROP TABLE scheduled_posts;
@@ -0,0 +1,22 @@
+-- +goose Up
+-- unpublished posts saved while they are written. Attachments are uploaded
+-- with the draft, version goes up on every save so a stale autosave from
+-- another tab can be refused
+CREATE TABLE drafts
+(
+    id SERIAL PRIMARY KEY,
+    user_id int NOT NULL,
+    body text NOT NULL DEFAULT '',
+    visibility text NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'followers', 'mentioned', 'private')),
+    media_ids int[] NOT NULL DEFAULT '{}',
+    alt_texts text[] NOT NULL DEFAULT '{}',
+    version int NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_drafts_user_id ON drafts(user_id, updated_at DESC);
+CREATE INDEX idx_drafts_updated_at ON drafts(updated_at);
+
+-- +goose Down
+DROP TABLE drafts;
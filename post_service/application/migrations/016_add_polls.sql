@@ -0,0 +1,37 @@
+-- +goose Up
+-- a poll carried by a post. closed_at is set once poll.closed was published,
+-- the poll stops taking votes at ends_at either way
+CREATE TABLE polls
+(
+    post_id int PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    multiple_choice boolean NOT NULL DEFAULT false,
+    ends_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    voter_count int NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_polls_due ON polls(ends_at) WHERE closed_at IS NULL;
+
+CREATE TABLE poll_options
+(
+    post_id int NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    position int NOT NULL,
+    text text NOT NULL CHECK (char_length(text) BETWEEN 1 AND 100),
+    vote_count int NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, position)
+);
+
+-- one row per voter, positions holds every option they picked
+CREATE TABLE poll_votes
+(
+    post_id int NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    positions int[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id)
+);
+CREATE INDEX idx_poll_votes_user_id ON poll_votes(user_id);
+
+-- +goose Down
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- progress of copying the follows that predate migration 007 from the user
+-- service, a single row holding the last pair copied
+CREATE TABLE follows_backfill
+(
+    id int PRIMARY KEY CHECK (id = 1),
+    after_follower_id int NOT NULL DEFAULT 0,
+    after_followee_id int NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP
+);
+INSERT INTO follows_backfill(id) VALUES (1);
+
+-- +goose Down
+DROP TABLE follows_backfill;
@@ -18,15 +18,48 @@ func SetupRouter(h *handler.Handler, tm *jwtauth.JWTAuth) *chi.Mux {
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	r.Get("/api/v1/posts/health", h.CheckHealth)
-	r.Get("/api/v1/posts/users/{userId}", h.GetPosts)
+	r.Get("/api/v1/posts/trending", h.GetTrendingHashtags)
+	// Optionally authenticated routes
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(tm))
+		r.Get("/api/v1/posts/users/{userId}", h.GetPosts)
+		r.Get("/api/v1/posts/tags/{tag}", h.GetHashtagPosts)
+		r.Get("/api/v1/posts/{postId}", h.GetPost)
+		r.Get("/api/v1/posts/{postId}/comments", h.GetComments)
+		r.Get("/api/v1/posts/{postId}/reactions", h.GetReactors)
+		r.Get("/api/v1/posts/{postId}/revisions", h.GetPostRevisions)
+		r.Get("/api/v1/posts/{postId}/poll", h.GetPoll)
+	})
 
 	// Protected routes
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(tm))
 		r.Use(jwtauth.Authenticator)
+		r.Use(h.TrackPresence)
 		r.Get("/api/v1/posts/all", h.GetAllPosts)
+		r.Get("/api/v1/posts/timeline", h.GetTimeline)
 		r.Post("/api/v1/posts", h.CreatePost)
+		r.Get("/api/v1/posts/scheduled", h.GetScheduledPosts)
+		r.Post("/api/v1/posts/scheduled", h.SchedulePost)
+		r.Patch("/api/v1/posts/scheduled/{scheduledPostId}", h.ReschedulePost)
+		r.Delete("/api/v1/posts/scheduled/{scheduledPostId}", h.CancelScheduledPost)
+		r.Get("/api/v1/posts/drafts", h.GetDrafts)
+		r.Post("/api/v1/posts/drafts", h.CreateDraft)
+		r.Get("/api/v1/posts/drafts/{draftId}", h.GetDraft)
+		r.Patch("/api/v1/posts/drafts/{draftId}", h.UpdateDraft)
+		r.Delete("/api/v1/posts/drafts/{draftId}", h.DeleteDraft)
+		r.Post("/api/v1/posts/drafts/{draftId}/publish", h.PublishDraft)
+		r.Patch("/api/v1/posts/{postId}", h.EditPost)
 		r.Delete("/api/v1/posts/{postId}", h.DeletePost)
+		r.Post("/api/v1/posts/{postId}/comments", h.CreateComment)
+		r.Patch("/api/v1/posts/{postId}/comments/{commentId}", h.EditComment)
+		r.Delete("/api/v1/posts/{postId}/comments/{commentId}", h.DeleteComment)
+		r.Post("/api/v1/posts/{postId}/reactions/{reaction}", h.React)
+		r.Delete("/api/v1/posts/{postId}/reactions/{reaction}", h.Unreact)
+		r.Post("/api/v1/posts/{postId}/repost", h.Repost)
+		r.Delete("/api/v1/posts/{postId}/repost", h.Unrepost)
+		r.Post("/api/v1/posts/{postId}/quote", h.QuotePost)
+		r.Post("/api/v1/posts/{postId}/poll/votes", h.VotePoll)
 	})
 	return r
 }
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+const (
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 100
+)
+
+func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	var parentCommentId int
+	if v := r.URL.Query().Get("parentId"); v != "" {
+		parentCommentId, err = strconv.Atoi(v)
+		if err != nil || parentCommentId <= 0 {
+			http.Error(w, "invalid parentId", http.StatusBadRequest)
+			return
+		}
+	}
+	limit := defaultCommentPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxCommentPageSize {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	commentPage, err := h.postService.GetComments(r.Context(), viewerIdFromContext(r), int32(postId), int32(parentCommentId), service.CommentPageReq{
+		After: r.URL.Query().Get("after"),
+		Limit: int32(limit),
+	})
+	if errors.Is(err, service.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(w).Encode(commentPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+	ctxUsername := claims["username"].(string)
+
+	var commentReq CreateCommentRequest
+	err = json.NewDecoder(r.Body).Decode(&commentReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = service.Validate(commentReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	comment, err := h.postService.CreateComment(r.Context(), service.CreateCommentInput{
+		PostId:          int32(postId),
+		ParentCommentId: commentReq.ParentCommentId,
+		UserId:          int32(ctxUserId),
+		Username:        ctxUsername,
+		Body:            commentReq.Body,
+	})
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(comment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) EditComment(w http.ResponseWriter, r *http.Request) {
+	postId, commentId, ok := commentIdsFromUrl(w, r)
+	if !ok {
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var editReq EditCommentRequest
+	err := json.NewDecoder(r.Body).Decode(&editReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = service.Validate(editReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	comment, err := h.postService.EditComment(r.Context(), int32(ctxUserId), postId, commentId, editReq.Body)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(comment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	postId, commentId, ok := commentIdsFromUrl(w, r)
+	if !ok {
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err := h.postService.DeleteComment(r.Context(), int32(ctxUserId), postId, commentId)
+	if err != nil {
+		writeCommentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func commentIdsFromUrl(w http.ResponseWriter, r *http.Request) (int32, int32, bool) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	commentId, err := strconv.Atoi(chi.URLParam(r, "commentId"))
+	if err != nil || commentId <= 0 {
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return int32(postId), int32(commentId), true
+}
+
+func writeCommentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound), errors.Is(err, service.ErrCommentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrNotCommentOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
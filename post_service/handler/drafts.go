@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// CreateDraft takes the same multipart form as CreatePost, the body may be
+// empty.
+func (h *Handler) CreateDraft(w http.ResponseWriter, r *http.Request) {
+	input, closeFiles, err := draftInputFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+
+	draft, err := h.postService.CreateDraft(r.Context(), input)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(draft)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// UpdateDraft saves the whole draft again. Sending media files replaces
+// every attachment, removeMedia=true drops them and version, when set, must
+// match the stored draft.
+func (h *Handler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	draftId, err := strconv.Atoi(chi.URLParam(r, "draftId"))
+	if err != nil || draftId <= 0 {
+		http.Error(w, "invalid draft id", http.StatusBadRequest)
+		return
+	}
+	input, closeFiles, err := draftInputFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+	input.DraftId = int32(draftId)
+	input.RemoveMedia = r.FormValue("removeMedia") == "true"
+	if v := r.FormValue("version"); v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version <= 0 {
+			http.Error(w, "invalid version", http.StatusBadRequest)
+			return
+		}
+		input.Version = int32(version)
+	}
+
+	draft, err := h.postService.UpdateDraft(r.Context(), input)
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(draft)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	drafts, err := h.postService.GetDrafts(r.Context(), int32(ctxUserId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(drafts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	draftId, err := strconv.Atoi(chi.URLParam(r, "draftId"))
+	if err != nil || draftId <= 0 {
+		http.Error(w, "invalid draft id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	draft, err := h.postService.GetDraft(r.Context(), int32(ctxUserId), int32(draftId))
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(draft)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	draftId, err := strconv.Atoi(chi.URLParam(r, "draftId"))
+	if err != nil || draftId <= 0 {
+		http.Error(w, "invalid draft id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = h.postService.DeleteDraft(r.Context(), int32(ctxUserId), int32(draftId))
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) PublishDraft(w http.ResponseWriter, r *http.Request) {
+	draftId, err := strconv.Atoi(chi.URLParam(r, "draftId"))
+	if err != nil || draftId <= 0 {
+		http.Error(w, "invalid draft id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+	ctxUsername := claims["username"].(string)
+
+	post, err := h.postService.PublishDraft(r.Context(), int32(ctxUserId), ctxUsername, int32(draftId))
+	if err != nil {
+		writeDraftError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// draftInputFromForm parses the multipart draft form. The returned func
+// closes the attachment files.
+func draftInputFromForm(r *http.Request) (service.SaveDraftInput, func(), error) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err := r.ParseMultipartForm(10 << 20)
+	if err != nil {
+		log.Println(err)
+		return service.SaveDraftInput{}, nil, errors.New("Unable to parse form")
+	}
+	attachments, closeFiles, err := attachmentsFromForm(r)
+	if err != nil {
+		log.Println("Error getting media file:", err)
+		return service.SaveDraftInput{}, nil, err
+	}
+	return service.SaveDraftInput{
+		UserId:      int32(ctxUserId),
+		Body:        r.FormValue("body"),
+		Visibility:  r.FormValue("visibility"),
+		Attachments: attachments,
+	}, closeFiles, nil
+}
+
+func writeDraftError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDraftNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrDraftConflict), errors.Is(err, service.ErrTooManyDrafts):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
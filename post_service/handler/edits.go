@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// EditPost takes the same multipart form as CreatePost. Sending media files
+// replaces every attachment and removeMedia=true drops them.
+func (h *Handler) EditPost(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = r.ParseMultipartForm(10 << 20)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+	input := service.EditPostInput{
+		PostId:      int32(postId),
+		UserId:      int32(ctxUserId),
+		Body:        r.FormValue("body"),
+		RemoveMedia: r.FormValue("removeMedia") == "true",
+	}
+	attachments, closeFiles, err := attachmentsFromForm(r)
+	if err != nil {
+		log.Println("Error getting media file:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+	input.Attachments = attachments
+
+	post, err := h.postService.EditPost(r.Context(), input)
+	if err != nil {
+		writeEditError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	revisions, err := h.postService.GetPostRevisions(r.Context(), viewerIdFromContext(r), int32(postId))
+	if err != nil {
+		writeEditError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(revisions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeEditError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrNotPostAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrEditWindowClosed), errors.Is(err, service.ErrPostNotEditable):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
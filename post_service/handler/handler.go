@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,6 +12,11 @@ import (
 	"github.com/go-chi/jwtauth/v5"
 )
 
+const (
+	defaultPostPageSize = 20
+	maxPostPageSize     = 100
+)
+
 type Handler struct {
 	postService *service.PostService
 }
@@ -26,7 +32,7 @@ func (h *Handler) CheckHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.postService.GetAllPosts(r.Context())
+	posts, err := h.postService.GetAllPosts(r.Context(), viewerIdFromContext(r))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
@@ -37,6 +43,29 @@ func (h *Handler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *Handler) GetPost(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	post, err := h.postService.GetPost(r.Context(), viewerIdFromContext(r), int32(postId))
+	if errors.Is(err, service.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(post)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *Handler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	_, claims, _ := jwtauth.FromContext(r.Context())
 	ctxUserId := claims["user_id"].(float64)
@@ -60,13 +89,49 @@ func (h *Handler) GetPosts(w http.ResponseWriter, r *http.Request) {
 	pageSize := r.URL.Query().Get("pageSize")
 
 	userIdInt, _ := strconv.Atoi(userId)
+	if userIdInt <= 0 {
+		http.Error(w, "invalid pagination or user id", http.StatusBadRequest)
+		return
+	}
+	// pageNo and pageSize are the old offset pagination, kept until clients
+	// move to cursors
+	if pageNo != "" || pageSize != "" {
+		h.getPostsByPageNo(w, r, int32(userIdInt), pageNo, pageSize)
+		return
+	}
+	limit := defaultPostPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxPostPageSize {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	postPage, err := h.postService.GetUserPostsByCursor(r.Context(), viewerIdFromContext(r), int32(userIdInt), service.PostPageReq{
+		After:  r.URL.Query().Get("after"),
+		Before: r.URL.Query().Get("before"),
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(w).Encode(postPage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) getPostsByPageNo(w http.ResponseWriter, r *http.Request, userId int32, pageNo string, pageSize string) {
 	pageNoInt, _ := strconv.Atoi(pageNo)
 	pageSizeint, _ := strconv.Atoi(pageSize)
-	if pageNoInt == 0 || pageSizeint == 0 || userIdInt <= 0 {
+	if pageNoInt == 0 || pageSizeint == 0 {
 		http.Error(w, "invalid pagination or user id", http.StatusBadRequest)
 		return
 	}
-	postPage, err := h.postService.GetUserPostsPaginated(r.Context(), int32(userIdInt), service.PostPageReq{
+	postPage, err := h.postService.GetUserPostsPaginated(r.Context(), viewerIdFromContext(r), userId, service.PostPageReq{
 		PageNo:   int32(pageNoInt),
 		PageSize: int32(pageSizeint),
 	})
@@ -98,20 +163,21 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	var input service.CreatePostInput
 	input.UserId = int32(ctxUserId)
 	input.Body = r.FormValue("body")
+	input.Visibility = r.FormValue("visibility")
 
 	input.Username = ctxUsername
-	file, header, err := r.FormFile("media")
-	if err != nil && err != http.ErrMissingFile {
+	attachments, closeFiles, err := attachmentsFromForm(r)
+	if err != nil {
 		log.Println("Error getting media file:", err)
-		http.Error(w, "Unable to get file from request", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	if err == nil && file != nil {
-		defer file.Close()
-		input.Media = file
-		input.MediaType = header.Header.Get("Content-Type")
-		input.MediaSize = header.Size
+	defer closeFiles()
+	input.Attachments = attachments
+	input.Poll, err = pollFromForm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	err = service.Validate(input)
@@ -120,6 +186,10 @@ func (h *Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	err = h.postService.CreatePost(r.Context(), input)
+	if errors.Is(err, service.ErrInvalidVisibility) || errors.Is(err, service.ErrInvalidPoll) || errors.Is(err, service.ErrInvalidPollEndAt) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
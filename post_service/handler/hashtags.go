@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultHashtagPageSize = 20
+	maxHashtagPageSize     = 100
+	defaultTrendingLimit   = 10
+	maxTrendingLimit       = 50
+)
+
+func (h *Handler) GetHashtagPosts(w http.ResponseWriter, r *http.Request) {
+	limit := defaultHashtagPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxHashtagPageSize {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	page, err := h.postService.GetHashtagPosts(r.Context(), viewerIdFromContext(r), chi.URLParam(r, "tag"), service.HashtagPageReq{
+		After: r.URL.Query().Get("after"),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrendingLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxTrendingLimit {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	trending, err := h.postService.GetTrendingHashtags(r.Context(), int32(limit))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(trending)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// viewerIdFromContext returns the user id of a verified token when one was
+// sent, or 0 for anonymous requests on routes where auth is optional.
+func viewerIdFromContext(r *http.Request) int32 {
+	token, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil || token == nil {
+		return 0
+	}
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0
+	}
+	return int32(userId)
+}
+
+// attachmentsFromForm opens every "media" file of a parsed multipart form in
+// order. The i-th "altText" value describes the i-th file. The returned func
+// closes the opened files.
+func attachmentsFromForm(r *http.Request) ([]service.AttachmentUpload, func(), error) {
+	closeFiles := func() {}
+	if r.MultipartForm == nil {
+		return nil, closeFiles, nil
+	}
+	headers := r.MultipartForm.File["media"]
+	if len(headers) > service.MaxPostAttachments {
+		return nil, closeFiles, service.ErrTooManyAttachments
+	}
+	altTexts := r.MultipartForm.Value["altText"]
+	attachments := make([]service.AttachmentUpload, 0, len(headers))
+	closeFiles = func() {
+		for _, attachment := range attachments {
+			attachment.File.Close()
+		}
+	}
+	for i, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			closeFiles()
+			return nil, func() {}, errors.New("unable to get file from request")
+		}
+		attachment := service.AttachmentUpload{
+			File:        file,
+			ContentType: header.Header.Get("Content-Type"),
+			Size:        header.Size,
+		}
+		if i < len(altTexts) {
+			attachment.AltText = altTexts[i]
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, closeFiles, nil
+}
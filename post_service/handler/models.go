@@ -0,0 +1,25 @@
+package handler
+
+import "time"
+
+type CreateCommentRequest struct {
+	Body            string `json:"body" validate:"required,max=2000"`
+	ParentCommentId int32  `json:"parentCommentId" validate:"gte=0"`
+}
+
+type EditCommentRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type QuotePostRequest struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type ReschedulePostRequest struct {
+	PublishAt time.Time `json:"publishAt" validate:"required"`
+}
+
+type VotePollRequest struct {
+	// Options are the positions picked, one unless the poll is multiple choice
+	Options []int32 `json:"options" validate:"required,min=1,max=4"`
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// pollFromForm reads the optional poll of a post form: repeated pollOptions,
+// pollEndsAt as an RFC 3339 time and pollMultipleChoice. It returns nil when
+// the form has no poll options.
+func pollFromForm(r *http.Request) (*service.PollInput, error) {
+	options := r.MultipartForm.Value["pollOptions"]
+	if len(options) == 0 {
+		return nil, nil
+	}
+	endsAt, err := time.Parse(time.RFC3339, r.FormValue("pollEndsAt"))
+	if err != nil {
+		return nil, errors.New("pollEndsAt must be an RFC 3339 time")
+	}
+	return &service.PollInput{
+		Options:        options,
+		EndsAt:         endsAt,
+		MultipleChoice: strings.EqualFold(r.FormValue("pollMultipleChoice"), "true"),
+	}, nil
+}
+
+func (h *Handler) GetPoll(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	poll, err := h.postService.GetPoll(r.Context(), viewerIdFromContext(r), int32(postId))
+	if err != nil {
+		writePollError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(poll)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var voteReq VotePollRequest
+	err = json.NewDecoder(r.Body).Decode(&voteReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = service.Validate(voteReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	poll, err := h.postService.VotePoll(r.Context(), int32(ctxUserId), int32(postId), voteReq.Options)
+	if err != nil {
+		writePollError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(poll)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writePollError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound), errors.Is(err, service.ErrPollNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrPollClosed), errors.Is(err, service.ErrAlreadyVoted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidPollVote):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
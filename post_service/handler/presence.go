@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// TrackPresence reports the token user as active on every authenticated
+// request.
+func (h *Handler) TrackPresence(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		if userId, ok := claims["user_id"].(float64); ok {
+			err := h.postService.ReportActivity(int32(userId))
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+const (
+	defaultReactorPageSize = 50
+	maxReactorPageSize     = 100
+)
+
+func (h *Handler) React(w http.ResponseWriter, r *http.Request) {
+	h.handleReaction(w, r, h.postService.React)
+}
+
+func (h *Handler) Unreact(w http.ResponseWriter, r *http.Request) {
+	h.handleReaction(w, r, h.postService.Unreact)
+}
+
+// handleReaction applies action from the token user to the {postId} and
+// {reaction} in the url.
+func (h *Handler) handleReaction(w http.ResponseWriter, r *http.Request, action func(context.Context, int32, int32, string) error) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = action(r.Context(), int32(ctxUserId), int32(postId), chi.URLParam(r, "reaction"))
+	if errors.Is(err, service.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GetReactors(w http.ResponseWriter, r *http.Request) {
+	postId, err := strconv.Atoi(chi.URLParam(r, "postId"))
+	if err != nil || postId <= 0 {
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+	limit := defaultReactorPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxReactorPageSize {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+	reactors, err := h.postService.GetReactors(r.Context(), viewerIdFromContext(r), int32(postId), r.URL.Query().Get("reaction"), service.ReactorPageReq{
+		After: r.URL.Query().Get("after"),
+		Limit: int32(limit),
+	})
+	if errors.Is(err, service.ErrPostNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(w).Encode(reactors)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// SchedulePost takes the same multipart form as CreatePost plus publishAt as
+// an RFC 3339 time.
+func (h *Handler) SchedulePost(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+	ctxUsername := claims["username"].(string)
+
+	err := r.ParseMultipartForm(10 << 20)
+	if err != nil {
+		log.Println(err)
+		http.Error(w, "Unable to parse form", http.StatusBadRequest)
+		return
+	}
+	publishAt, err := time.Parse(time.RFC3339, r.FormValue("publishAt"))
+	if err != nil {
+		http.Error(w, "publishAt must be an RFC 3339 time", http.StatusBadRequest)
+		return
+	}
+	input := service.SchedulePostInput{
+		UserId:     int32(ctxUserId),
+		Username:   ctxUsername,
+		Body:       r.FormValue("body"),
+		Visibility: r.FormValue("visibility"),
+		PublishAt:  publishAt,
+	}
+	attachments, closeFiles, err := attachmentsFromForm(r)
+	if err != nil {
+		log.Println("Error getting media file:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeFiles()
+	input.Attachments = attachments
+
+	scheduled, err := h.postService.SchedulePost(r.Context(), input)
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(scheduled)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetScheduledPosts(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	scheduled, err := h.postService.GetScheduledPosts(r.Context(), int32(ctxUserId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(scheduled)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) ReschedulePost(w http.ResponseWriter, r *http.Request) {
+	scheduledPostId, err := strconv.Atoi(chi.URLParam(r, "scheduledPostId"))
+	if err != nil || scheduledPostId <= 0 {
+		http.Error(w, "invalid scheduled post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var rescheduleReq ReschedulePostRequest
+	err = json.NewDecoder(r.Body).Decode(&rescheduleReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = service.Validate(rescheduleReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scheduled, err := h.postService.ReschedulePost(r.Context(), int32(ctxUserId), int32(scheduledPostId), rescheduleReq.PublishAt)
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(scheduled)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) CancelScheduledPost(w http.ResponseWriter, r *http.Request) {
+	scheduledPostId, err := strconv.Atoi(chi.URLParam(r, "scheduledPostId"))
+	if err != nil || scheduledPostId <= 0 {
+		http.Error(w, "invalid scheduled post id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = h.postService.CancelScheduledPost(r.Context(), int32(ctxUserId), int32(scheduledPostId))
+	if err != nil {
+		writeScheduledError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeScheduledError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrScheduledPostNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrTooManyScheduledPosts):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -1,6 +1,8 @@
 package rabbitmq_consumer
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 
 	"github.com/BernardN38/socialstream-backend/post_service/service"
@@ -51,6 +53,42 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, postService *s
 	// if err != nil {
 	// 	return nil, err
 	// }
+	err = channel.QueueBind(queue.Name, "user.deactivated", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.reactivated", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.export.requested", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.verified", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.unverified", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.followed", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.unfollowed", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.blocked", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.deleted", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
 	return &RabbitMQConsumer{
 		conn:        conn,
 		channel:     channel,
@@ -75,7 +113,7 @@ func (c *RabbitMQConsumer) Close() error {
 }
 
 func (c *RabbitMQConsumer) Consume() error {
-	// ctx := context.Background()
+	ctx := context.Background()
 	msgs, err := c.channel.Consume(
 		c.queue,
 		"",
@@ -105,6 +143,115 @@ func (c *RabbitMQConsumer) Consume() error {
 			// 		log.Println(err)
 			// 	}
 			// 	log.Println("user created: ", createUserInput)
+			case "user.deactivated":
+				var userMsg UserStatusMsg
+				err := json.Unmarshal(msg.Body, &userMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.HideAuthorPosts(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.reactivated":
+				var userMsg UserStatusMsg
+				err := json.Unmarshal(msg.Body, &userMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.UnhideAuthorPosts(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.export.requested":
+				var exportMsg service.DataExportRequestedMsg
+				err := json.Unmarshal(msg.Body, &exportMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.ExportUserData(ctx, exportMsg.ExportId, exportMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.verified", "user.unverified":
+				var verifiedMsg UserVerifiedMsg
+				err := json.Unmarshal(msg.Body, &verifiedMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				// unverified messages carry an empty badge
+				err = c.postService.SetAuthorBadge(ctx, verifiedMsg.UserId, verifiedMsg.Badge)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.followed":
+				var followMsg UserFollowedMsg
+				err := json.Unmarshal(msg.Body, &followMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.FollowAuthor(ctx, followMsg.FollowerId, followMsg.FolloweeId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.unfollowed":
+				var followMsg UserFollowedMsg
+				err := json.Unmarshal(msg.Body, &followMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.UnfollowAuthor(ctx, followMsg.FollowerId, followMsg.FolloweeId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.blocked":
+				var blockMsg UserBlockedMsg
+				err := json.Unmarshal(msg.Body, &blockMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.RemoveFollowsBetween(ctx, blockMsg.BlockerId, blockMsg.BlockedId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.postService.RemoveMentionsBetween(ctx, blockMsg.BlockerId, blockMsg.BlockedId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.deleted":
+				var userMsg UserStatusMsg
+				err := json.Unmarshal(msg.Body, &userMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.postService.RemoveUserFollows(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.postService.RemoveUserMentions(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.postService.RemoveUserScheduledPosts(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.postService.RemoveUserDrafts(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.postService.RemoveUserPollVotes(ctx, userMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
 			default:
 				log.Println("did not recognize topic:", msg.RoutingKey)
 			}
@@ -118,3 +265,22 @@ type UserProfileImageUploadInput struct {
 	UserId  int32 `json:"userId"`
 	MediaId int32 `json:"mediaId"`
 }
+
+type UserStatusMsg struct {
+	UserId int32 `json:"userId"`
+}
+
+type UserVerifiedMsg struct {
+	UserId int32  `json:"userId"`
+	Badge  string `json:"badge"`
+}
+
+type UserFollowedMsg struct {
+	FollowerId int32 `json:"followerId"`
+	FolloweeId int32 `json:"followeeId"`
+}
+
+type UserBlockedMsg struct {
+	BlockerId int32 `json:"blockerId"`
+	BlockedId int32 `json:"blockedId"`
+}
@@ -1,5 +1,7 @@
 package rabbitmq_producer
 
+import "time"
+
 type UserDeletedMsg struct {
 	UserId int32 `json:"userId"`
 }
@@ -7,3 +9,57 @@ type UserDeletedMsg struct {
 type MediaDeletedMsg struct {
 	MediaId string `json:"mediaId"`
 }
+
+// PostCommentedMsg carries the authors a comment may notify. The parent
+// fields are 0 for top level comments.
+type PostCommentedMsg struct {
+	CommentId       int32  `json:"commentId"`
+	PostId          int32  `json:"postId"`
+	PostAuthorId    int32  `json:"postAuthorId"`
+	ParentCommentId int32  `json:"parentCommentId,omitempty"`
+	ParentAuthorId  int32  `json:"parentAuthorId,omitempty"`
+	UserId          int32  `json:"userId"`
+	Username        string `json:"username"`
+}
+
+// PostUpdatedMsg carries the new content of an edited post.
+type PostUpdatedMsg struct {
+	PostId   int32     `json:"postId"`
+	UserId   int32     `json:"userId"`
+	Body     string    `json:"body"`
+	MediaId  int32     `json:"mediaId,omitempty"`
+	MediaIds []int32   `json:"mediaIds"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+// PostMentionedMsg is published once for every user mentioned in a post.
+type PostMentionedMsg struct {
+	PostId          int32  `json:"postId"`
+	AuthorId        int32  `json:"authorId"`
+	AuthorUsername  string `json:"authorUsername"`
+	MentionedUserId int32  `json:"mentionedUserId"`
+}
+
+// PostSharedMsg is published for reposts, undone reposts and quotes.
+type PostSharedMsg struct {
+	PostId           int32  `json:"postId"`
+	OriginalPostId   int32  `json:"originalPostId"`
+	OriginalAuthorId int32  `json:"originalAuthorId"`
+	UserId           int32  `json:"userId"`
+	Username         string `json:"username,omitempty"`
+}
+
+// PollClosedMsg is published once when a poll ends, with its final counts.
+type PollClosedMsg struct {
+	PostId     int32              `json:"postId"`
+	AuthorId   int32              `json:"authorId"`
+	VoterCount int32              `json:"voterCount"`
+	Options    []PollClosedOption `json:"options"`
+	ClosedAt   time.Time          `json:"closedAt"`
+}
+
+type PollClosedOption struct {
+	Position  int32  `json:"position"`
+	Text      string `json:"text"`
+	VoteCount int32  `json:"voteCount"`
+}
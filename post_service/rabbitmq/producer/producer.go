@@ -5,7 +5,7 @@ import (
 )
 
 type RabbitMQProducerInterface interface {
-	Publish(string, []byte) error
+	Publish(string, string, []byte) error
 	Close() error
 }
 
@@ -1,6 +1,7 @@
 package rpc_client
 
 import (
+	"context"
 	"net/rpc"
 
 	"github.com/google/uuid"
@@ -8,6 +9,7 @@ import (
 
 type RpcClient struct {
 	mediaServiceRpcClient *rpc.Client
+	userServiceRpcClient  *rpc.Client
 }
 
 type RpcImageUpload struct {
@@ -15,14 +17,57 @@ type RpcImageUpload struct {
 	UserId      int32
 	ContentType string
 	Size        int64
+	// AwaitingClaim has the media service delete the upload unless a post,
+	// draft or scheduled post claims it
+	AwaitingClaim bool
 }
 
-func New(mediaServiceClient *rpc.Client) (*RpcClient, error) {
+type MentionsReq struct {
+	AuthorId  int32
+	Usernames []string
+}
+
+type MentionedUser struct {
+	UserId   int32
+	Username string
+}
+
+type FollowedAmongReq struct {
+	FollowerId  int32
+	FolloweeIds []int32
+}
+
+type FollowsPageReq struct {
+	AfterFollowerId int32
+	AfterFolloweeId int32
+	Limit           int32
+}
+
+type Follow struct {
+	FollowerId int32
+	FolloweeId int32
+}
+
+func New(mediaServiceClient *rpc.Client, userServiceClient *rpc.Client) (*RpcClient, error) {
 	return &RpcClient{
 		mediaServiceRpcClient: mediaServiceClient,
+		userServiceRpcClient:  userServiceClient,
 	}, nil
 }
 
+// call makes an rpc call that gives up when ctx is done. net/rpc has no
+// deadlines of its own, a call the server never answers would otherwise
+// block forever. The reply is left to the abandoned call.
+func call(ctx context.Context, client *rpc.Client, serviceMethod string, args any, reply any) error {
+	pending := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case done := <-pending.Done:
+		return done.Error
+	}
+}
+
 func (rc *RpcClient) UploadMedia(ImageUpload *RpcImageUpload) (int32, error) {
 	var mediaId int32
 	err := rc.mediaServiceRpcClient.Call("RpcServer.UploadImage", ImageUpload, &mediaId)
@@ -32,6 +77,20 @@ func (rc *RpcClient) UploadMedia(ImageUpload *RpcImageUpload) (int32, error) {
 	return mediaId, nil
 }
 
+// RestrictMedia has the media service check access to the media before
+// serving it.
+func (rc *RpcClient) RestrictMedia(ctx context.Context, mediaIds []int32) error {
+	var restricted bool
+	return call(ctx, rc.mediaServiceRpcClient, "RpcServer.RestrictMedia", mediaIds, &restricted)
+}
+
+// UnrestrictMedia lets the media service serve the media without an access
+// check again.
+func (rc *RpcClient) UnrestrictMedia(ctx context.Context, mediaIds []int32) error {
+	var unrestricted bool
+	return call(ctx, rc.mediaServiceRpcClient, "RpcServer.UnrestrictMedia", mediaIds, &unrestricted)
+}
+
 func (rc *RpcClient) DeleteMedia(mediaId uuid.UUID) error {
 	var replyErr error
 	err := rc.mediaServiceRpcClient.Call("RpcServer.DeleteImage", mediaId, &replyErr)
@@ -43,3 +102,46 @@ func (rc *RpcClient) DeleteMedia(mediaId uuid.UUID) error {
 	}
 	return nil
 }
+
+// ResolveMentions asks the user service which usernames the author can
+// mention.
+func (rc *RpcClient) ResolveMentions(ctx context.Context, authorId int32, usernames []string) ([]MentionedUser, error) {
+	var mentioned []MentionedUser
+	err := call(ctx, rc.userServiceRpcClient, "RpcServer.ResolveMentions", MentionsReq{
+		AuthorId:  authorId,
+		Usernames: usernames,
+	}, &mentioned)
+	if err != nil {
+		return nil, err
+	}
+	return mentioned, nil
+}
+
+// GetFollowedAmong asks the user service which of followeeIds followerId
+// follows.
+func (rc *RpcClient) GetFollowedAmong(ctx context.Context, followerId int32, followeeIds []int32) ([]int32, error) {
+	var followed []int32
+	err := call(ctx, rc.userServiceRpcClient, "RpcServer.GetFollowedAmong", FollowedAmongReq{
+		FollowerId:  followerId,
+		FolloweeIds: followeeIds,
+	}, &followed)
+	if err != nil {
+		return nil, err
+	}
+	return followed, nil
+}
+
+// GetFollowsPage reads up to limit follows after the given pair from the
+// user service, in follower then followee order.
+func (rc *RpcClient) GetFollowsPage(ctx context.Context, afterFollowerId int32, afterFolloweeId int32, limit int32) ([]Follow, error) {
+	var follows []Follow
+	err := call(ctx, rc.userServiceRpcClient, "RpcServer.GetFollowsPage", FollowsPageReq{
+		AfterFollowerId: afterFollowerId,
+		AfterFolloweeId: afterFolloweeId,
+		Limit:           limit,
+	}, &follows)
+	if err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
@@ -1,6 +1,8 @@
 package rpc_server
 
 import (
+	"context"
+	"log"
 	"net/rpc"
 
 	"github.com/BernardN38/socialstream-backend/post_service/service"
@@ -20,6 +22,11 @@ type ProfileImageUpdateReq struct {
 	UserId  int32
 	MediaId int32
 }
+type MediaAccessReq struct {
+	MediaId int32
+	// ViewerId is 0 when anonymous
+	ViewerId int32
+}
 
 // New returns the object for the RPC handler
 func NewRpcServer(postService *service.PostService) (*RpcServer, error) {
@@ -37,3 +44,27 @@ func (s *RpcServer) health(userId int32, reply *bool) error {
 	*reply = true
 	return nil
 }
+
+// CanViewMedia reports whether the viewer can read a post the media is
+// attached to, the media service asks before serving restricted media.
+func (s *RpcServer) CanViewMedia(req MediaAccessReq, reply *bool) error {
+	ok, err := s.postService.CanViewMedia(context.Background(), req.ViewerId, req.MediaId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = ok
+	return nil
+}
+
+// GetClaimedMedia returns the media ids a post, draft or scheduled post
+// holds on to, the media service deletes other uploads awaiting a claim.
+func (s *RpcServer) GetClaimedMedia(mediaIds []int32, reply *[]int32) error {
+	claimed, err := s.postService.GetClaimedMediaIds(context.Background(), mediaIds)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = claimed
+	return nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	rpc_client "github.com/BernardN38/socialstream-backend/post_service/rpc/client"
+)
+
+const (
+	MaxPostAttachments = 4
+	maxAltTextLength   = 1000
+)
+
+var ErrTooManyAttachments = fmt.Errorf("a post can have at most %d attachments", MaxPostAttachments)
+
+func validateAttachments(attachments []AttachmentUpload) error {
+	if len(attachments) > MaxPostAttachments {
+		return ErrTooManyAttachments
+	}
+	for _, attachment := range attachments {
+		if utf8.RuneCountInString(attachment.AltText) > maxAltTextLength {
+			return fmt.Errorf("alt text can be at most %d characters", maxAltTextLength)
+		}
+	}
+	return nil
+}
+
+// uploadAttachments uploads the attachments to the media service in
+// parallel and returns their media ids in order. When any upload fails the
+// ones that went through are deleted so no media is left without a post.
+// Uploads are marked as awaiting a claim, so media whose reply was lost or
+// whose post never committed is swept by the media service when
+// GetClaimedMediaIds does not return it.
+func (p *PostService) uploadAttachments(userId int32, attachments []AttachmentUpload) ([]int32, error) {
+	mediaIds := make([]int32, len(attachments))
+	errs := make([]error, len(attachments))
+	var wg sync.WaitGroup
+	for i, attachment := range attachments {
+		wg.Add(1)
+		go func(i int, attachment AttachmentUpload) {
+			defer wg.Done()
+			mediaBytes, err := io.ReadAll(attachment.File)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			mediaIds[i], errs[i] = p.rpcClient.UploadMedia(&rpc_client.RpcImageUpload{
+				ImageData:   mediaBytes,
+				UserId:      userId,
+				ContentType: attachment.ContentType,
+				Size:        attachment.Size,
+				// no post holds on to it yet
+				AwaitingClaim: true,
+			})
+		}(i, attachment)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			p.deleteMedia(mediaIds)
+			return nil, err
+		}
+	}
+	return mediaIds, nil
+}
+
+// GetClaimedMediaIds returns the media ids among mediaIds that a post, an
+// earlier revision, a draft or a scheduled post holds on to. The media
+// service deletes uploads awaiting a claim that are not among them.
+func (p *PostService) GetClaimedMediaIds(ctx context.Context, mediaIds []int32) ([]int32, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	claimed, err := p.postQuries.GetClaimedMediaIds(timeoutCtx, mediaIds)
+	if err != nil {
+		return nil, err
+	}
+	if claimed == nil {
+		claimed = []int32{}
+	}
+	return claimed, nil
+}
+
+// deleteMedia asks the media service to delete each media id, ids of 0 are
+// skipped. Failures are only logged, it runs on paths that are already
+// failing or cleaning up.
+func (p *PostService) deleteMedia(mediaIds []int32) {
+	for _, mediaId := range mediaIds {
+		if mediaId <= 0 {
+			continue
+		}
+		err := p.publishMediaDeleted(mediaId)
+		if err != nil {
+			log.Println("delete media:", err)
+		}
+	}
+}
+
+func (p *PostService) publishMediaDeleted(mediaId int32) error {
+	msg, err := json.Marshal(MediaDeletedMsg{
+		MediaId: mediaId,
+	})
+	if err != nil {
+		return err
+	}
+	return p.rabbitmProducer.Publish("media_events", "media.deleted", msg)
+}
+
+func attachmentAltTexts(attachments []AttachmentUpload) []string {
+	altTexts := make([]string, len(attachments))
+	for i, attachment := range attachments {
+		altTexts[i] = attachment.AltText
+	}
+	return altTexts
+}
+
+// firstMediaId is what posts.media_id holds for readers that only know
+// about a single attachment.
+func firstMediaId(mediaIds []int32) sql.NullInt32 {
+	if len(mediaIds) == 0 {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: mediaIds[0], Valid: true}
+}
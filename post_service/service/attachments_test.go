@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+// testFile is an in-memory multipart.File.
+type testFile struct {
+	*bytes.Reader
+}
+
+func (testFile) Close() error {
+	return nil
+}
+
+// testAttachment is an upload the fake media service stores as mediaId.
+func testAttachment(mediaId int32, contentType string, altText string) AttachmentUpload {
+	data := []byte(strconv.Itoa(int(mediaId)))
+	return AttachmentUpload{
+		File:        testFile{bytes.NewReader(data)},
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		AltText:     altText,
+	}
+}
+
+// deletedMediaIds returns the media ids published as deleted so far.
+func deletedMediaIds(t *testing.T, producer *MockRabbitmqProducer) []int32 {
+	t.Helper()
+	ids := []int32{}
+	for _, message := range producer.Messages("media.deleted") {
+		var msg MediaDeletedMsg
+		if err := json.Unmarshal(message, &msg); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, msg.MediaId)
+	}
+	return ids
+}
+
+func TestPostAttachments(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	SetupRpcServer(t, postService)
+
+	err := postService.CreatePost(ctx, CreatePostInput{
+		UserId:   1,
+		Username: "user1",
+		Body:     "five pictures",
+		Attachments: []AttachmentUpload{
+			testAttachment(11, "image/png", ""),
+			testAttachment(12, "image/png", ""),
+			testAttachment(13, "image/png", ""),
+			testAttachment(14, "image/png", ""),
+			testAttachment(15, "image/png", ""),
+		},
+	})
+	if !errors.Is(err, ErrTooManyAttachments) {
+		t.Errorf("five attachments = %v, want %v", err, ErrTooManyAttachments)
+	}
+	err = postService.CreatePost(ctx, CreatePostInput{
+		UserId:   1,
+		Username: "user1",
+		Body:     "one upload fails",
+		Attachments: []AttachmentUpload{
+			testAttachment(1, "image/png", ""),
+			testAttachment(0, "fail", ""),
+		},
+	})
+	if err == nil {
+		t.Error("expected a failed upload to fail the post")
+	}
+	// the upload that went through is not left without a post
+	if got := deletedMediaIds(t, producer); !reflect.DeepEqual(got, []int32{1}) {
+		t.Errorf("deleted media %v, want [1]", got)
+	}
+
+	err = postService.CreatePost(ctx, CreatePostInput{
+		UserId:   1,
+		Username: "user1",
+		Body:     "two pictures",
+		Attachments: []AttachmentUpload{
+			testAttachment(2, "image/png", "a cat"),
+			testAttachment(3, "image/jpeg", "a dog"),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := postService.GetUserPostsByCursor(ctx, 0, 1, PostPageReq{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 {
+		t.Fatalf("got posts %+v, want only the post with attachments", page.Posts)
+	}
+	post := page.Posts[0]
+	want := []posts.PostMedium{
+		{PostID: post.ID, Position: 0, MediaID: 2, AltText: "a cat"},
+		{PostID: post.ID, Position: 1, MediaID: 3, AltText: "a dog"},
+	}
+	if !reflect.DeepEqual(post.Attachments, want) {
+		t.Errorf("attachments = %+v, want %+v", post.Attachments, want)
+	}
+	if post.MediaID.Int32 != 2 {
+		t.Errorf("media id = %d, want the first attachment", post.MediaID.Int32)
+	}
+
+	// uploads for an edit that is refused are deleted again
+	_, err = postService.EditPost(ctx, EditPostInput{
+		PostId:      post.ID,
+		UserId:      2,
+		Body:        "not mine",
+		Attachments: []AttachmentUpload{testAttachment(4, "image/png", "")},
+	})
+	if !errors.Is(err, ErrNotPostAuthor) {
+		t.Errorf("editing someone else's post = %v, want %v", err, ErrNotPostAuthor)
+	}
+	if got := deletedMediaIds(t, producer); !reflect.DeepEqual(got, []int32{1, 4}) {
+		t.Errorf("deleted media %v, want [1 4]", got)
+	}
+	// removed attachments stay with the revision that had them
+	view, err := postService.EditPost(ctx, EditPostInput{PostId: post.ID, UserId: 1, Body: "two pictures", RemoveMedia: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Attachments) != 0 || view.MediaID.Valid {
+		t.Errorf("attachments after removal = %+v, media id %v", view.Attachments, view.MediaID)
+	}
+	revisions, err := postService.GetPostRevisions(ctx, 1, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || !reflect.DeepEqual(revisions[0].MediaIds, []int32{2, 3}) {
+		t.Errorf("revisions = %+v, want one with media [2 3]", revisions)
+	}
+	if got := deletedMediaIds(t, producer); !reflect.DeepEqual(got, []int32{1, 4}) {
+		t.Errorf("deleted media after edit %v, want [1 4]", got)
+	}
+	// the media service sweeps the uploads nothing holds on to
+	claimed, err := postService.GetClaimedMediaIds(ctx, []int32{1, 2, 3, 4, 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(claimed, []int32{2, 3}) {
+		t.Errorf("claimed media %v, want [2 3]", claimed)
+	}
+}
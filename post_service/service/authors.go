@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+// HideAuthorPosts filters every post by the user out of reads while their
+// account is deactivated. The posts themselves are kept so a reactivated
+// account gets them back.
+func (p *PostService) HideAuthorPosts(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	err := p.postQuries.CreateDeactivatedAuthor(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	return p.adjustAuthorTrending(timeoutCtx, userId, -1)
+}
+
+// UnhideAuthorPosts makes the user's posts visible again after reactivation.
+func (p *PostService) UnhideAuthorPosts(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	err := p.postQuries.DeleteDeactivatedAuthor(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	return p.adjustAuthorTrending(timeoutCtx, userId, 1)
+}
+
+// SetAuthorBadge stores the author's verification badge and stamps it on
+// their existing posts and comments. New ones pick it up when created.
+func (p *PostService) SetAuthorBadge(ctx context.Context, userId int32, badge string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	if badge == "" {
+		err = txQuries.DeleteAuthorBadge(timeoutCtx, userId)
+	} else {
+		err = txQuries.UpsertAuthorBadge(timeoutCtx, posts.UpsertAuthorBadgeParams{
+			UserID: userId,
+			Badge:  badge,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	err = txQuries.UpdatePostsAuthorBadge(timeoutCtx, posts.UpdatePostsAuthorBadgeParams{
+		UserID:      userId,
+		AuthorBadge: badge,
+	})
+	if err != nil {
+		return err
+	}
+	err = txQuries.UpdateCommentsAuthorBadge(timeoutCtx, posts.UpdateCommentsAuthorBadgeParams{
+		UserID:      userId,
+		AuthorBadge: badge,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+const maxCommentLength = 2000
+
+var (
+	ErrPostNotFound    = errors.New("post not found")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrInvalidComment  = errors.New("comment must be 1 to 2000 characters")
+	ErrNotCommentOwner = errors.New("only the comment author can change it, or the post author delete it")
+)
+
+// CreateComment adds a comment to a post, or a reply when ParentCommentId is
+// set, and publishes post.commented so the post and parent authors can be
+// notified.
+func (p *PostService) CreateComment(ctx context.Context, input CreateCommentInput) (posts.Comment, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" || len([]rune(body)) > maxCommentLength {
+		return posts.Comment{}, ErrInvalidComment
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	// readability needs the follow graph over rpc, so it is checked before
+	// the post row is locked
+	readable, err := p.getReadablePost(timeoutCtx, input.UserId, input.PostId)
+	if err != nil {
+		return posts.Comment{}, err
+	}
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	// the post is locked before any comment so deletes and creates agree on order
+	post, err := txQuries.GetPostForUpdate(timeoutCtx, input.PostId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return posts.Comment{}, ErrPostNotFound
+	}
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	if post.Visibility != readable.Visibility {
+		return posts.Comment{}, ErrPostNotFound
+	}
+	var parent posts.Comment
+	if input.ParentCommentId > 0 {
+		parent, err = txQuries.GetCommentForUpdate(timeoutCtx, input.ParentCommentId)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && parent.PostID != post.ID) {
+			return posts.Comment{}, ErrCommentNotFound
+		}
+		if err != nil {
+			return posts.Comment{}, err
+		}
+		if parent.DeletedAt.Valid {
+			return posts.Comment{}, errors.New("cannot reply to a deleted comment")
+		}
+	}
+	comment, err := txQuries.CreateComment(timeoutCtx, posts.CreateCommentParams{
+		PostID: post.ID,
+		ParentCommentID: sql.NullInt32{
+			Int32: input.ParentCommentId,
+			Valid: input.ParentCommentId > 0,
+		},
+		UserID:   input.UserId,
+		Username: input.Username,
+		Body:     body,
+	})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	err = txQuries.UpdatePostCommentCount(timeoutCtx, posts.UpdatePostCommentCountParams{
+		ID:    post.ID,
+		Delta: 1,
+	})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	if input.ParentCommentId > 0 {
+		err = txQuries.UpdateCommentReplyCount(timeoutCtx, posts.UpdateCommentReplyCountParams{
+			ID:    parent.ID,
+			Delta: 1,
+		})
+		if err != nil {
+			return posts.Comment{}, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	msg, err := json.Marshal(rabbitmq_producer.PostCommentedMsg{
+		CommentId:       comment.ID,
+		PostId:          post.ID,
+		PostAuthorId:    post.UserID,
+		ParentCommentId: parent.ID,
+		ParentAuthorId:  parent.UserID,
+		UserId:          comment.UserID,
+		Username:        comment.Username,
+	})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	err = p.rabbitmProducer.Publish("post_events", "post.commented", msg)
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	return comment, nil
+}
+
+// EditComment replaces the body of a comment. Only its author can edit it.
+func (p *PostService) EditComment(ctx context.Context, userId int32, postId int32, commentId int32, body string) (posts.Comment, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > maxCommentLength {
+		return posts.Comment{}, ErrInvalidComment
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	comment, err := txQuries.GetCommentForUpdate(timeoutCtx, commentId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (comment.PostID != postId || comment.DeletedAt.Valid)) {
+		return posts.Comment{}, ErrCommentNotFound
+	}
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	if comment.UserID != userId {
+		return posts.Comment{}, ErrNotCommentOwner
+	}
+	comment, err = txQuries.UpdateCommentBody(timeoutCtx, posts.UpdateCommentBodyParams{
+		ID:   commentId,
+		Body: body,
+	})
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return posts.Comment{}, err
+	}
+	return comment, nil
+}
+
+// DeleteComment can be called by the comment author or the post author.
+// Comments with replies are blanked out instead of removed so the thread
+// below them stays intact.
+func (p *PostService) DeleteComment(ctx context.Context, userId int32, postId int32, commentId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	post, err := txQuries.GetPostForUpdate(timeoutCtx, postId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	comment, err := txQuries.GetCommentForUpdate(timeoutCtx, commentId)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (comment.PostID != postId || comment.DeletedAt.Valid)) {
+		return ErrCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userId && post.UserID != userId {
+		return ErrNotCommentOwner
+	}
+	if comment.ReplyCount > 0 {
+		err = txQuries.SoftDeleteComment(timeoutCtx, commentId)
+	} else {
+		err = txQuries.DeleteComment(timeoutCtx, commentId)
+		if err == nil {
+			err = removeEmptyAncestors(timeoutCtx, txQuries, comment.ParentCommentID)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	err = txQuries.UpdatePostCommentCount(timeoutCtx, posts.UpdatePostCommentCountParams{
+		ID:    postId,
+		Delta: -1,
+	})
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// removeEmptyAncestors takes a removed reply off its parent's reply count.
+// A blanked out parent was only kept for its replies, so it is removed too
+// once it has none left, and so on up the thread.
+func removeEmptyAncestors(ctx context.Context, queries *posts.Queries, parentId sql.NullInt32) error {
+	for parentId.Valid {
+		parent, err := queries.DecrementCommentReplyCount(ctx, parentId.Int32)
+		if err != nil {
+			return err
+		}
+		if !parent.DeletedAt.Valid || parent.ReplyCount > 0 {
+			return nil
+		}
+		err = queries.DeleteComment(ctx, parent.ID)
+		if err != nil {
+			return err
+		}
+		parentId = parent.ParentCommentID
+	}
+	return nil
+}
+
+// GetComments returns a page of top level comments on the post, or of the
+// direct replies to parentCommentId when it is set, oldest first.
+func (p *PostService) GetComments(ctx context.Context, viewerId int32, postId int32, parentCommentId int32, page CommentPageReq) (*CommentPageResp, error) {
+	afterId, err := decodeCommentCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	_, err = p.getReadablePost(timeoutCtx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch one extra row to know if there is a next page
+	limit := page.Limit + 1
+	var comments []posts.Comment
+	if parentCommentId > 0 {
+		comments, err = p.postQuries.GetReplyPage(timeoutCtx, posts.GetReplyPageParams{
+			PostID:          postId,
+			ParentCommentID: sql.NullInt32{Int32: parentCommentId, Valid: true},
+			Limit:           limit,
+			AfterID:         afterId,
+		})
+	} else {
+		comments, err = p.postQuries.GetCommentPage(timeoutCtx, posts.GetCommentPageParams{
+			PostID:  postId,
+			Limit:   limit,
+			AfterID: afterId,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &CommentPageResp{
+		Comments: comments,
+	}
+	if len(comments) > int(page.Limit) {
+		resp.Comments = comments[:page.Limit]
+		resp.NextCursor = encodeCommentCursor(resp.Comments[len(resp.Comments)-1].ID)
+	}
+	if resp.Comments == nil {
+		resp.Comments = []posts.Comment{}
+	}
+	return resp, nil
+}
@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+const (
+	maxDraftsPerUser      = 50
+	draftCleanupBatchSize = 500
+)
+
+var (
+	ErrDraftNotFound = errors.New("draft not found")
+	ErrDraftConflict = errors.New("draft was saved elsewhere since it was loaded")
+	ErrTooManyDrafts = fmt.Errorf("at most %d drafts can be saved", maxDraftsPerUser)
+)
+
+// CreateDraft saves a new draft, uploading its attachments for the author's
+// eyes only. The body may still be empty.
+func (p *PostService) CreateDraft(ctx context.Context, input SaveDraftInput) (*posts.Draft, error) {
+	visibility, err := normalizeVisibility(input.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAttachments(input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	countCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	count, err := p.postQuries.CountDraftsByUser(countCtx, input.UserId)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxDraftsPerUser {
+		return nil, ErrTooManyDrafts
+	}
+	mediaIds, err := p.uploadAttachments(input.UserId, input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	err = p.hideMedia(ctx, mediaIds)
+	if err != nil {
+		p.deleteMedia(mediaIds)
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	draft, err := p.postQuries.CreateDraft(timeoutCtx, posts.CreateDraftParams{
+		UserID:     input.UserId,
+		Body:       input.Body,
+		Visibility: visibility,
+		MediaIds:   mediaIds,
+		AltTexts:   attachmentAltTexts(input.Attachments),
+	})
+	if err != nil {
+		p.deleteMedia(mediaIds)
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// UpdateDraft saves the draft again. New uploads replace every attachment and
+// RemoveMedia drops them, the replaced media is deleted since nothing
+// published uses it. An empty Visibility keeps the stored one. A Version
+// other than the stored one is refused with ErrDraftConflict.
+func (p *PostService) UpdateDraft(ctx context.Context, input SaveDraftInput) (*posts.Draft, error) {
+	visibility := input.Visibility
+	if visibility != "" {
+		var err error
+		visibility, err = normalizeVisibility(visibility)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := validateAttachments(input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	uploadedIds, err := p.uploadAttachments(input.UserId, input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			p.deleteMedia(uploadedIds)
+		}
+	}()
+	err = p.hideMedia(ctx, uploadedIds)
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	draft, err := txQuries.GetDraftForUpdate(timeoutCtx, posts.GetDraftForUpdateParams{
+		ID:     input.DraftId,
+		UserID: input.UserId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if input.Version > 0 && input.Version != draft.Version {
+		return nil, ErrDraftConflict
+	}
+	if visibility == "" {
+		visibility = draft.Visibility
+	}
+	mediaIds, altTexts := draft.MediaIds, draft.AltTexts
+	replaceMedia := len(uploadedIds) > 0 || input.RemoveMedia
+	if replaceMedia {
+		mediaIds, altTexts = uploadedIds, attachmentAltTexts(input.Attachments)
+	}
+	updated, err := txQuries.UpdateDraft(timeoutCtx, posts.UpdateDraftParams{
+		ID:         draft.ID,
+		Body:       input.Body,
+		Visibility: visibility,
+		MediaIds:   mediaIds,
+		AltTexts:   altTexts,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	committed = true
+	if replaceMedia {
+		p.deleteMedia(draft.MediaIds)
+	}
+	return &updated, nil
+}
+
+// GetDrafts lists the user's drafts, the most recently saved first.
+func (p *PostService) GetDrafts(ctx context.Context, userId int32) ([]posts.Draft, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	drafts, err := p.postQuries.GetDraftsByUser(timeoutCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if drafts == nil {
+		drafts = []posts.Draft{}
+	}
+	return drafts, nil
+}
+
+func (p *PostService) GetDraft(ctx context.Context, userId int32, draftId int32) (*posts.Draft, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	draft, err := p.postQuries.GetDraft(timeoutCtx, posts.GetDraftParams{
+		ID:     draftId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// DeleteDraft drops the draft and its attachments.
+func (p *PostService) DeleteDraft(ctx context.Context, userId int32, draftId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	mediaIds, err := p.postQuries.DeleteDraft(timeoutCtx, posts.DeleteDraftParams{
+		ID:     draftId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrDraftNotFound
+	}
+	if err != nil {
+		return err
+	}
+	p.deleteMedia(mediaIds)
+	return nil
+}
+
+// PublishDraft turns the draft into a post and deletes it in one
+// transaction, so publishing twice cannot make two posts. Its attachments
+// move to the post. Mentions are resolved with the user service before the
+// draft is locked, a save in between is refused with ErrDraftConflict.
+func (p *PostService) PublishDraft(ctx context.Context, userId int32, username string, draftId int32) (*PostView, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	loaded, err := p.postQuries.GetDraft(timeoutCtx, posts.GetDraftParams{
+		ID:     draftId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	body := strings.TrimSpace(loaded.Body)
+	if body == "" {
+		return nil, errors.New("post body is required")
+	}
+	mentions := p.resolveMentions(timeoutCtx, userId, body)
+	// the visibility can only change with a save, which the version check
+	// below refuses. Drafts saved before their media was hidden up front may
+	// still have it unrestricted.
+	err = p.restrictMedia(timeoutCtx, loaded.Visibility, loaded.MediaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	draft, err := txQuries.GetDraftForUpdate(timeoutCtx, posts.GetDraftForUpdateParams{
+		ID:     draftId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if draft.Version != loaded.Version {
+		return nil, ErrDraftConflict
+	}
+	post := newPost{
+		UserId:     userId,
+		Username:   username,
+		Body:       body,
+		Visibility: draft.Visibility,
+		MediaIds:   draft.MediaIds,
+		AltTexts:   draft.AltTexts,
+		Mentions:   mentions,
+	}
+	postId, tags, err := insertPost(timeoutCtx, txQuries, post)
+	if err != nil {
+		return nil, err
+	}
+	_, err = txQuries.DeleteDraft(timeoutCtx, posts.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	p.revealMedia(ctx, post.Visibility, post.MediaIds)
+	p.announcePost(timeoutCtx, postId, post, tags)
+	return p.GetPost(ctx, userId, postId)
+}
+
+// RemoveUserDrafts drops a deleted user's drafts and their attachments.
+func (p *PostService) RemoveUserDrafts(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	mediaIds, err := p.postQuries.DeleteDraftsByUser(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	for _, ids := range mediaIds {
+		p.deleteMedia(ids)
+	}
+	return nil
+}
+
+// RunDraftCleanupJob deletes drafts not saved for the configured retention
+// every interval, publishing media.deleted for their attachments.
+func (p *PostService) RunDraftCleanupJob(ctx context.Context, interval time.Duration) {
+	if p.config.DraftRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := p.deleteStaleDrafts(ctx)
+			if err != nil {
+				log.Println("delete stale drafts:", err)
+			}
+		}
+	}
+}
+
+func (p *PostService) deleteStaleDrafts(ctx context.Context) error {
+	updatedBefore := time.Now().Add(-p.config.DraftRetention).UTC()
+	for {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		mediaIds, err := p.postQuries.DeleteStaleDrafts(timeoutCtx, posts.DeleteStaleDraftsParams{
+			UpdatedBefore: updatedBefore,
+			BatchSize:     draftCleanupBatchSize,
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+		for _, ids := range mediaIds {
+			p.deleteMedia(ids)
+		}
+		if len(mediaIds) < draftCleanupBatchSize {
+			return nil
+		}
+	}
+}
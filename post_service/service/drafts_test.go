@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestDrafts(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	SetupRpcServer(t, postService)
+
+	draft, err := postService.CreateDraft(ctx, SaveDraftInput{
+		UserId:      1,
+		Attachments: []AttachmentUpload{testAttachment(1, "image/png", "")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = postService.PublishDraft(ctx, 1, "user1", draft.ID)
+	if err == nil {
+		t.Error("expected publishing an empty draft to fail")
+	}
+	saved, err := postService.UpdateDraft(ctx, SaveDraftInput{
+		DraftId:     draft.ID,
+		UserId:      1,
+		Body:        "almost done",
+		Version:     draft.Version,
+		Attachments: []AttachmentUpload{testAttachment(2, "image/png", "")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a save from a tab that loaded the first version is refused
+	_, err = postService.UpdateDraft(ctx, SaveDraftInput{DraftId: draft.ID, UserId: 1, Body: "stale", Version: draft.Version})
+	if !errors.Is(err, ErrDraftConflict) {
+		t.Errorf("saving an old version = %v, want %v", err, ErrDraftConflict)
+	}
+	_, err = postService.UpdateDraft(ctx, SaveDraftInput{DraftId: draft.ID, UserId: 2, Body: "not mine"})
+	if !errors.Is(err, ErrDraftNotFound) {
+		t.Errorf("saving someone else's draft = %v, want %v", err, ErrDraftNotFound)
+	}
+	// the replaced attachment is deleted
+	if got := deletedMediaIds(t, producer); !reflect.DeepEqual(got, []int32{1}) {
+		t.Errorf("deleted media %v, want [1]", got)
+	}
+	claimed, err := postService.GetClaimedMediaIds(ctx, []int32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(claimed, []int32{2}) {
+		t.Errorf("claimed media %v, want the draft's [2]", claimed)
+	}
+
+	// publishing from several tabs at once makes a single post
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	published, notFound := 0, 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := postService.PublishDraft(ctx, 1, "user1", saved.ID)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				published++
+			case errors.Is(err, ErrDraftNotFound):
+				notFound++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if published != 1 || notFound != 3 {
+		t.Errorf("published %d times and %d not found, want 1 and 3", published, notFound)
+	}
+	page, err := postService.GetUserPostsByCursor(ctx, 1, 1, PostPageReq{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].Body != "almost done" || page.Posts[0].MediaID.Int32 != 2 {
+		t.Errorf("posts after publishing = %+v, want the draft with its attachment", page.Posts)
+	}
+	drafts, err := postService.GetDrafts(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drafts) != 0 {
+		t.Errorf("drafts after publishing = %+v, want none", drafts)
+	}
+}
+
+func TestDraftVisibilityAndMedia(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	fake := SetupRpcServer(t, postService)
+
+	draft, err := postService.CreateDraft(ctx, SaveDraftInput{
+		UserId:      1,
+		Visibility:  VisibilityFollowers,
+		Attachments: []AttachmentUpload{testAttachment(5, "image/png", "")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a save that leaves out the visibility keeps it
+	saved, err := postService.UpdateDraft(ctx, SaveDraftInput{DraftId: draft.ID, UserId: 1, Body: "for friends"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Visibility != VisibilityFollowers {
+		t.Errorf("visibility after a save without one = %q, want %q", saved.Visibility, VisibilityFollowers)
+	}
+	saved, err = postService.UpdateDraft(ctx, SaveDraftInput{
+		DraftId:     draft.ID,
+		UserId:      1,
+		Body:        "for everyone",
+		Visibility:  VisibilityPublic,
+		Attachments: []AttachmentUpload{testAttachment(6, "image/png", "")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// draft uploads are only for the author whatever the visibility
+	if got := fake.Restricted(); !reflect.DeepEqual(got, []int32{5, 6}) {
+		t.Errorf("restricted media %v, want [5 6]", got)
+	}
+	_, err = postService.PublishDraft(ctx, 1, "user1", saved.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.Unrestricted(); !reflect.DeepEqual(got, []int32{6}) {
+		t.Errorf("unrestricted media %v, want the published post's [6]", got)
+	}
+}
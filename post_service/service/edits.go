@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+var (
+	ErrNotPostAuthor    = errors.New("only the author can edit a post")
+	ErrPostNotEditable  = errors.New("reposts cannot be edited")
+	ErrEditWindowClosed = errors.New("post can no longer be edited")
+)
+
+// EditPost replaces the body and attachments of the author's post, keeping
+// the previous version as a revision. New uploads replace every attachment,
+// the old media is kept for the revision history.
+func (p *PostService) EditPost(ctx context.Context, input EditPostInput) (*PostView, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, errors.New("post body is required")
+	}
+	err := validateAttachments(input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	uploadedIds, err := p.uploadAttachments(input.UserId, input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			p.deleteMedia(uploadedIds)
+		}
+	}()
+	mentions := p.resolveMentions(ctx, input.UserId, body)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	if len(uploadedIds) > 0 {
+		// visibility is fixed when the post is created, so the new media
+		// can be restricted before the post is locked
+		current, err := p.postQuries.GetVisiblePost(timeoutCtx, input.PostId)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPostNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = p.restrictMedia(timeoutCtx, current.Visibility, uploadedIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	post, err := txQuries.GetPostForUpdate(timeoutCtx, input.PostId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if post.UserID != input.UserId {
+		return nil, ErrNotPostAuthor
+	}
+	if post.Kind == PostKindRepost {
+		return nil, ErrPostNotEditable
+	}
+	if p.config.EditWindow > 0 && time.Since(post.CreatedAt) > p.config.EditWindow {
+		return nil, ErrEditWindowClosed
+	}
+
+	oldMediaIds, err := txQuries.GetPostMediaIds(timeoutCtx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	replaceMedia := len(uploadedIds) > 0 || (input.RemoveMedia && len(oldMediaIds) > 0)
+	if body == post.Body && !replaceMedia {
+		views, err := p.toPostViews(timeoutCtx, input.UserId, []posts.Post{post})
+		if err != nil {
+			return nil, err
+		}
+		return &views[0], nil
+	}
+
+	// the replaced version was current from its own edit, or from creation
+	versionCreatedAt := post.CreatedAt
+	if post.EditedAt.Valid {
+		versionCreatedAt = post.EditedAt.Time
+	}
+	err = txQuries.CreatePostRevision(timeoutCtx, posts.CreatePostRevisionParams{
+		PostID:    post.ID,
+		Body:      post.Body,
+		MediaID:   post.MediaID,
+		MediaIds:  oldMediaIds,
+		CreatedAt: versionCreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	oldTags, err := txQuries.GetPostHashtags(timeoutCtx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	tags := extractHashtags(body)
+	err = txQuries.DeletePostHashtags(timeoutCtx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = setPostHashtags(timeoutCtx, txQuries, post.ID, tags)
+	if err != nil {
+		return nil, err
+	}
+	// users mentioned before the edit were already notified
+	oldMentioned, err := txQuries.GetPostMentionedUserIds(timeoutCtx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = txQuries.DeletePostMentions(timeoutCtx, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = setPostMentions(timeoutCtx, txQuries, post.ID, mentions)
+	if err != nil {
+		return nil, err
+	}
+	mediaIds := oldMediaIds
+	if replaceMedia {
+		mediaIds = uploadedIds
+		err = txQuries.DeletePostMedia(timeoutCtx, post.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(uploadedIds) > 0 {
+			err = txQuries.CreatePostMedia(timeoutCtx, posts.CreatePostMediaParams{
+				PostID:   post.ID,
+				MediaIds: uploadedIds,
+				AltTexts: attachmentAltTexts(input.Attachments),
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	updated, err := txQuries.UpdatePostContent(timeoutCtx, posts.UpdatePostContentParams{
+		ID:      post.ID,
+		Body:    body,
+		MediaID: firstMediaId(mediaIds),
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	committed = true
+	// trending counts uses when the post was made, an edit only moves the
+	// tags it added or removed
+	if post.Visibility == VisibilityPublic {
+		p.recordTrendingTags(timeoutCtx, tagsDifference(tags, oldTags), post.CreatedAt, 1)
+		p.recordTrendingTags(timeoutCtx, tagsDifference(oldTags, tags), post.CreatedAt, -1)
+	}
+	if post.Visibility != VisibilityPrivate {
+		p.publishMentions(post.ID, post.UserID, post.Username, mentions, oldMentioned)
+	}
+	msg, err := json.Marshal(rabbitmq_producer.PostUpdatedMsg{
+		PostId:   updated.ID,
+		UserId:   updated.UserID,
+		Body:     updated.Body,
+		MediaId:  updated.MediaID.Int32,
+		MediaIds: mediaIds,
+		EditedAt: updated.EditedAt.Time,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = p.rabbitmProducer.Publish("post_events", "post.updated", msg)
+	if err != nil {
+		return nil, err
+	}
+	views, err := p.toPostViews(timeoutCtx, input.UserId, []posts.Post{updated})
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// GetPostRevisions returns the earlier versions of a post, newest first.
+func (p *PostService) GetPostRevisions(ctx context.Context, viewerId int32, postId int32) ([]posts.PostRevision, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_, err := p.getReadablePost(timeoutCtx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := p.postQuries.GetPostRevisions(timeoutCtx, postId)
+	if err != nil {
+		return nil, err
+	}
+	if revisions == nil {
+		revisions = []posts.PostRevision{}
+	}
+	return revisions, nil
+}
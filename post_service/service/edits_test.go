@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEditPost(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	postId := createTestPost(t, postService, 1, "first draft")
+
+	for _, body := range []string{"second draft", " second draft ", "final"} {
+		view, err := postService.EditPost(ctx, EditPostInput{PostId: postId, UserId: 1, Body: body})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !view.EditedAt.Valid {
+			t.Errorf("edited post %+v has no edited time", view.Post)
+		}
+	}
+	_, err := postService.EditPost(ctx, EditPostInput{PostId: postId, UserId: 2, Body: "hijacked"})
+	if !errors.Is(err, ErrNotPostAuthor) {
+		t.Errorf("editing someone else's post = %v, want %v", err, ErrNotPostAuthor)
+	}
+	repost, err := postService.Repost(ctx, 2, "user2", postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = postService.EditPost(ctx, EditPostInput{PostId: repost.ID, UserId: 2, Body: "changed"})
+	if !errors.Is(err, ErrPostNotEditable) {
+		t.Errorf("editing a repost = %v, want %v", err, ErrPostNotEditable)
+	}
+
+	// an edit that changes nothing leaves no revision behind
+	revisions, err := postService.GetPostRevisions(ctx, 1, postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodies := []string{}
+	for _, revision := range revisions {
+		bodies = append(bodies, revision.Body)
+	}
+	if want := []string{"second draft", "first draft"}; !reflect.DeepEqual(bodies, want) {
+		t.Errorf("revisions = %v, want %v", bodies, want)
+	}
+	want := []string{"post.updated", "post.updated", "post.reposted"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+
+	postService.config.EditWindow = time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	_, err = postService.EditPost(ctx, EditPostInput{PostId: postId, UserId: 1, Body: "too late"})
+	if !errors.Is(err, ErrEditWindowClosed) {
+		t.Errorf("editing after the window = %v, want %v", err, ErrEditWindowClosed)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+// ExportUserData answers a data export request with every post, post revision,
+// scheduled post, draft, comment and reaction the user left, including ones hidden while the account is deactivated.
+func (p *PostService) ExportUserData(ctx context.Context, exportId int32, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	part := DataExportPartMsg{
+		ExportId: exportId,
+		Service:  "post",
+		Files:    []DataExportFile{},
+	}
+	userPosts, err := p.postQuries.GetAllPostsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load posts"
+	} else {
+		if userPosts == nil {
+			userPosts = []posts.Post{}
+		}
+		postsBytes, err := json.Marshal(userPosts)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "posts.json", Content: postsBytes})
+	}
+	userComments, err := p.postQuries.GetAllCommentsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load comments"
+	} else {
+		if userComments == nil {
+			userComments = []posts.Comment{}
+		}
+		commentsBytes, err := json.Marshal(userComments)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "comments.json", Content: commentsBytes})
+	}
+	userReactions, err := p.postQuries.GetAllReactionsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load reactions"
+	} else {
+		if userReactions == nil {
+			userReactions = []posts.Reaction{}
+		}
+		reactionsBytes, err := json.Marshal(userReactions)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "reactions.json", Content: reactionsBytes})
+	}
+	userRevisions, err := p.postQuries.GetAllPostRevisionsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load post revisions"
+	} else {
+		if userRevisions == nil {
+			userRevisions = []posts.PostRevision{}
+		}
+		revisionsBytes, err := json.Marshal(userRevisions)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "post_revisions.json", Content: revisionsBytes})
+	}
+	userScheduled, err := p.postQuries.GetAllScheduledPostsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load scheduled posts"
+	} else {
+		if userScheduled == nil {
+			userScheduled = []posts.ScheduledPost{}
+		}
+		scheduledBytes, err := json.Marshal(userScheduled)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "scheduled_posts.json", Content: scheduledBytes})
+	}
+	userDrafts, err := p.postQuries.GetAllDraftsByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load drafts"
+	} else {
+		if userDrafts == nil {
+			userDrafts = []posts.Draft{}
+		}
+		draftsBytes, err := json.Marshal(userDrafts)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "drafts.json", Content: draftsBytes})
+	}
+	userVotes, err := p.postQuries.GetAllPollVotesByUserId(timeoutCtx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to load poll votes"
+	} else {
+		if userVotes == nil {
+			userVotes = []posts.PollVote{}
+		}
+		votesBytes, err := json.Marshal(userVotes)
+		if err != nil {
+			return err
+		}
+		part.Files = append(part.Files, DataExportFile{Name: "poll_votes.json", Content: votesBytes})
+	}
+	msg, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	return p.rabbitmProducer.Publish("post_events", "post.export.part", msg)
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sort"
+	"time"
+
+	rpc_client "github.com/BernardN38/socialstream-backend/post_service/rpc/client"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+// followsBackfillPageSize is how many follows one backfill step copies.
+const followsBackfillPageSize = 1000
+
+// FollowAuthor mirrors a follow from the user service and backfills the
+// follower's timeline with the followee's recent posts.
+func (p *PostService) FollowAuthor(ctx context.Context, followerId int32, followeeId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	rows, err := txQuries.CreateFollow(timeoutCtx, posts.CreateFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	err = txQuries.UpdateFollowerCount(timeoutCtx, posts.UpdateFollowerCountParams{
+		UserID: followeeId,
+		Delta:  1,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return p.backfillTimeline(ctx, followerId, followeeId)
+}
+
+// UnfollowAuthor mirrors an unfollow and drops the followee's recent posts
+// from the follower's timeline.
+func (p *PostService) UnfollowAuthor(ctx context.Context, followerId int32, followeeId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	rows, err := txQuries.DeleteFollow(timeoutCtx, posts.DeleteFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	err = txQuries.UpdateFollowerCount(timeoutCtx, posts.UpdateFollowerCountParams{
+		UserID: followeeId,
+		Delta:  -1,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return p.removeAuthorFromTimeline(ctx, followerId, followeeId)
+}
+
+// RemoveFollowsBetween drops follows in both directions after a block.
+func (p *PostService) RemoveFollowsBetween(ctx context.Context, userA int32, userB int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	removed, err := txQuries.DeleteFollowsBetween(timeoutCtx, posts.DeleteFollowsBetweenParams{
+		UserA: userA,
+		UserB: userB,
+	})
+	if err != nil {
+		return err
+	}
+	for _, follow := range removed {
+		err = txQuries.UpdateFollowerCount(timeoutCtx, posts.UpdateFollowerCountParams{
+			UserID: follow.FolloweeID,
+			Delta:  -1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	for _, follow := range removed {
+		err = p.removeAuthorFromTimeline(ctx, follow.FollowerID, follow.FolloweeID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveUserFollows drops every follow of a deleted user and their timeline.
+// Their posts already left other timelines through deactivated_authors.
+func (p *PostService) RemoveUserFollows(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	removed, err := txQuries.DeleteFollowsByUser(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	for _, follow := range removed {
+		if follow.FolloweeID == userId {
+			continue
+		}
+		err = txQuries.UpdateFollowerCount(timeoutCtx, posts.UpdateFollowerCountParams{
+			UserID: follow.FolloweeID,
+			Delta:  -1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	err = txQuries.DeleteFollowerCount(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return p.redisClient.Del(ctx, timelineKey(userId)).Err()
+}
+
+// RunFollowsBackfill copies the follows made before the post service kept a
+// copy of the follow graph from the user service, retrying every interval
+// until it has gone through all of them. Every replica runs it, a page is
+// saved under a row lock and skipped by whoever finds the cursor moved.
+func (p *PostService) RunFollowsBackfill(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		done, err := p.backfillFollows(ctx)
+		if done {
+			return
+		}
+		if err == nil {
+			continue
+		}
+		log.Println("backfill follows:", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backfillFollows copies the next page of follows and reports whether the
+// backfill is complete. The page is read from the user service before the
+// transaction opens. A follow removed while its page is in flight can be
+// copied after the unfollow event was handled and linger until the next
+// unfollow or block between the pair.
+func (p *PostService) backfillFollows(ctx context.Context) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	state, err := p.postQuries.GetFollowsBackfill(timeoutCtx)
+	if err != nil {
+		return false, err
+	}
+	if state.CompletedAt.Valid {
+		return true, nil
+	}
+	follows, err := p.rpcClient.GetFollowsPage(timeoutCtx, state.AfterFollowerID, state.AfterFolloweeID, followsBackfillPageSize)
+	if err != nil {
+		return false, err
+	}
+	created, err := p.saveFollowsPage(timeoutCtx, state, follows)
+	if err != nil {
+		return false, err
+	}
+	for _, follow := range created {
+		err = p.backfillTimeline(ctx, follow.FollowerID, follow.FolloweeID)
+		if err != nil {
+			log.Println("backfill timeline:", err)
+		}
+	}
+	return len(follows) < followsBackfillPageSize, nil
+}
+
+// saveFollowsPage mirrors the follows read after state and moves the cursor
+// past them. Nothing is saved when another replica moved the cursor first.
+func (p *PostService) saveFollowsPage(ctx context.Context, state posts.FollowsBackfill, follows []rpc_client.Follow) ([]posts.CreateFollowsRow, error) {
+	tx, err := p.postDb.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	locked, err := txQuries.GetFollowsBackfillForUpdate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if locked != state {
+		return nil, nil
+	}
+	followerIds := make([]int32, 0, len(follows))
+	followeeIds := make([]int32, 0, len(follows))
+	for _, follow := range follows {
+		followerIds = append(followerIds, follow.FollowerId)
+		followeeIds = append(followeeIds, follow.FolloweeId)
+	}
+	created, err := txQuries.CreateFollows(ctx, posts.CreateFollowsParams{
+		FollowerIds: followerIds,
+		FolloweeIds: followeeIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// follows mirrored from user_events already counted, only new rows do
+	added := map[int32]int32{}
+	for _, follow := range created {
+		added[follow.FolloweeID]++
+	}
+	counted := make([]int32, 0, len(added))
+	for followeeId := range added {
+		counted = append(counted, followeeId)
+	}
+	// a fixed order keeps replicas updating counts from deadlocking
+	sort.Slice(counted, func(i, j int) bool { return counted[i] < counted[j] })
+	for _, followeeId := range counted {
+		err = txQuries.UpdateFollowerCount(ctx, posts.UpdateFollowerCountParams{
+			UserID: followeeId,
+			Delta:  added[followeeId],
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	cursor := posts.UpdateFollowsBackfillParams{
+		AfterFollowerID: state.AfterFollowerID,
+		AfterFolloweeID: state.AfterFolloweeID,
+		Completed:       len(follows) < followsBackfillPageSize,
+	}
+	if len(follows) > 0 {
+		cursor.AfterFollowerID = follows[len(follows)-1].FollowerId
+		cursor.AfterFolloweeID = follows[len(follows)-1].FolloweeId
+	}
+	err = txQuries.UpdateFollowsBackfill(ctx, cursor)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFollowsBackfill(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	fake := SetupRpcServer(t, postService)
+	for _, follow := range [][2]int32{{1, 2}, {3, 2}, {2, 1}} {
+		fake.AddFollow(follow[0], follow[1])
+	}
+	// already mirrored from user_events before the backfill runs
+	err := postService.FollowAuthor(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		done, err := postService.backfillFollows(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !done {
+			t.Errorf("backfill run %d not done after a short page", i)
+		}
+		for userId, want := range map[int32]int32{1: 1, 2: 2, 3: 0} {
+			got, err := postService.postQuries.GetFollowerCount(ctx, userId)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("run %d: user %d has %d followers, want %d", i, userId, got, want)
+			}
+		}
+	}
+	state, err := postService.postQuries.GetFollowsBackfill(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.CompletedAt.Valid || state.AfterFollowerID != 3 || state.AfterFolloweeID != 2 {
+		t.Errorf("backfill state = %+v, want completed after 3 -> 2", state)
+	}
+}
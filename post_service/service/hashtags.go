@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	maxHashtagsPerPost = 10
+	maxHashtagLength   = 50
+
+	// trending counts tag uses in buckets, weighted down by age when read so
+	// a tag has to keep being used to stay on top
+	trendingBucketSize    = 5 * time.Minute
+	trendingWindowBuckets = 12
+	trendingHalfLife      = 30 * time.Minute
+	trendingCacheKey      = "trending:hashtags"
+	trendingCacheTTL      = time.Minute
+	trendingCacheSize     = 50
+)
+
+// a # only starts a tag at the start of the body or after a character that
+// cannot be part of a word, so urls with fragments and "a#b" are skipped
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#/])#([\p{L}\p{N}_]+)`)
+
+// extractHashtags returns the distinct normalized tags in body, sorted so
+// concurrent upserts lock hashtags rows in the same order.
+func extractHashtags(body string) []string {
+	seen := make(map[string]struct{})
+	tags := []string{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(body, -1) {
+		tag := normalizeHashtag(match[1])
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+		if len(tags) == maxHashtagsPerPost {
+			break
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// normalizeHashtag lowercases the tag and drops a leading #. It returns ""
+// for tags that are too long or have no letters, like #1.
+func normalizeHashtag(tag string) string {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	if tag == "" || len([]rune(tag)) > maxHashtagLength {
+		return ""
+	}
+	if strings.IndexFunc(tag, unicode.IsLetter) < 0 {
+		return ""
+	}
+	return tag
+}
+
+// tagsDifference returns the tags in a that are not in b.
+func tagsDifference(a []string, b []string) []string {
+	inB := make(map[string]struct{}, len(b))
+	for _, tag := range b {
+		inB[tag] = struct{}{}
+	}
+	diff := []string{}
+	for _, tag := range a {
+		if _, ok := inB[tag]; !ok {
+			diff = append(diff, tag)
+		}
+	}
+	return diff
+}
+
+func setPostHashtags(ctx context.Context, queries *posts.Queries, postId int32, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	hashtagIds, err := queries.UpsertHashtags(ctx, tags)
+	if err != nil {
+		return err
+	}
+	return queries.CreatePostHashtags(ctx, posts.CreatePostHashtagsParams{
+		PostID:     postId,
+		HashtagIds: hashtagIds,
+	})
+}
+
+// GetHashtagPosts pages the posts using tag, newest first.
+func (p *PostService) GetHashtagPosts(ctx context.Context, viewerId int32, tag string, page HashtagPageReq) (*HashtagPageResp, error) {
+	tag = normalizeHashtag(tag)
+	if tag == "" {
+		return nil, errors.New("invalid hashtag")
+	}
+	beforeId, err := decodePostCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	rows, err := p.postQuries.GetHashtagPostPage(timeoutCtx, posts.GetHashtagPostPageParams{
+		Tag:       tag,
+		BeforeID:  beforeId,
+		PageLimit: page.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(rows) > int(page.Limit)
+	if hasMore {
+		rows = rows[:page.Limit]
+	}
+	// the cursor comes from the rows read, a page can be short when some
+	// of them are hidden from the viewer
+	resp := &HashtagPageResp{Tag: tag}
+	if hasMore {
+		resp.NextCursor = encodePostCursor(rows[len(rows)-1].ID)
+	}
+	rows, err = p.visiblePosts(timeoutCtx, viewerId, rows)
+	if err != nil {
+		return nil, err
+	}
+	resp.Posts, err = p.toPostViews(timeoutCtx, viewerId, rows)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func trendingBucket(at time.Time) int64 {
+	return at.Unix() / int64(trendingBucketSize/time.Second)
+}
+
+func trendingBucketKey(bucket int64) string {
+	return fmt.Sprintf("trending:bucket:%d", bucket)
+}
+
+// recordTrendingTags adds delta uses of each tag to the bucket of at. Uses
+// older than the window are ignored, their buckets are gone or about to be.
+func (p *PostService) recordTrendingTags(ctx context.Context, tags []string, at time.Time, delta float64) {
+	if len(tags) == 0 || time.Since(at) > trendingBucketSize*trendingWindowBuckets {
+		return
+	}
+	key := trendingBucketKey(trendingBucket(at))
+	pipe := p.redisClient.Pipeline()
+	for _, tag := range tags {
+		pipe.ZIncrBy(ctx, key, delta, tag)
+	}
+	if delta < 0 {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "0")
+	}
+	pipe.Expire(ctx, key, trendingBucketSize*(trendingWindowBuckets+1))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Println("record trending tags:", err)
+	}
+}
+
+// adjustAuthorTrending takes the author's recent tag uses out of trending
+// while their account is deactivated, or puts them back.
+func (p *PostService) adjustAuthorTrending(ctx context.Context, userId int32, delta float64) error {
+	uses, err := p.postQuries.GetRecentHashtagUsesByUser(ctx, posts.GetRecentHashtagUsesByUserParams{
+		UserID: userId,
+		Since:  time.Now().Add(-trendingBucketSize * trendingWindowBuckets),
+	})
+	if err != nil {
+		return err
+	}
+	for _, use := range uses {
+		p.recordTrendingTags(ctx, []string{use.Tag}, use.CreatedAt, delta)
+	}
+	if len(uses) > 0 {
+		p.redisClient.Del(ctx, trendingCacheKey)
+	}
+	return nil
+}
+
+// GetTrendingHashtags ranks tags by their uses in the window, each bucket
+// weighted by its age so recent velocity counts the most. The ranking is
+// cached for a minute.
+func (p *PostService) GetTrendingHashtags(ctx context.Context, limit int32) ([]TrendingHashtag, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	exists, err := p.redisClient.Exists(timeoutCtx, trendingCacheKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		now := trendingBucket(time.Now())
+		store := &redis.ZStore{}
+		for age := int64(0); age < trendingWindowBuckets; age++ {
+			store.Keys = append(store.Keys, trendingBucketKey(now-age))
+			halfLives := float64(time.Duration(age)*trendingBucketSize) / float64(trendingHalfLife)
+			store.Weights = append(store.Weights, math.Pow(0.5, halfLives))
+		}
+		pipe := p.redisClient.TxPipeline()
+		pipe.ZUnionStore(timeoutCtx, trendingCacheKey, store)
+		pipe.ZRemRangeByRank(timeoutCtx, trendingCacheKey, 0, -(trendingCacheSize + 1))
+		pipe.Expire(timeoutCtx, trendingCacheKey, trendingCacheTTL)
+		_, err = pipe.Exec(timeoutCtx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ranked, err := p.redisClient.ZRevRangeWithScores(timeoutCtx, trendingCacheKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	trending := make([]TrendingHashtag, 0, len(ranked))
+	for _, z := range ranked {
+		tag, ok := z.Member.(string)
+		if !ok || z.Score <= 0 {
+			continue
+		}
+		trending = append(trending, TrendingHashtag{
+			Tag:   tag,
+			Score: z.Score,
+		})
+	}
+	return trending, nil
+}
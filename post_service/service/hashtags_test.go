@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	manyTags := []string{}
+	for i := 0; i < maxHashtagsPerPost+2; i++ {
+		manyTags = append(manyTags, fmt.Sprintf("#tag%02d", i))
+	}
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{name: "no tags", body: "just a post", want: []string{}},
+		{name: "start of body", body: "#golang is fun", want: []string{"golang"}},
+		{name: "sorted and lowercased", body: "learning #Rust and #Go today", want: []string{"go", "rust"}},
+		{name: "duplicates in any case", body: "#Go #go #GO", want: []string{"go"}},
+		{name: "punctuation before", body: "(#first) and,#second", want: []string{"first", "second"}},
+		{name: "trailing punctuation", body: "done #shipit!", want: []string{"shipit"}},
+		{name: "inside a word", body: "a#b c#d", want: []string{}},
+		{name: "url fragment", body: "see https://example.com/page#section", want: []string{}},
+		{name: "html entity", body: "fish &#38; chips", want: []string{}},
+		{name: "double hash", body: "##twice", want: []string{}},
+		{name: "digits only", body: "#1 #2024", want: []string{}},
+		{name: "digits and letters", body: "#2024goals", want: []string{"2024goals"}},
+		{name: "underscore", body: "#go_lang", want: []string{"go_lang"}},
+		{name: "unicode letters", body: "#café #日本", want: []string{"café", "日本"}},
+		{name: "too long", body: "#" + strings.Repeat("a", maxHashtagLength+1), want: []string{}},
+		{name: "capped per post", body: strings.Join(manyTags, " "), want: []string{
+			"tag00", "tag01", "tag02", "tag03", "tag04", "tag05", "tag06", "tag07", "tag08", "tag09",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractHashtags(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractHashtags(%q) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "plain", tag: "golang", want: "golang"},
+		{name: "leading hash", tag: "#GoLang", want: "golang"},
+		{name: "empty", tag: "", want: ""},
+		{name: "hash only", tag: "#", want: ""},
+		{name: "digits only", tag: "#123", want: ""},
+		{name: "underscore only", tag: "___", want: ""},
+		{name: "max length", tag: strings.Repeat("a", maxHashtagLength), want: strings.Repeat("a", maxHashtagLength)},
+		{name: "over max length", tag: strings.Repeat("a", maxHashtagLength+1), want: ""},
+		{name: "length counts characters", tag: strings.Repeat("é", maxHashtagLength), want: strings.Repeat("é", maxHashtagLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := normalizeHashtag(tt.tag)
+			if got != tt.want {
+				t.Errorf("normalizeHashtag(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashtagPostsAndTrending(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	edited := createTestPost(t, postService, 1, "#Go and #golang")
+	second := createTestPost(t, postService, 2, "#go again")
+	createTestPost(t, postService, 3, "#rust")
+	createTestPost(t, postService, 3, "more #rust")
+	third := createTestPost(t, postService, 2, "still #go")
+
+	tagPostIds := func(tag string) []int32 {
+		t.Helper()
+		resp, err := postService.GetHashtagPosts(ctx, 0, tag, HashtagPageReq{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []int32{}
+		for _, post := range resp.Posts {
+			got = append(got, post.ID)
+		}
+		return got
+	}
+	trendingTags := func() []string {
+		t.Helper()
+		// skip the cached ranking so every call sees the latest uses
+		err := postService.redisClient.Del(ctx, trendingCacheKey).Err()
+		if err != nil {
+			t.Fatal(err)
+		}
+		trending, err := postService.GetTrendingHashtags(ctx, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, tag := range trending {
+			got = append(got, tag.Tag)
+		}
+		return got
+	}
+
+	if got, want := tagPostIds("#GO"), []int32{third, second, edited}; !reflect.DeepEqual(got, want) {
+		t.Errorf("#go posts = %v, want %v", got, want)
+	}
+	if got, want := trendingTags(), []string{"go", "rust", "golang"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("trending = %v, want %v", got, want)
+	}
+	// an edit moves only the tags it added or removed
+	_, err := postService.EditPost(ctx, EditPostInput{PostId: edited, UserId: 1, Body: "now #rust"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tagPostIds("go"), []int32{third, second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("#go posts after edit = %v, want %v", got, want)
+	}
+	if got, want := trendingTags(), []string{"rust", "go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("trending after edit = %v, want %v", got, want)
+	}
+	err = postService.HideAuthorPosts(ctx, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := trendingTags(), []string{"go", "rust"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("trending after deactivation = %v, want %v", got, want)
+	}
+}
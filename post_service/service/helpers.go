@@ -1,9 +1,11 @@
 package service
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
-
-	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"math"
+	"time"
 )
 
 func calculateOffsetAndLimit(pageNo int32, pageSize int32) (int32, int32) {
@@ -14,7 +16,7 @@ func calculateOffsetAndLimit(pageNo int32, pageSize int32) (int32, int32) {
 	return offset, limit
 }
 
-func createPostPageResp(posts []posts.Post, pageNo int, pageSize int, limit int, offset int) (*PostPageResp, error) {
+func createPostPageResp(posts []PostView, pageNo int, pageSize int, limit int, offset int) (*PostPageResp, error) {
 	//check posts is not empty
 	if len(posts) <= 0 {
 		return nil, errors.New("no posts found")
@@ -32,3 +34,120 @@ func createPostPageResp(posts []posts.Post, pageNo int, pageSize int, limit int,
 	}, nil
 
 }
+
+// commentCursor holds the id of the last comment on a page. It is handed to
+// clients base64 encoded so they treat it as opaque.
+type commentCursor struct {
+	Id int32 `json:"i"`
+}
+
+func encodeCommentCursor(commentId int32) string {
+	cursorBytes, _ := json.Marshal(commentCursor{Id: commentId})
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+func decodeCommentCursor(encoded string) (int32, error) {
+	if encoded == "" {
+		return 0, nil
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	var cursor commentCursor
+	err = json.Unmarshal(cursorBytes, &cursor)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	return cursor.Id, nil
+}
+
+// reactorCursor holds the sort key of the last reactor on a page.
+type reactorCursor struct {
+	CreatedAt time.Time `json:"c"`
+	UserId    int32     `json:"u"`
+}
+
+func encodeReactorCursor(cursor reactorCursor) string {
+	cursorBytes, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// decodeReactorCursor returns a cursor past every reaction for the first page.
+func decodeReactorCursor(encoded string) (reactorCursor, error) {
+	if encoded == "" {
+		return reactorCursor{
+			CreatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+			UserId:    math.MaxInt32,
+		}, nil
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return reactorCursor{}, errors.New("invalid cursor")
+	}
+	var cursor reactorCursor
+	err = json.Unmarshal(cursorBytes, &cursor)
+	if err != nil {
+		return reactorCursor{}, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// postCursor holds the id of the last post on a page of a feed ordered by
+// post id.
+type postCursor struct {
+	Id int32 `json:"p"`
+}
+
+func encodePostCursor(postId int32) string {
+	cursorBytes, _ := json.Marshal(postCursor{Id: postId})
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// decodePostCursor returns an id past every post for the first page.
+func decodePostCursor(encoded string) (int32, error) {
+	if encoded == "" {
+		return math.MaxInt32, nil
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, errors.New("invalid cursor")
+	}
+	var cursor postCursor
+	err = json.Unmarshal(cursorBytes, &cursor)
+	if err != nil || cursor.Id <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return cursor.Id, nil
+}
+
+// postPageCursor holds the sort key of a post at the edge of a user post page.
+type postPageCursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        int32     `json:"i"`
+}
+
+func encodePostPageCursor(createdAt time.Time, postId int32) string {
+	cursorBytes, _ := json.Marshal(postPageCursor{CreatedAt: createdAt, Id: postId})
+	return base64.RawURLEncoding.EncodeToString(cursorBytes)
+}
+
+// decodePostPageCursor returns a cursor past every post for the first page.
+func decodePostPageCursor(encoded string) (postPageCursor, error) {
+	if encoded == "" {
+		return postPageCursor{
+			CreatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+			Id:        math.MaxInt32,
+		}, nil
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return postPageCursor{}, errors.New("invalid cursor")
+	}
+	var cursor postPageCursor
+	err = json.Unmarshal(cursorBytes, &cursor)
+	if err != nil {
+		return postPageCursor{}, errors.New("invalid cursor")
+	}
+	return cursor, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCommentCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		commentId int32
+	}{
+		{name: "small id", commentId: 1},
+		{name: "large id", commentId: 2147483647},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCommentCursor(encodeCommentCursor(tt.commentId))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got != tt.commentId {
+				t.Errorf("round trip = %d, want %d", got, tt.commentId)
+			}
+		})
+	}
+}
+
+func TestDecodeCommentCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    int32
+		wantErr bool
+	}{
+		{name: "first page", encoded: "", want: 0},
+		{name: "not base64", encoded: "!!!", wantErr: true},
+		{name: "not json", encoded: base64.RawURLEncoding.EncodeToString([]byte("12")), wantErr: true},
+		{name: "padded base64", encoded: base64.URLEncoding.EncodeToString([]byte(`{"i":5}`)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCommentCursor(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeCommentCursor(%q) error = %v, wantErr %v", tt.encoded, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("decodeCommentCursor(%q) = %d, want %d", tt.encoded, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReactorCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor reactorCursor
+	}{
+		{name: "utc", cursor: reactorCursor{CreatedAt: time.Date(2024, 3, 1, 10, 30, 0, 123456000, time.UTC), UserId: 7}},
+		{name: "same time other user", cursor: reactorCursor{CreatedAt: time.Date(2024, 3, 1, 10, 30, 0, 123456000, time.UTC), UserId: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeReactorCursor(encodeReactorCursor(tt.cursor))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !got.CreatedAt.Equal(tt.cursor.CreatedAt) || got.UserId != tt.cursor.UserId {
+				t.Errorf("round trip = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeReactorCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    reactorCursor
+		wantErr bool
+	}{
+		{
+			name:    "first page starts past every reaction",
+			encoded: "",
+			want:    reactorCursor{CreatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), UserId: math.MaxInt32},
+		},
+		{name: "not base64", encoded: "%%", wantErr: true},
+		{name: "bad time", encoded: base64.RawURLEncoding.EncodeToString([]byte(`{"c":"yesterday","u":1}`)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeReactorCursor(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeReactorCursor(%q) error = %v, wantErr %v", tt.encoded, err, tt.wantErr)
+			}
+			if !tt.wantErr && (!got.CreatedAt.Equal(tt.want.CreatedAt) || got.UserId != tt.want.UserId) {
+				t.Errorf("decodeReactorCursor(%q) = %+v, want %+v", tt.encoded, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePostCursor(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    int32
+		wantErr bool
+	}{
+		{name: "first page starts past every post", encoded: "", want: math.MaxInt32},
+		{name: "round trip", encoded: encodePostCursor(42), want: 42},
+		{name: "zero id", encoded: encodePostCursor(0), wantErr: true},
+		{name: "negative id", encoded: encodePostCursor(-3), wantErr: true},
+		{name: "not base64", encoded: "%%", wantErr: true},
+		{name: "not json", encoded: base64.RawURLEncoding.EncodeToString([]byte("42")), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePostCursor(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePostCursor(%q) error = %v, wantErr %v", tt.encoded, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("decodePostCursor(%q) = %d, want %d", tt.encoded, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePostPageCursor(t *testing.T) {
+	createdAt := time.Date(2024, 5, 2, 8, 15, 30, 987654000, time.UTC)
+	tests := []struct {
+		name    string
+		encoded string
+		want    postPageCursor
+		wantErr bool
+	}{
+		{
+			name:    "first page starts past every post",
+			encoded: "",
+			want:    postPageCursor{CreatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), Id: math.MaxInt32},
+		},
+		{
+			name:    "round trip keeps sub-second time",
+			encoded: encodePostPageCursor(createdAt, 17),
+			want:    postPageCursor{CreatedAt: createdAt, Id: 17},
+		},
+		{
+			name:    "round trip other zone",
+			encoded: encodePostPageCursor(createdAt.In(time.FixedZone("UTC+2", 2*60*60)), 18),
+			want:    postPageCursor{CreatedAt: createdAt, Id: 18},
+		},
+		{name: "not base64", encoded: "%%", wantErr: true},
+		{name: "bad time", encoded: base64.RawURLEncoding.EncodeToString([]byte(`{"c":1,"i":1}`)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePostPageCursor(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodePostPageCursor(%q) error = %v, wantErr %v", tt.encoded, err, tt.wantErr)
+			}
+			if !tt.wantErr && (!got.CreatedAt.Equal(tt.want.CreatedAt) || got.Id != tt.want.Id) {
+				t.Errorf("decodePostPageCursor(%q) = %+v, want %+v", tt.encoded, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+const maxMentionsPerPost = 10
+
+// an @ only starts a mention at the start of the body or after a character
+// that cannot be part of a username or email address
+var mentionPattern = regexp.MustCompile(`(?:^|[^A-Za-z0-9_@.])(@[A-Za-z0-9_]{1,30})\b`)
+
+type mentionMatch struct {
+	Username string
+	// Start and End are character offsets covering the @, End is exclusive
+	Start int32
+	End   int32
+}
+
+// extractMentions finds the @mentions in body. Every occurrence is kept but
+// only the first maxMentionsPerPost distinct usernames are.
+func extractMentions(body string) []mentionMatch {
+	seen := make(map[string]struct{})
+	matches := []mentionMatch{}
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(body, -1) {
+		start, end := loc[2], loc[3]
+		username := body[start+1 : end]
+		key := strings.ToLower(username)
+		if _, ok := seen[key]; !ok {
+			if len(seen) == maxMentionsPerPost {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		matches = append(matches, mentionMatch{
+			Username: username,
+			Start:    int32(utf8.RuneCountInString(body[:start])),
+			End:      int32(utf8.RuneCountInString(body[:end])),
+		})
+	}
+	return matches
+}
+
+// resolveMentions keeps the mentions in body that name a user the author can
+// mention. Anything else stays plain text. When the user service cannot be
+// reached in time the post is saved without mentions rather than failing.
+func (p *PostService) resolveMentions(ctx context.Context, authorId int32, body string) []posts.PostMention {
+	matches := extractMentions(body)
+	if len(matches) == 0 {
+		return nil
+	}
+	usernames := []string{}
+	seen := make(map[string]struct{})
+	for _, match := range matches {
+		key := strings.ToLower(match.Username)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		usernames = append(usernames, match.Username)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	resolved, err := p.rpcClient.ResolveMentions(timeoutCtx, authorId, usernames)
+	if err != nil {
+		log.Println("resolve mentions:", err)
+		return nil
+	}
+	users := make(map[string]int32, len(resolved))
+	canonical := make(map[string]string, len(resolved))
+	for _, user := range resolved {
+		key := strings.ToLower(user.Username)
+		users[key] = user.UserId
+		canonical[key] = user.Username
+	}
+	mentions := []posts.PostMention{}
+	for _, match := range matches {
+		key := strings.ToLower(match.Username)
+		userId, ok := users[key]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, posts.PostMention{
+			UserID:      userId,
+			Username:    canonical[key],
+			StartOffset: match.Start,
+			EndOffset:   match.End,
+		})
+	}
+	return mentions
+}
+
+func setPostMentions(ctx context.Context, queries *posts.Queries, postId int32, mentions []posts.PostMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	params := posts.CreatePostMentionsParams{PostID: postId}
+	for _, mention := range mentions {
+		params.UserIds = append(params.UserIds, mention.UserID)
+		params.Usernames = append(params.Usernames, mention.Username)
+		params.StartOffsets = append(params.StartOffsets, mention.StartOffset)
+		params.EndOffsets = append(params.EndOffsets, mention.EndOffset)
+	}
+	return queries.CreatePostMentions(ctx, params)
+}
+
+// publishMentions sends one post.mentioned event per mentioned user, skipping
+// the author and any user in alreadyNotified.
+func (p *PostService) publishMentions(postId int32, authorId int32, authorUsername string, mentions []posts.PostMention, alreadyNotified []int32) {
+	notified := make(map[int32]struct{}, len(alreadyNotified)+1)
+	notified[authorId] = struct{}{}
+	for _, userId := range alreadyNotified {
+		notified[userId] = struct{}{}
+	}
+	for _, mention := range mentions {
+		if _, ok := notified[mention.UserID]; ok {
+			continue
+		}
+		notified[mention.UserID] = struct{}{}
+		msg, err := json.Marshal(rabbitmq_producer.PostMentionedMsg{
+			PostId:          postId,
+			AuthorId:        authorId,
+			AuthorUsername:  authorUsername,
+			MentionedUserId: mention.UserID,
+		})
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		err = p.rabbitmProducer.Publish("post_events", "post.mentioned", msg)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// RemoveMentionsBetween drops mentions either user made of the other after a
+// block, they render as plain text from then on.
+func (p *PostService) RemoveMentionsBetween(ctx context.Context, userA int32, userB int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+	return p.postQuries.DeleteMentionsBetween(timeoutCtx, posts.DeleteMentionsBetweenParams{
+		UserA: userA,
+		UserB: userB,
+	})
+}
+
+// RemoveUserMentions drops every mention of a deleted user.
+func (p *PostService) RemoveUserMentions(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return p.postQuries.DeleteMentionsOfUser(timeoutCtx, userId)
+}
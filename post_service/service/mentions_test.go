@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+func TestExtractMentions(t *testing.T) {
+	manyMentions := []string{}
+	for i := 0; i < maxMentionsPerPost+1; i++ {
+		manyMentions = append(manyMentions, fmt.Sprintf("@user%02d", i))
+	}
+	capped := []mentionMatch{}
+	for i := 0; i < maxMentionsPerPost; i++ {
+		capped = append(capped, mentionMatch{Username: fmt.Sprintf("user%02d", i), Start: int32(i * 8), End: int32(i*8 + 7)})
+	}
+	tests := []struct {
+		name string
+		body string
+		want []mentionMatch
+	}{
+		{name: "no mentions", body: "hello there", want: []mentionMatch{}},
+		{name: "start of body", body: "@alice hi", want: []mentionMatch{
+			{Username: "alice", Start: 0, End: 6},
+		}},
+		{name: "after text", body: "hi @bob_1!", want: []mentionMatch{
+			{Username: "bob_1", Start: 3, End: 9},
+		}},
+		{name: "offsets count characters not bytes", body: "héllo 日本 @carol", want: []mentionMatch{
+			{Username: "carol", Start: 9, End: 15},
+		}},
+		{name: "emoji before", body: "🎉🎉 @dave", want: []mentionMatch{
+			{Username: "dave", Start: 3, End: 8},
+		}},
+		{name: "email address", body: "mail me at erin@example.com", want: []mentionMatch{}},
+		{name: "double at", body: "@@frank", want: []mentionMatch{}},
+		{name: "after dot", body: "x.@grace", want: []mentionMatch{}},
+		{name: "every occurrence kept", body: "@Heidi and @heidi", want: []mentionMatch{
+			{Username: "Heidi", Start: 0, End: 6},
+			{Username: "heidi", Start: 11, End: 17},
+		}},
+		{name: "too long is not a mention", body: "@" + strings.Repeat("a", 31), want: []mentionMatch{}},
+		{name: "distinct usernames capped", body: strings.Join(manyMentions, " "), want: capped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractMentions(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractMentions(%q) = %+v, want %+v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveMentionsGivesUp(t *testing.T) {
+	postService := &PostService{}
+	fake := SetupRpcServer(t, postService)
+	fake.AddMentionable(5, "alice")
+	release := fake.Hang()
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	mentions := postService.resolveMentions(ctx, 1, "hi @alice")
+	if len(mentions) != 0 {
+		t.Errorf("mentions from a hung user service = %+v, want none", mentions)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("resolving mentions took %v with the user service hung", elapsed)
+	}
+}
+
+func TestPostMentions(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	fake := SetupRpcServer(t, postService)
+	fake.AddMentionable(1, "author")
+	fake.AddMentionable(5, "Alice")
+	fake.AddMentionable(6, "bob")
+
+	postId := createTestPost(t, postService, 1, "hi @alice, @ghost and @author, bye @ALICE")
+	mentionsOf := func() []posts.PostMention {
+		t.Helper()
+		page, err := postService.GetUserPostsByCursor(ctx, 0, 1, PostPageReq{Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Posts) != 1 || page.Posts[0].ID != postId {
+			t.Fatalf("got posts %+v, want post %d", page.Posts, postId)
+		}
+		return page.Posts[0].Mentions
+	}
+	mentionedIds := func() []int32 {
+		t.Helper()
+		ids := []int32{}
+		for _, message := range producer.Messages("post.mentioned") {
+			var msg rabbitmq_producer.PostMentionedMsg
+			if err := json.Unmarshal(message, &msg); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, msg.MentionedUserId)
+		}
+		return ids
+	}
+
+	// unknown users stay plain text and names take the canonical case
+	want := []posts.PostMention{
+		{PostID: postId, UserID: 5, Username: "Alice", StartOffset: 3, EndOffset: 9},
+		{PostID: postId, UserID: 1, Username: "author", StartOffset: 22, EndOffset: 29},
+		{PostID: postId, UserID: 5, Username: "Alice", StartOffset: 35, EndOffset: 41},
+	}
+	if got := mentionsOf(); !reflect.DeepEqual(got, want) {
+		t.Errorf("mentions = %+v, want %+v", got, want)
+	}
+	// the author is never notified and a user only once per post
+	if got := mentionedIds(); !reflect.DeepEqual(got, []int32{5}) {
+		t.Errorf("notified %v, want [5]", got)
+	}
+	_, err := postService.EditPost(ctx, EditPostInput{PostId: postId, UserId: 1, Body: "hi @bob and @alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mentionedIds(); !reflect.DeepEqual(got, []int32{5, 6}) {
+		t.Errorf("notified after edit %v, want [5 6]", got)
+	}
+	err = postService.RemoveMentionsBetween(ctx, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []posts.PostMention{
+		{PostID: postId, UserID: 6, Username: "bob", StartOffset: 3, EndOffset: 7},
+	}
+	if got := mentionsOf(); !reflect.DeepEqual(got, want) {
+		t.Errorf("mentions after block = %+v, want %+v", got, want)
+	}
+}
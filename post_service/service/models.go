@@ -1,19 +1,176 @@
 package service
 
 import (
+	"encoding/json"
 	"mime/multipart"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
 
 	"github.com/go-playground/validator/v10"
 )
 
 type CreatePostInput struct {
-	UserId    int32  `json:"userId" validate:"required"`
-	Username  string `json:"username", validate:"required"`
-	Body      string `json:"body" validate:"required"`
-	MediaId   int32  `json:"mediaId"`
-	Media     multipart.File
-	MediaType string
-	MediaSize int64
+	UserId   int32  `json:"userId" validate:"required"`
+	Username string `json:"username" validate:"required"`
+	Body     string `json:"body" validate:"required"`
+	MediaId  int32  `json:"mediaId"`
+	// Visibility is one of the Visibility constants, public when empty
+	Visibility string `json:"visibility"`
+	// Attachments are uploaded in order, at most MaxPostAttachments
+	Attachments []AttachmentUpload
+	// Poll is optional
+	Poll *PollInput
+}
+
+type PollInput struct {
+	// Options are shown in order, 2 to 4 of them
+	Options        []string
+	EndsAt         time.Time
+	MultipleChoice bool
+}
+
+type SchedulePostInput struct {
+	UserId     int32
+	Username   string
+	Body       string
+	Visibility string
+	PublishAt  time.Time
+	// Attachments are uploaded when the post is scheduled
+	Attachments []AttachmentUpload
+}
+
+type SaveDraftInput struct {
+	DraftId    int32
+	UserId     int32
+	Body       string
+	Visibility string
+	// Version is the draft version the client last loaded, 0 skips the check
+	Version int32
+	// RemoveMedia drops the attachments, new Attachments replace them
+	RemoveMedia bool
+	Attachments []AttachmentUpload
+}
+
+type AttachmentUpload struct {
+	File        multipart.File
+	ContentType string
+	Size        int64
+	AltText     string
+}
+
+type EditPostInput struct {
+	PostId int32
+	UserId int32
+	Body   string
+	// RemoveMedia drops the attachments, new Attachments replace them
+	RemoveMedia bool
+	Attachments []AttachmentUpload
+}
+
+// PostView is a post as returned to clients, with its reaction counts and
+// the reactions the viewer left on it. Reposts and quotes embed the post
+// they share, or set Tombstone once it was deleted or hidden.
+type PostView struct {
+	posts.Post
+	Reactions       map[string]int64    `json:"reactions"`
+	ViewerReactions []string            `json:"viewerReactions"`
+	Mentions        []posts.PostMention `json:"mentions"`
+	Attachments     []posts.PostMedium  `json:"attachments"`
+	Poll            *PollView           `json:"poll,omitempty"`
+	Original        *PostView           `json:"original,omitempty"`
+	Tombstone       bool                `json:"tombstone,omitempty"`
+}
+
+// PollView is a post's poll. Vote counts are left out until the viewer has
+// voted or the poll has closed.
+type PollView struct {
+	MultipleChoice bool             `json:"multipleChoice"`
+	EndsAt         time.Time        `json:"endsAt"`
+	Closed         bool             `json:"closed"`
+	Options        []PollOptionView `json:"options"`
+	VoterCount     *int32           `json:"voterCount,omitempty"`
+	// ViewerVotes are the positions the viewer picked, empty when they did
+	// not vote
+	ViewerVotes []int32 `json:"viewerVotes"`
+}
+
+type PollOptionView struct {
+	Position  int32  `json:"position"`
+	Text      string `json:"text"`
+	VoteCount *int32 `json:"voteCount,omitempty"`
+}
+
+type ReactorPageReq struct {
+	// After is the opaque cursor returned as nextCursor by the previous page
+	After string
+	Limit int32
+}
+type ReactorPageResp struct {
+	Reactors   []posts.GetReactorsRow `json:"reactors"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
+}
+
+type TimelinePageReq struct {
+	// After is the opaque cursor returned as nextCursor by the previous page
+	After string
+	Limit int32
+}
+type TimelinePageResp struct {
+	Posts      []PostView `json:"posts"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+type HashtagPageReq struct {
+	// After is the opaque cursor returned as nextCursor by the previous page
+	After string
+	Limit int32
+}
+type HashtagPageResp struct {
+	Tag        string     `json:"tag"`
+	Posts      []PostView `json:"posts"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+}
+
+type TrendingHashtag struct {
+	Tag   string  `json:"tag"`
+	Score float64 `json:"score"`
+}
+
+type CreateCommentInput struct {
+	PostId          int32
+	ParentCommentId int32
+	UserId          int32
+	Username        string
+	Body            string
+}
+
+type CommentPageReq struct {
+	// After is the opaque cursor returned as nextCursor by the previous page
+	After string
+	Limit int32
+}
+type CommentPageResp struct {
+	Comments   []posts.Comment `json:"comments"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
+
+type DataExportRequestedMsg struct {
+	ExportId int32 `json:"exportId"`
+	UserId   int32 `json:"userId"`
+}
+
+// DataExportPartMsg carries the user's posts for a data export.
+type DataExportPartMsg struct {
+	ExportId int32            `json:"exportId"`
+	Service  string           `json:"service"`
+	Files    []DataExportFile `json:"files"`
+	Error    string           `json:"error,omitempty"`
+}
+
+type DataExportFile struct {
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
 }
 
 func Validate(input interface{}) error {
@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 100
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+	// pollCloseBatchSize caps how many ended polls one tick closes
+	pollCloseBatchSize = 50
+)
+
+var (
+	ErrPollNotFound     = errors.New("post has no poll")
+	ErrPollClosed       = errors.New("poll is closed")
+	ErrAlreadyVoted     = errors.New("already voted in this poll")
+	ErrInvalidPollVote  = errors.New("vote must pick existing options, exactly one unless the poll is multiple choice")
+	ErrInvalidPollEndAt = fmt.Errorf("poll must end between %v and %v from now", minPollDuration, maxPollDuration)
+	ErrInvalidPoll      = fmt.Errorf("poll needs %d to %d distinct options of at most %d characters", minPollOptions, maxPollOptions, maxPollOptionLength)
+)
+
+// validatePoll trims the options in place and checks them and the end time.
+func validatePoll(poll *PollInput) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return ErrInvalidPoll
+	}
+	seen := make(map[string]struct{}, len(poll.Options))
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || len([]rune(option)) > maxPollOptionLength {
+			return ErrInvalidPoll
+		}
+		key := strings.ToLower(option)
+		if _, ok := seen[key]; ok {
+			return ErrInvalidPoll
+		}
+		seen[key] = struct{}{}
+		poll.Options[i] = option
+	}
+	untilEnd := time.Until(poll.EndsAt)
+	if untilEnd < minPollDuration || untilEnd > maxPollDuration {
+		return ErrInvalidPollEndAt
+	}
+	return nil
+}
+
+func createPoll(ctx context.Context, queries *posts.Queries, postId int32, poll PollInput) error {
+	err := queries.CreatePoll(ctx, posts.CreatePollParams{
+		PostID:         postId,
+		MultipleChoice: poll.MultipleChoice,
+		EndsAt:         poll.EndsAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	return queries.CreatePollOptions(ctx, posts.CreatePollOptionsParams{
+		PostID: postId,
+		Texts:  poll.Options,
+	})
+}
+
+func pollClosed(poll posts.Poll, now time.Time) bool {
+	return poll.ClosedAt.Valid || !now.Before(poll.EndsAt)
+}
+
+// loadPolls builds the views of the polls carried by the posts, keyed by
+// post id.
+func (p *PostService) loadPolls(ctx context.Context, viewerId int32, postIds []int32) (map[int32]*PollView, error) {
+	views := make(map[int32]*PollView)
+	if len(postIds) == 0 {
+		return views, nil
+	}
+	polls, err := p.postQuries.GetPollsByPostIds(ctx, postIds)
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return views, nil
+	}
+	pollIds := make([]int32, 0, len(polls))
+	for _, poll := range polls {
+		pollIds = append(pollIds, poll.PostID)
+	}
+	options, err := p.postQuries.GetPollOptionsByPostIds(ctx, pollIds)
+	if err != nil {
+		return nil, err
+	}
+	viewerVotes := make(map[int32][]int32)
+	if viewerId > 0 {
+		votes, err := p.postQuries.GetViewerPollVotes(ctx, posts.GetViewerPollVotesParams{
+			ViewerID: viewerId,
+			PostIds:  pollIds,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range votes {
+			viewerVotes[vote.PostID] = vote.Positions
+		}
+	}
+
+	now := time.Now().UTC()
+	showResults := make(map[int32]bool, len(polls))
+	for _, poll := range polls {
+		poll := poll
+		closed := pollClosed(poll, now)
+		votes, voted := viewerVotes[poll.PostID]
+		if votes == nil {
+			votes = []int32{}
+		}
+		view := &PollView{
+			MultipleChoice: poll.MultipleChoice,
+			EndsAt:         poll.EndsAt,
+			Closed:         closed,
+			Options:        []PollOptionView{},
+			ViewerVotes:    votes,
+		}
+		showResults[poll.PostID] = closed || voted
+		if showResults[poll.PostID] {
+			view.VoterCount = &poll.VoterCount
+		}
+		views[poll.PostID] = view
+	}
+	for _, option := range options {
+		view, ok := views[option.PostID]
+		if !ok {
+			continue
+		}
+		optionView := PollOptionView{
+			Position: option.Position,
+			Text:     option.Text,
+		}
+		if showResults[option.PostID] {
+			voteCount := option.VoteCount
+			optionView.VoteCount = &voteCount
+		}
+		view.Options = append(view.Options, optionView)
+	}
+	return views, nil
+}
+
+// GetPoll returns the poll of a post the viewer can read.
+func (p *PostService) GetPoll(ctx context.Context, viewerId int32, postId int32) (*PollView, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_, err := p.getReadablePost(timeoutCtx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+	polls, err := p.loadPolls(timeoutCtx, viewerId, []int32{postId})
+	if err != nil {
+		return nil, err
+	}
+	poll, ok := polls[postId]
+	if !ok {
+		return nil, ErrPollNotFound
+	}
+	return poll, nil
+}
+
+// VotePoll records the user's one vote and bumps the counts in the same
+// transaction. The poll row is locked first so votes racing the poll closing
+// either count or are refused.
+func (p *PostService) VotePoll(ctx context.Context, userId int32, postId int32, positions []int32) (*PollView, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	_, err := p.getReadablePost(timeoutCtx, userId, postId)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	poll, err := txQuries.GetPollForUpdate(timeoutCtx, postId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPollNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pollClosed(poll, time.Now().UTC()) {
+		return nil, ErrPollClosed
+	}
+	options, err := txQuries.GetPollOptions(timeoutCtx, postId)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePollVote(poll, len(options), positions)
+	if err != nil {
+		return nil, err
+	}
+	inserted, err := txQuries.CreatePollVote(timeoutCtx, posts.CreatePollVoteParams{
+		PostID:    postId,
+		UserID:    userId,
+		Positions: positions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if inserted == 0 {
+		return nil, ErrAlreadyVoted
+	}
+	err = txQuries.UpdatePollOptionVoteCounts(timeoutCtx, posts.UpdatePollOptionVoteCountsParams{
+		PostID:    postId,
+		Delta:     1,
+		Positions: positions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = txQuries.UpdatePollVoterCount(timeoutCtx, posts.UpdatePollVoterCountParams{
+		PostID: postId,
+		Delta:  1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return p.GetPoll(ctx, userId, postId)
+}
+
+func validatePollVote(poll posts.Poll, optionCount int, positions []int32) error {
+	if len(positions) == 0 || (!poll.MultipleChoice && len(positions) != 1) {
+		return ErrInvalidPollVote
+	}
+	seen := make(map[int32]struct{}, len(positions))
+	for _, position := range positions {
+		if position < 0 || int(position) >= optionCount {
+			return ErrInvalidPollVote
+		}
+		if _, ok := seen[position]; ok {
+			return ErrInvalidPollVote
+		}
+		seen[position] = struct{}{}
+	}
+	return nil
+}
+
+// RemoveUserPollVotes drops a deleted user's votes and takes them off the
+// counts, closed polls included.
+func (p *PostService) RemoveUserPollVotes(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	votes, err := txQuries.DeletePollVotesByUser(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	// polls are locked in post id order, and before their options like
+	// VotePoll does, so this cannot deadlock with voters
+	sort.Slice(votes, func(i, j int) bool { return votes[i].PostID < votes[j].PostID })
+	for _, vote := range votes {
+		_, err = txQuries.GetPollForUpdate(timeoutCtx, vote.PostID)
+		if err != nil {
+			return err
+		}
+		err = txQuries.UpdatePollOptionVoteCounts(timeoutCtx, posts.UpdatePollOptionVoteCountsParams{
+			PostID:    vote.PostID,
+			Delta:     -1,
+			Positions: vote.Positions,
+		})
+		if err != nil {
+			return err
+		}
+		err = txQuries.UpdatePollVoterCount(timeoutCtx, posts.UpdatePollVoterCountParams{
+			PostID: vote.PostID,
+			Delta:  -1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// RunPollCloser publishes poll.closed for polls that ended every interval.
+// Every replica runs it, an ended poll is claimed with a row lock that the
+// others skip.
+func (p *PostService) RunPollCloser(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.closeEndedPolls(ctx)
+		}
+	}
+}
+
+func (p *PostService) closeEndedPolls(ctx context.Context) {
+	for i := 0; i < pollCloseBatchSize; i++ {
+		err := p.closeNextEndedPoll(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if err != nil {
+			// the poll stays unclosed and is retried next tick
+			log.Println("close poll:", err)
+			return
+		}
+	}
+}
+
+// closeNextEndedPoll claims the poll that ended first and marks it closed.
+// poll.closed is published before the commit, so a failed commit sends it
+// again next tick rather than never. It returns sql.ErrNoRows when no poll
+// has ended.
+func (p *PostService) closeNextEndedPoll(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	poll, err := txQuries.ClaimDuePoll(timeoutCtx)
+	if err != nil {
+		return err
+	}
+	options, err := txQuries.GetPollOptions(timeoutCtx, poll.PostID)
+	if err != nil {
+		return err
+	}
+	err = txQuries.MarkPollClosed(timeoutCtx, poll.PostID)
+	if err != nil {
+		return err
+	}
+	closedOptions := make([]rabbitmq_producer.PollClosedOption, 0, len(options))
+	for _, option := range options {
+		closedOptions = append(closedOptions, rabbitmq_producer.PollClosedOption{
+			Position:  option.Position,
+			Text:      option.Text,
+			VoteCount: option.VoteCount,
+		})
+	}
+	msg, err := json.Marshal(rabbitmq_producer.PollClosedMsg{
+		PostId:     poll.PostID,
+		AuthorId:   poll.AuthorID,
+		VoterCount: poll.VoterCount,
+		Options:    closedOptions,
+		ClosedAt:   poll.EndsAt,
+	})
+	if err != nil {
+		return err
+	}
+	err = p.rabbitmProducer.Publish("post_events", "poll.closed", msg)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+func TestValidatePoll(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     []string
+		endsIn      time.Duration
+		want        error
+		wantOptions []string
+	}{
+		{name: "two options", options: []string{"yes", "no"}, endsIn: time.Hour, wantOptions: []string{"yes", "no"}},
+		{name: "four options", options: []string{"a", "b", "c", "d"}, endsIn: time.Hour, wantOptions: []string{"a", "b", "c", "d"}},
+		{name: "options trimmed", options: []string{"  tea ", "coffee\n"}, endsIn: time.Hour, wantOptions: []string{"tea", "coffee"}},
+		{name: "one option", options: []string{"only"}, endsIn: time.Hour, want: ErrInvalidPoll},
+		{name: "five options", options: []string{"a", "b", "c", "d", "e"}, endsIn: time.Hour, want: ErrInvalidPoll},
+		{name: "blank option", options: []string{"yes", "   "}, endsIn: time.Hour, want: ErrInvalidPoll},
+		{name: "duplicate in any case", options: []string{"Yes", "yes "}, endsIn: time.Hour, want: ErrInvalidPoll},
+		{name: "option at max length", options: []string{strings.Repeat("é", maxPollOptionLength), "no"}, endsIn: time.Hour, wantOptions: []string{strings.Repeat("é", maxPollOptionLength), "no"}},
+		{name: "option too long", options: []string{strings.Repeat("a", maxPollOptionLength+1), "no"}, endsIn: time.Hour, want: ErrInvalidPoll},
+		{name: "ends too soon", options: []string{"yes", "no"}, endsIn: minPollDuration - time.Minute, want: ErrInvalidPollEndAt},
+		{name: "ends in the past", options: []string{"yes", "no"}, endsIn: -time.Hour, want: ErrInvalidPollEndAt},
+		{name: "ends too late", options: []string{"yes", "no"}, endsIn: maxPollDuration + time.Minute, want: ErrInvalidPollEndAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poll := PollInput{Options: tt.options, EndsAt: time.Now().Add(tt.endsIn)}
+			err := validatePoll(&poll)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("validatePoll(%q) = %v, want %v", tt.options, err, tt.want)
+			}
+			if err == nil && !reflect.DeepEqual(poll.Options, tt.wantOptions) {
+				t.Errorf("validatePoll options = %q, want %q", poll.Options, tt.wantOptions)
+			}
+		})
+	}
+}
+
+func TestValidatePollVote(t *testing.T) {
+	single := posts.Poll{MultipleChoice: false}
+	multiple := posts.Poll{MultipleChoice: true}
+	tests := []struct {
+		name      string
+		poll      posts.Poll
+		positions []int32
+		want      error
+	}{
+		{name: "single choice", poll: single, positions: []int32{0}, want: nil},
+		{name: "single choice last option", poll: single, positions: []int32{2}, want: nil},
+		{name: "single choice two picks", poll: single, positions: []int32{0, 1}, want: ErrInvalidPollVote},
+		{name: "no picks", poll: single, positions: nil, want: ErrInvalidPollVote},
+		{name: "multiple choice", poll: multiple, positions: []int32{0, 2}, want: nil},
+		{name: "multiple choice no picks", poll: multiple, positions: []int32{}, want: ErrInvalidPollVote},
+		{name: "multiple choice repeated pick", poll: multiple, positions: []int32{1, 1}, want: ErrInvalidPollVote},
+		{name: "negative position", poll: single, positions: []int32{-1}, want: ErrInvalidPollVote},
+		{name: "position past options", poll: multiple, positions: []int32{0, 3}, want: ErrInvalidPollVote},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePollVote(tt.poll, 3, tt.positions)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("validatePollVote(%v) = %v, want %v", tt.positions, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVotePoll(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	postId := createTestPostWith(t, postService, CreatePostInput{
+		UserId: 1,
+		Body:   "tea or coffee?",
+		Poll:   &PollInput{Options: []string{"tea", "coffee", "water"}, EndsAt: time.Now().Add(time.Hour)},
+	})
+
+	// users 2 to 21 vote at once, user 2 from several tabs
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	alreadyVoted := 0
+	vote := func(userId int32, position int32) {
+		defer wg.Done()
+		_, err := postService.VotePoll(ctx, userId, postId, []int32{position})
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case errors.Is(err, ErrAlreadyVoted):
+			alreadyVoted++
+		case err != nil:
+			t.Error(err)
+		}
+	}
+	for userId := int32(2); userId <= 21; userId++ {
+		wg.Add(1)
+		go vote(userId, userId%2)
+	}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go vote(2, 0)
+	}
+	wg.Wait()
+	if alreadyVoted != 3 {
+		t.Errorf("%d votes refused as repeated, want 3", alreadyVoted)
+	}
+	counts := func(poll *PollView) []int32 {
+		got := []int32{*poll.VoterCount}
+		for _, option := range poll.Options {
+			got = append(got, *option.VoteCount)
+		}
+		return got
+	}
+	poll, err := postService.GetPoll(ctx, 2, postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{20, 10, 10, 0}; !reflect.DeepEqual(counts(poll), want) {
+		t.Errorf("voters and option counts = %v, want %v", counts(poll), want)
+	}
+	// results stay hidden until the viewer votes
+	poll, err = postService.GetPoll(ctx, 30, postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if poll.VoterCount != nil || poll.Options[0].VoteCount != nil {
+		t.Errorf("poll before voting = %+v, want no counts", poll)
+	}
+
+	err = postService.RemoveUserPollVotes(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = postService.postDb.ExecContext(ctx, `UPDATE polls SET ends_at = NOW() - interval '1 minute' WHERE post_id = $1`, postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = postService.VotePoll(ctx, 30, postId, []int32{2})
+	if !errors.Is(err, ErrPollClosed) {
+		t.Errorf("voting after the end = %v, want %v", err, ErrPollClosed)
+	}
+	postService.closeEndedPolls(ctx)
+	postService.closeEndedPolls(ctx)
+	poll, err = postService.GetPoll(ctx, 30, postId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int32{19, 9, 10, 0}; !poll.Closed || !reflect.DeepEqual(counts(poll), want) {
+		t.Errorf("closed %v with counts %v, want %v", poll.Closed, counts(poll), want)
+	}
+	if got := producer.Messages("poll.closed"); len(got) != 1 {
+		t.Errorf("published poll.closed %d times, want once", len(got))
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"encoding/json"
+)
+
+type UserActiveMsg struct {
+	UserId int32 `json:"userId"`
+}
+
+// ReportActivity publishes user.active for the user service, which owns
+// presence and throttles the heartbeats.
+func (p *PostService) ReportActivity(userId int32) error {
+	if userId <= 0 {
+		return nil
+	}
+	msg, err := json.Marshal(UserActiveMsg{UserId: userId})
+	if err != nil {
+		return err
+	}
+	return p.rabbitmProducer.Publish("user_events", "user.active", msg)
+}
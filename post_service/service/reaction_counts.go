@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	reactionCountsTTL       = 24 * time.Hour
+	reactionDirtySetKey     = "reactions:dirty"
+	reactionReconcileBatch  = 500
+	reactionCountsLoadedKey = "_"
+)
+
+// incrementIfCached only bumps a counter when the hash is already loaded, a
+// partial hash would otherwise be read as the full set of counts.
+var incrementIfCached = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("HINCRBY", KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// reactionCounter keeps per post reaction counts in redis hashes for the read
+// heavy feed. Writes mark posts dirty and reconcile recounts them from the
+// reactions table into post_reaction_counts, which is also what cold hashes
+// are loaded from.
+type reactionCounter struct {
+	db          *sql.DB
+	queries     *posts.Queries
+	redisClient *redis.Client
+}
+
+func newReactionCounter(db *sql.DB, queries *posts.Queries, redisClient *redis.Client) *reactionCounter {
+	return &reactionCounter{
+		db:          db,
+		queries:     queries,
+		redisClient: redisClient,
+	}
+}
+
+func reactionCountsKey(postId int32) string {
+	return fmt.Sprintf("post:%d:reactions", postId)
+}
+
+func (c *reactionCounter) increment(ctx context.Context, postId int32, reaction string, delta int64) {
+	err := incrementIfCached.Run(ctx, c.redisClient, []string{reactionCountsKey(postId)}, reaction, delta).Err()
+	if err != nil {
+		log.Println("increment reaction count:", err)
+	}
+	err = c.redisClient.SAdd(ctx, reactionDirtySetKey, postId).Err()
+	if err != nil {
+		log.Println("mark reaction count dirty:", err)
+	}
+}
+
+// get returns the counts for every post id, loading cold hashes from
+// postgres. Redis errors fall back to postgres for all of them.
+func (c *reactionCounter) get(ctx context.Context, postIds []int32) (map[int32]map[string]int64, error) {
+	counts := make(map[int32]map[string]int64, len(postIds))
+	if len(postIds) == 0 {
+		return counts, nil
+	}
+	pipe := c.redisClient.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(postIds))
+	for i, postId := range postIds {
+		cmds[i] = pipe.HGetAll(ctx, reactionCountsKey(postId))
+	}
+	misses := postIds
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Println("get reaction counts:", err)
+	} else {
+		misses = nil
+		for i, cmd := range cmds {
+			fields := cmd.Val()
+			if len(fields) == 0 {
+				misses = append(misses, postIds[i])
+				continue
+			}
+			counts[postIds[i]] = parseReactionCounts(fields)
+		}
+	}
+	if len(misses) == 0 {
+		return counts, nil
+	}
+	loaded, err := c.load(ctx, misses)
+	if err != nil {
+		return nil, err
+	}
+	for postId, postCounts := range loaded {
+		counts[postId] = postCounts
+	}
+	c.cache(ctx, loaded)
+	return counts, nil
+}
+
+func (c *reactionCounter) load(ctx context.Context, postIds []int32) (map[int32]map[string]int64, error) {
+	rows, err := c.queries.GetReactionCountsByPostIds(ctx, postIds)
+	if err != nil {
+		return nil, err
+	}
+	loaded := make(map[int32]map[string]int64, len(postIds))
+	for _, postId := range postIds {
+		loaded[postId] = map[string]int64{}
+	}
+	for _, row := range rows {
+		loaded[row.PostID][row.Reaction] = int64(row.Count)
+	}
+	return loaded, nil
+}
+
+// cache replaces the hashes with the given counts. Every hash carries a
+// marker field so posts without reactions are cached too.
+func (c *reactionCounter) cache(ctx context.Context, counts map[int32]map[string]int64) {
+	pipe := c.redisClient.TxPipeline()
+	for postId, postCounts := range counts {
+		key := reactionCountsKey(postId)
+		fields := map[string]interface{}{reactionCountsLoadedKey: 0}
+		for reaction, count := range postCounts {
+			fields[reaction] = count
+		}
+		pipe.Del(ctx, key)
+		pipe.HSet(ctx, key, fields)
+		pipe.Expire(ctx, key, reactionCountsTTL)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Println("cache reaction counts:", err)
+	}
+}
+
+// reconcile recounts one batch of dirty posts into postgres and overwrites
+// their redis hashes. Posts that fail are marked dirty again.
+func (c *reactionCounter) reconcile(ctx context.Context) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	members, err := c.redisClient.SPopN(timeoutCtx, reactionDirtySetKey, reactionReconcileBatch).Result()
+	if err != nil {
+		log.Println("reconcile reaction counts:", err)
+		return
+	}
+	if len(members) == 0 {
+		return
+	}
+	postIds := make([]int32, 0, len(members))
+	for _, member := range members {
+		postId, err := strconv.Atoi(member)
+		if err != nil {
+			continue
+		}
+		postIds = append(postIds, int32(postId))
+	}
+	err = c.recount(timeoutCtx, postIds)
+	if err != nil {
+		log.Println("reconcile reaction counts:", err)
+		err = c.redisClient.SAdd(ctx, reactionDirtySetKey, members).Err()
+		if err != nil {
+			log.Println("mark reaction count dirty:", err)
+		}
+		return
+	}
+	loaded, err := c.load(timeoutCtx, postIds)
+	if err != nil {
+		log.Println("reconcile reaction counts:", err)
+		return
+	}
+	c.cache(timeoutCtx, loaded)
+}
+
+func (c *reactionCounter) recount(ctx context.Context, postIds []int32) error {
+	tx, err := c.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := c.queries.WithTx(tx)
+
+	err = txQuries.DeletePostReactionCounts(ctx, postIds)
+	if err != nil {
+		return err
+	}
+	err = txQuries.RecountPostReactions(ctx, postIds)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func parseReactionCounts(fields map[string]string) map[string]int64 {
+	counts := make(map[string]int64, len(fields))
+	for reaction, value := range fields {
+		if reaction == reactionCountsLoadedKey {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || count <= 0 {
+			continue
+		}
+		counts[reaction] = count
+	}
+	return counts
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"github.com/lib/pq"
+)
+
+var reactionTypes = map[string]struct{}{
+	"like":  {},
+	"love":  {},
+	"laugh": {},
+	"wow":   {},
+	"sad":   {},
+	"angry": {},
+}
+
+var ErrInvalidReaction = errors.New("reaction must be like, love, laugh, wow, sad or angry")
+
+// React adds the reaction from userId, reacting twice with the same type is
+// a no-op. Counters are bumped in redis and reconciled to postgres later.
+func (p *PostService) React(ctx context.Context, userId int32, postId int32, reaction string) error {
+	if _, ok := reactionTypes[reaction]; !ok {
+		return ErrInvalidReaction
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	_, err := p.getReadablePost(timeoutCtx, userId, postId)
+	if err != nil {
+		return err
+	}
+	rows, err := p.postQuries.CreateReaction(timeoutCtx, posts.CreateReactionParams{
+		PostID:   postId,
+		UserID:   userId,
+		Reaction: reaction,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrPostNotFound
+		}
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	p.reactionCounts.increment(timeoutCtx, postId, reaction, 1)
+	return nil
+}
+
+func (p *PostService) Unreact(ctx context.Context, userId int32, postId int32, reaction string) error {
+	if _, ok := reactionTypes[reaction]; !ok {
+		return ErrInvalidReaction
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	rows, err := p.postQuries.DeleteReaction(timeoutCtx, posts.DeleteReactionParams{
+		PostID:   postId,
+		UserID:   userId,
+		Reaction: reaction,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	p.reactionCounts.increment(timeoutCtx, postId, reaction, -1)
+	return nil
+}
+
+// GetReactors lists who reacted to the post, newest first, optionally only
+// for one reaction type.
+func (p *PostService) GetReactors(ctx context.Context, viewerId int32, postId int32, reaction string, page ReactorPageReq) (*ReactorPageResp, error) {
+	if _, ok := reactionTypes[reaction]; reaction != "" && !ok {
+		return nil, ErrInvalidReaction
+	}
+	cursor, err := decodeReactorCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	_, err = p.getReadablePost(timeoutCtx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.postQuries.GetReactors(timeoutCtx, posts.GetReactorsParams{
+		PostID:          postId,
+		Reaction:        reaction,
+		BeforeCreatedAt: cursor.CreatedAt,
+		BeforeUserID:    cursor.UserId,
+		PageLimit:       page.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReactorPageResp{
+		Reactors: rows,
+	}
+	if len(rows) > int(page.Limit) {
+		resp.Reactors = rows[:page.Limit]
+		last := resp.Reactors[len(resp.Reactors)-1]
+		resp.NextCursor = encodeReactorCursor(reactorCursor{CreatedAt: last.CreatedAt, UserId: last.UserID})
+	}
+	if resp.Reactors == nil {
+		resp.Reactors = []posts.GetReactorsRow{}
+	}
+	return resp, nil
+}
+
+// toPostViews builds the views for the posts and embeds the originals of
+// reposts and quotes one level deep.
+func (p *PostService) toPostViews(ctx context.Context, viewerId int32, rows []posts.Post) ([]PostView, error) {
+	views, err := p.buildPostViews(ctx, viewerId, rows)
+	if err != nil {
+		return nil, err
+	}
+	originalIds := make([]int32, 0)
+	for _, view := range views {
+		if view.Kind != PostKindPost && view.OriginalPostID.Valid {
+			originalIds = append(originalIds, view.OriginalPostID.Int32)
+		}
+	}
+	originals := make(map[int32]*PostView)
+	if len(originalIds) > 0 {
+		originalRows, err := p.postQuries.GetPostsByIds(ctx, originalIds)
+		if err != nil {
+			return nil, err
+		}
+		originalRows, err = p.visiblePosts(ctx, viewerId, originalRows)
+		if err != nil {
+			return nil, err
+		}
+		originalViews, err := p.buildPostViews(ctx, viewerId, originalRows)
+		if err != nil {
+			return nil, err
+		}
+		for i := range originalViews {
+			originals[originalViews[i].ID] = &originalViews[i]
+		}
+	}
+	for i := range views {
+		if views[i].Kind == PostKindPost {
+			continue
+		}
+		original, ok := originals[views[i].OriginalPostID.Int32]
+		if !views[i].OriginalPostID.Valid || !ok {
+			views[i].Tombstone = true
+			continue
+		}
+		views[i].Original = original
+	}
+	return views, nil
+}
+
+// buildPostViews attaches reaction counts, mentions, attachments, polls, and
+// the viewer's own reactions when viewerId is set, to the posts.
+func (p *PostService) buildPostViews(ctx context.Context, viewerId int32, rows []posts.Post) ([]PostView, error) {
+	postIds := make([]int32, 0, len(rows))
+	for _, post := range rows {
+		postIds = append(postIds, post.ID)
+	}
+	counts, err := p.reactionCounts.get(ctx, postIds)
+	if err != nil {
+		return nil, err
+	}
+	viewerReactions := make(map[int32][]string)
+	if viewerId > 0 && len(postIds) > 0 {
+		reacted, err := p.postQuries.GetViewerReactions(ctx, posts.GetViewerReactionsParams{
+			UserID:  viewerId,
+			PostIds: postIds,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range reacted {
+			viewerReactions[row.PostID] = append(viewerReactions[row.PostID], row.Reaction)
+		}
+	}
+	mentions := make(map[int32][]posts.PostMention)
+	if len(postIds) > 0 {
+		mentionRows, err := p.postQuries.GetMentionsByPostIds(ctx, postIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, mention := range mentionRows {
+			mentions[mention.PostID] = append(mentions[mention.PostID], mention)
+		}
+	}
+	attachments := make(map[int32][]posts.PostMedium)
+	if len(postIds) > 0 {
+		mediaRows, err := p.postQuries.GetPostMediaByPostIds(ctx, postIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, media := range mediaRows {
+			attachments[media.PostID] = append(attachments[media.PostID], media)
+		}
+	}
+	polls, err := p.loadPolls(ctx, viewerId, postIds)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]PostView, 0, len(rows))
+	for _, post := range rows {
+		view := PostView{
+			Post:            post,
+			Reactions:       counts[post.ID],
+			ViewerReactions: viewerReactions[post.ID],
+			Mentions:        mentions[post.ID],
+			Attachments:     attachments[post.ID],
+			Poll:            polls[post.ID],
+		}
+		if view.Reactions == nil {
+			view.Reactions = map[string]int64{}
+		}
+		if view.ViewerReactions == nil {
+			view.ViewerReactions = []string{}
+		}
+		if view.Mentions == nil {
+			view.Mentions = []posts.PostMention{}
+		}
+		if view.Attachments == nil {
+			view.Attachments = []posts.PostMedium{}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// RunReactionReconcileJob recounts reactions for posts that changed since the
+// last run until ctx is cancelled.
+func (p *PostService) RunReactionReconcileJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.reactionCounts.reconcile(ctx)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+const (
+	ScheduledStatusScheduled = "scheduled"
+	ScheduledStatusPublished = "published"
+	ScheduledStatusFailed    = "failed"
+
+	maxPendingScheduledPosts = 100
+	maxScheduleAhead         = 365 * 24 * time.Hour
+	// a post that fails to publish this many times is marked failed until
+	// the author reschedules it
+	maxPublishAttempts = 5
+	// publishBatchSize caps how many due posts one tick publishes
+	publishBatchSize = 50
+)
+
+// errScheduledPostTaken is returned when a due post was claimed by another
+// replica between being found and being locked
+var errScheduledPostTaken = errors.New("scheduled post claimed by another publisher")
+
+var (
+	ErrScheduledPostNotFound = errors.New("scheduled post not found")
+	ErrInvalidPublishAt      = errors.New("publish time must be in the future and at most a year ahead")
+	ErrTooManyScheduledPosts = fmt.Errorf("at most %d posts can be scheduled at once", maxPendingScheduledPosts)
+)
+
+func validatePublishAt(publishAt time.Time) error {
+	now := time.Now()
+	if !publishAt.After(now) || publishAt.After(now.Add(maxScheduleAhead)) {
+		return ErrInvalidPublishAt
+	}
+	return nil
+}
+
+// SchedulePost uploads the attachments now, served only to the author, and
+// stores the post until its publish time.
+func (p *PostService) SchedulePost(ctx context.Context, input SchedulePostInput) (*posts.ScheduledPost, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, errors.New("post body is required")
+	}
+	visibility, err := normalizeVisibility(input.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	err = validatePublishAt(input.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+	err = validateAttachments(input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	countCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	count, err := p.postQuries.CountPendingScheduledPosts(countCtx, input.UserId)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPendingScheduledPosts {
+		return nil, ErrTooManyScheduledPosts
+	}
+	mediaIds, err := p.uploadAttachments(input.UserId, input.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	// only the author sees the media until the post goes live
+	err = p.hideMedia(ctx, mediaIds)
+	if err != nil {
+		p.deleteMedia(mediaIds)
+		return nil, err
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	scheduled, err := p.postQuries.CreateScheduledPost(timeoutCtx, posts.CreateScheduledPostParams{
+		UserID:     input.UserId,
+		Username:   input.Username,
+		Body:       body,
+		Visibility: visibility,
+		MediaIds:   mediaIds,
+		AltTexts:   attachmentAltTexts(input.Attachments),
+		PublishAt:  input.PublishAt.UTC(),
+	})
+	if err != nil {
+		p.deleteMedia(mediaIds)
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// GetScheduledPosts lists the user's posts still waiting to be published,
+// and the ones that failed to, soonest first.
+func (p *PostService) GetScheduledPosts(ctx context.Context, userId int32) ([]posts.ScheduledPost, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	scheduled, err := p.postQuries.GetPendingScheduledPosts(timeoutCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled == nil {
+		scheduled = []posts.ScheduledPost{}
+	}
+	return scheduled, nil
+}
+
+// ReschedulePost moves a pending or failed post to a new publish time.
+func (p *PostService) ReschedulePost(ctx context.Context, userId int32, scheduledPostId int32, publishAt time.Time) (*posts.ScheduledPost, error) {
+	err := validatePublishAt(publishAt)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	scheduled, err := p.postQuries.ReschedulePost(timeoutCtx, posts.ReschedulePostParams{
+		ID:        scheduledPostId,
+		UserID:    userId,
+		PublishAt: publishAt.UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrScheduledPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+// CancelScheduledPost drops a post that was not published yet along with its
+// attachments.
+func (p *PostService) CancelScheduledPost(ctx context.Context, userId int32, scheduledPostId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	mediaIds, err := p.postQuries.DeleteScheduledPost(timeoutCtx, posts.DeleteScheduledPostParams{
+		ID:     scheduledPostId,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrScheduledPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	p.deleteMedia(mediaIds)
+	return nil
+}
+
+// RemoveUserScheduledPosts drops a deleted user's scheduled posts so none of
+// them goes live, and the attachments of the ones not published.
+func (p *PostService) RemoveUserScheduledPosts(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	rows, err := p.postQuries.DeleteScheduledPostsByUser(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if row.Status != ScheduledStatusPublished {
+			p.deleteMedia(row.MediaIds)
+		}
+	}
+	return nil
+}
+
+// RunScheduledPostPublisher publishes due posts every interval. Every replica
+// runs it, a due post is claimed with a row lock that the others skip.
+func (p *PostService) RunScheduledPostPublisher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.publishDuePosts(ctx)
+		}
+	}
+}
+
+func (p *PostService) publishDuePosts(ctx context.Context) {
+	// posts that failed this tick are skipped until the next one
+	skipIds := make([]int32, 0)
+	for i := 0; i < publishBatchSize; i++ {
+		scheduled, err := p.publishNextDuePost(ctx, skipIds)
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		if errors.Is(err, errScheduledPostTaken) {
+			skipIds = append(skipIds, scheduled.ID)
+			continue
+		}
+		if err == nil {
+			continue
+		}
+		log.Println("publish scheduled post:", err)
+		if scheduled.ID == 0 {
+			return
+		}
+		skipIds = append(skipIds, scheduled.ID)
+		err = p.postQuries.RecordScheduledPostFailure(ctx, posts.RecordScheduledPostFailureParams{
+			ID:          scheduled.ID,
+			LastError:   err.Error(),
+			MaxAttempts: maxPublishAttempts,
+		})
+		if err != nil {
+			log.Println("record scheduled post failure:", err)
+		}
+	}
+}
+
+// publishNextDuePost claims the oldest due post and creates the post in the
+// same transaction, so it goes live exactly once however many replicas run
+// the publisher. Mentions are resolved with the user service before the
+// transaction opens, a scheduled post's body cannot change. It returns
+// sql.ErrNoRows when nothing is due and errScheduledPostTaken when another
+// replica claimed the post first.
+func (p *PostService) publishNextDuePost(ctx context.Context, skipIds []int32) (posts.ScheduledPost, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	due, err := p.postQuries.GetNextDueScheduledPost(timeoutCtx, skipIds)
+	if err != nil {
+		return posts.ScheduledPost{}, err
+	}
+	mentions := p.resolveMentions(timeoutCtx, due.UserID, due.Body)
+	// already done when it was scheduled, repeated for posts scheduled
+	// before media was restricted up front
+	err = p.restrictMedia(timeoutCtx, due.Visibility, due.MediaIds)
+	if err != nil {
+		return due, err
+	}
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return posts.ScheduledPost{}, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	scheduled, err := txQuries.ClaimDueScheduledPost(timeoutCtx, due.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return due, errScheduledPostTaken
+	}
+	if err != nil {
+		return posts.ScheduledPost{}, err
+	}
+	post := newPost{
+		UserId:     scheduled.UserID,
+		Username:   scheduled.Username,
+		Body:       scheduled.Body,
+		Visibility: scheduled.Visibility,
+		MediaIds:   scheduled.MediaIds,
+		AltTexts:   scheduled.AltTexts,
+		Mentions:   mentions,
+	}
+	postId, tags, err := insertPost(timeoutCtx, txQuries, post)
+	if err != nil {
+		return scheduled, err
+	}
+	err = txQuries.MarkScheduledPostPublished(timeoutCtx, posts.MarkScheduledPostPublishedParams{
+		ID:     scheduled.ID,
+		PostID: sql.NullInt32{Int32: postId, Valid: true},
+	})
+	if err != nil {
+		return scheduled, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return scheduled, err
+	}
+	p.revealMedia(ctx, post.Visibility, post.MediaIds)
+	p.announcePost(timeoutCtx, postId, post, tags)
+	return scheduled, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestValidatePublishAt(t *testing.T) {
+	tests := []struct {
+		name      string
+		publishIn time.Duration
+		want      error
+	}{
+		{name: "in a minute", publishIn: time.Minute, want: nil},
+		{name: "in a month", publishIn: 30 * 24 * time.Hour, want: nil},
+		{name: "just under a year", publishIn: maxScheduleAhead - time.Minute, want: nil},
+		{name: "in the past", publishIn: -time.Minute, want: ErrInvalidPublishAt},
+		{name: "more than a year ahead", publishIn: maxScheduleAhead + time.Minute, want: ErrInvalidPublishAt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePublishAt(time.Now().Add(tt.publishIn))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("validatePublishAt(now%+v) = %v, want %v", tt.publishIn, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPublishDuePosts(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+
+	for i := 0; i < 6; i++ {
+		_, err := postService.SchedulePost(ctx, SchedulePostInput{
+			UserId:    1,
+			Username:  "user1",
+			Body:      fmt.Sprintf("scheduled %d", i),
+			PublishAt: time.Now().Add(time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// all but the last one fall due
+	_, err := postService.postDb.ExecContext(ctx, `UPDATE scheduled_posts SET publish_at = NOW() - interval '1 minute'
+		WHERE id <> (SELECT max(id) FROM scheduled_posts)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replicas publishing at the same time claim each due post once
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			postService.publishDuePosts(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var postCount, distinctCount int
+	err = postService.postDb.QueryRowContext(ctx, `SELECT count(*), count(DISTINCT body) FROM posts WHERE user_id = 1`).Scan(&postCount, &distinctCount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postCount != 5 || distinctCount != 5 {
+		t.Errorf("published %d posts with %d bodies, want 5 of each", postCount, distinctCount)
+	}
+	pending, err := postService.GetScheduledPosts(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Status != ScheduledStatusScheduled || pending[0].Body != "scheduled 5" {
+		t.Errorf("pending after publishing = %+v, want only the post not due", pending)
+	}
+}
+
+func TestScheduledMediaHiddenUntilPublished(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	fake := SetupRpcServer(t, postService)
+
+	for i, visibility := range []string{VisibilityPublic, VisibilityFollowers} {
+		_, err := postService.SchedulePost(ctx, SchedulePostInput{
+			UserId:      1,
+			Username:    "user1",
+			Body:        "later",
+			Visibility:  visibility,
+			PublishAt:   time.Now().Add(time.Hour),
+			Attachments: []AttachmentUpload{testAttachment(int32(i+7), "image/png", "")},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := fake.Restricted(); !reflect.DeepEqual(got, []int32{7, 8}) {
+		t.Errorf("restricted media %v, want both scheduled posts' [7 8]", got)
+	}
+	_, err := postService.postDb.ExecContext(ctx, `UPDATE scheduled_posts SET publish_at = NOW() - interval '1 minute'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	postService.publishDuePosts(ctx)
+
+	// only the public post's media is served to everyone once it is live
+	if got := fake.Unrestricted(); !reflect.DeepEqual(got, []int32{7}) {
+		t.Errorf("unrestricted media %v, want [7]", got)
+	}
+}
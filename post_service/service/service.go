@@ -3,10 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
 	"time"
 
 	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
@@ -20,11 +17,17 @@ type PostService struct {
 	postQuries      *posts.Queries
 	redisClient     *redis.Client
 	rpcClient       *rpc_client.RpcClient
-	rabbitmProducer *rabbitmq_producer.RabbitMQProducer
+	rabbitmProducer rabbitmq_producer.RabbitMQProducerInterface
+	reactionCounts  *reactionCounter
 	config          *PostServiceConfig
 }
 type PostServiceConfig struct {
 	MinioBucketName string
+	// EditWindow is how long after creation a post can still be edited
+	EditWindow time.Duration
+	// DraftRetention is how long a draft is kept after its last save, 0 keeps
+	// drafts forever
+	DraftRetention time.Duration
 }
 
 func New(db *sql.DB, rdb *redis.Client, rpcClient *rpc_client.RpcClient, rabbitmqProducer *rabbitmq_producer.RabbitMQProducer, config PostServiceConfig) (*PostService, error) {
@@ -35,23 +38,34 @@ func New(db *sql.DB, rdb *redis.Client, rpcClient *rpc_client.RpcClient, rabbitm
 		redisClient:     rdb,
 		rpcClient:       rpcClient,
 		rabbitmProducer: rabbitmqProducer,
+		reactionCounts:  newReactionCounter(db, dbQuries, rdb),
 		config:          &config,
 	}, nil
 }
 
-func (p *PostService) GetAllPosts(ctx context.Context) ([]posts.Post, error) {
+func (p *PostService) GetAllPosts(ctx context.Context, viewerId int32) ([]PostView, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
 	defer cancel()
 
-	postsCh := make(chan []posts.Post)
+	postsCh := make(chan []PostView)
 	errCh := make(chan error)
 	go func() {
-		posts, err := p.postQuries.GetAll(timeoutCtx)
+		rows, err := p.postQuries.GetAll(timeoutCtx)
 		if err != nil {
 			errCh <- err
 			return
 		}
-		postsCh <- posts
+		rows, err = p.visiblePosts(timeoutCtx, viewerId, rows)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		views, err := p.toPostViews(timeoutCtx, viewerId, rows)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		postsCh <- views
 	}()
 	select {
 	case posts := <-postsCh:
@@ -83,26 +97,50 @@ func (p *PostService) DeletePost(ctx context.Context, postId int32, userId int32
 			errCh <- errors.New("unathorized")
 			return
 		}
-		err = p.postQuries.DeletePost(timeoutCtx, posts.DeletePostParams{
-			ID:     postId,
-			UserID: userId,
-		})
+		attachmentIds, err := p.postQuries.GetPostMediaIds(timeoutCtx, postId)
 		if err != nil {
 			errCh <- err
 			return
 		}
-		msg, err := json.Marshal(MediaDeletedMsg{
-			MediaId: post.MediaID.Int32,
-		})
+		// revisions go with the post, so does any media only they reference
+		revisionMediaIds, err := p.postQuries.GetPostRevisionMediaIds(timeoutCtx, postId)
 		if err != nil {
 			errCh <- err
 			return
 		}
-		err = p.rabbitmProducer.Publish("media_events", "media.deleted", msg)
+		err = p.postQuries.DeletePost(timeoutCtx, posts.DeletePostParams{
+			ID:     postId,
+			UserID: userId,
+		})
 		if err != nil {
 			errCh <- err
 			return
 		}
+		if post.OriginalPostID.Valid {
+			counts := posts.UpdatePostShareCountsParams{ID: post.OriginalPostID.Int32}
+			if post.Kind == PostKindRepost {
+				counts.RepostDelta = -1
+			} else {
+				counts.QuoteDelta = -1
+			}
+			err = p.postQuries.UpdatePostShareCounts(timeoutCtx, counts)
+			if err != nil {
+				errCh <- err
+				return
+			}
+		}
+		seen := make(map[int32]struct{})
+		for _, mediaId := range append(append(attachmentIds, revisionMediaIds...), post.MediaID.Int32) {
+			if _, ok := seen[mediaId]; ok || mediaId <= 0 {
+				continue
+			}
+			seen[mediaId] = struct{}{}
+			err = p.publishMediaDeleted(mediaId)
+			if err != nil {
+				errCh <- err
+				return
+			}
+		}
 		successCh <- true
 	}()
 	select {
@@ -118,31 +156,45 @@ func (p *PostService) DeletePost(ctx context.Context, postId int32, userId int32
 type PostPageReq struct {
 	PageNo   int32 `json:"pageNo"`
 	PageSize int32 `json:"pageSize"`
+	// After and Before are the opaque cursors returned as nextCursor and
+	// prevCursor, at most one of them is set
+	After  string `json:"after"`
+	Before string `json:"before"`
+	Limit  int32  `json:"limit"`
 }
 type PostPageResp struct {
-	Posts      []posts.Post `json:"posts"`
-	IsLastPage bool         `json:"isLastPage"`
+	Posts      []PostView `json:"posts"`
+	IsLastPage bool       `json:"isLastPage"`
+	NextCursor string     `json:"nextCursor,omitempty"`
+	PrevCursor string     `json:"prevCursor,omitempty"`
 }
 
-func (p *PostService) GetUserPostsPaginated(ctx context.Context, userId int32, page PostPageReq) (*PostPageResp, error) {
+func (p *PostService) GetUserPostsPaginated(ctx context.Context, viewerId int32, userId int32, page PostPageReq) (*PostPageResp, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
 	defer cancel()
 
-	postsCh := make(chan []posts.Post)
+	postsCh := make(chan []PostView)
 	errCh := make(chan error)
 	offset, limit := calculateOffsetAndLimit(page.PageNo, page.PageSize)
 	go func() {
 
 		posts, err := p.postQuries.GetPostPage(timeoutCtx, posts.GetPostPageParams{
-			UserID: userId,
-			Limit:  limit,
-			Offset: offset,
+			UserID:        userId,
+			Limit:         limit,
+			Offset:        offset,
+			ViewerID:      viewerId,
+			ViewerFollows: p.viewerFollows(timeoutCtx, viewerId, userId),
 		})
 		if err != nil {
 			errCh <- err
 			return
 		}
-		postsCh <- posts
+		views, err := p.toPostViews(timeoutCtx, viewerId, posts)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		postsCh <- views
 	}()
 	select {
 	case posts := <-postsCh:
@@ -158,50 +210,151 @@ func (p *PostService) GetUserPostsPaginated(ctx context.Context, userId int32, p
 	}
 
 }
+
+// GetUserPostsByCursor pages the user's posts newest first on (created_at, id)
+// so new posts do not shift later pages. After pages towards older posts and
+// Before back towards newer ones.
+func (p *PostService) GetUserPostsByCursor(ctx context.Context, viewerId int32, userId int32, page PostPageReq) (*PostPageResp, error) {
+	if page.After != "" && page.Before != "" {
+		return nil, errors.New("only one of after and before can be set")
+	}
+	viewerFollows := p.viewerFollows(ctx, viewerId, userId)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	var rows []posts.Post
+	var hasMore bool
+	if page.Before != "" {
+		cursor, err := decodePostPageCursor(page.Before)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = p.postQuries.GetPostPageBefore(timeoutCtx, posts.GetPostPageBeforeParams{
+			UserID:          userId,
+			Limit:           page.Limit + 1,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.Id,
+			ViewerID:        viewerId,
+			ViewerFollows:   viewerFollows,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasMore = len(rows) > int(page.Limit)
+		if hasMore {
+			rows = rows[:page.Limit]
+		}
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	} else {
+		cursor, err := decodePostPageCursor(page.After)
+		if err != nil {
+			return nil, err
+		}
+		rows, err = p.postQuries.GetPostPageAfter(timeoutCtx, posts.GetPostPageAfterParams{
+			UserID:          userId,
+			Limit:           page.Limit + 1,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.Id,
+			ViewerID:        viewerId,
+			ViewerFollows:   viewerFollows,
+		})
+		if err != nil {
+			return nil, err
+		}
+		hasMore = len(rows) > int(page.Limit)
+		if hasMore {
+			rows = rows[:page.Limit]
+		}
+	}
+	views, err := p.toPostViews(timeoutCtx, viewerId, rows)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PostPageResp{Posts: views}
+	if len(rows) == 0 {
+		resp.IsLastPage = page.Before == ""
+		return resp, nil
+	}
+	first, last := rows[0], rows[len(rows)-1]
+	// paging back from a Before page always has older posts behind it, and
+	// paging on from an After page always has newer ones
+	if page.Before != "" || hasMore {
+		resp.NextCursor = encodePostPageCursor(last.CreatedAt, last.ID)
+	}
+	if page.After != "" || (page.Before != "" && hasMore) {
+		resp.PrevCursor = encodePostPageCursor(first.CreatedAt, first.ID)
+	}
+	resp.IsLastPage = resp.NextCursor == ""
+	return resp, nil
+}
+
 func (p *PostService) CreatePost(ctx context.Context, input CreatePostInput) error {
+	visibility, err := normalizeVisibility(input.Visibility)
+	if err != nil {
+		return err
+	}
+	err = validateAttachments(input.Attachments)
+	if err != nil {
+		return err
+	}
+	if input.Poll != nil {
+		err = validatePoll(input.Poll)
+		if err != nil {
+			return err
+		}
+	}
+	mediaIds, err := p.uploadAttachments(input.UserId, input.Attachments)
+	if err != nil {
+		return err
+	}
+	err = p.restrictMedia(ctx, visibility, mediaIds)
+	if err != nil {
+		p.deleteMedia(mediaIds)
+		return err
+	}
+	post := newPost{
+		UserId:     input.UserId,
+		Username:   input.Username,
+		Body:       input.Body,
+		Visibility: visibility,
+		MediaIds:   mediaIds,
+		AltTexts:   attachmentAltTexts(input.Attachments),
+		Mentions:   p.resolveMentions(ctx, input.UserId, input.Body),
+		Poll:       input.Poll,
+	}
+
 	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
 	defer cancel()
 
-	successCh := make(chan struct{})
-	errCh := make(chan error)
+	// buffered so a late failure after the timeout still cleans up the media
+	successCh := make(chan struct{}, 1)
+	errCh := make(chan error, 1)
 	go func() {
-		var mediaId int32
-		if input.MediaSize > 0 {
-			startTime := time.Now().UnixMilli()
-			mediaBytes, err := io.ReadAll(input.Media)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			rpcUpload := &rpc_client.RpcImageUpload{
-				ImageData:   mediaBytes,
-				UserId:      input.UserId,
-				ContentType: input.MediaType,
-				Size:        input.MediaSize,
-			}
+		fail := func(err error) {
+			p.deleteMedia(mediaIds)
+			errCh <- err
+		}
+		tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+		if err != nil {
+			fail(err)
+			return
+		}
+		defer tx.Rollback()
+		txQuries := p.postQuries.WithTx(tx)
 
-			respId, err := p.rpcClient.UploadMedia(rpcUpload)
-			if err != nil {
-				errCh <- err
-				return
-			}
-			endTime := time.Now().UnixMilli()
-			fmt.Println("upload to media service runtime: ", endTime-startTime)
-			mediaId = respId
-		}
-		err := p.postQuries.CreatePost(timeoutCtx, posts.CreatePostParams{
-			UserID:   input.UserId,
-			Username: input.Username,
-			Body:     input.Body,
-			MediaID: sql.NullInt32{
-				Int32: mediaId,
-				Valid: mediaId > 0,
-			},
-		})
+		postId, tags, err := insertPost(timeoutCtx, txQuries, post)
 		if err != nil {
-			errCh <- err
+			fail(err)
 			return
 		}
+		err = tx.Commit()
+		if err != nil {
+			fail(err)
+			return
+		}
+		p.announcePost(timeoutCtx, postId, post, tags)
 		successCh <- struct{}{}
 	}()
 	select {
@@ -213,3 +366,72 @@ func (p *PostService) CreatePost(ctx context.Context, input CreatePostInput) err
 		return timeoutCtx.Err()
 	}
 }
+
+// newPost is a post ready to be written, its attachments already uploaded
+// and its mentions resolved.
+type newPost struct {
+	UserId     int32
+	Username   string
+	Body       string
+	Visibility string
+	MediaIds   []int32
+	AltTexts   []string
+	Mentions   []posts.PostMention
+	Poll       *PollInput
+}
+
+// insertPost writes the post with its attachments, hashtags and mentions and
+// returns its id and hashtags.
+func insertPost(ctx context.Context, queries *posts.Queries, post newPost) (int32, []string, error) {
+	postId, err := queries.CreatePost(ctx, posts.CreatePostParams{
+		UserID:     post.UserId,
+		Username:   post.Username,
+		Body:       post.Body,
+		MediaID:    firstMediaId(post.MediaIds),
+		Visibility: post.Visibility,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(post.MediaIds) > 0 {
+		err = queries.CreatePostMedia(ctx, posts.CreatePostMediaParams{
+			PostID:   postId,
+			MediaIds: post.MediaIds,
+			AltTexts: post.AltTexts,
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	tags := extractHashtags(post.Body)
+	err = setPostHashtags(ctx, queries, postId, tags)
+	if err != nil {
+		return 0, nil, err
+	}
+	err = setPostMentions(ctx, queries, postId, post.Mentions)
+	if err != nil {
+		return 0, nil, err
+	}
+	if post.Poll != nil {
+		err = createPoll(ctx, queries, postId, *post.Poll)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	return postId, tags, nil
+}
+
+// announcePost runs what follows a new post going live once it is
+// committed. Failures are logged, the post is already saved.
+func (p *PostService) announcePost(ctx context.Context, postId int32, post newPost, tags []string) {
+	// trending is public, and only followers get posts on their timeline
+	if post.Visibility == VisibilityPublic {
+		p.recordTrendingTags(ctx, tags, time.Now(), 1)
+	}
+	if post.Visibility != VisibilityPrivate {
+		p.publishMentions(postId, post.UserId, post.Username, post.Mentions, nil)
+	}
+	if post.Visibility == VisibilityPublic || post.Visibility == VisibilityFollowers {
+		p.fanOutPost(post.UserId, postId)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/post_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"github.com/lib/pq"
+)
+
+const (
+	PostKindPost   = "post"
+	PostKindRepost = "repost"
+	PostKindQuote  = "quote"
+
+	maxQuoteLength = 2000
+)
+
+var (
+	ErrAlreadyReposted = errors.New("post is already reposted")
+	ErrInvalidQuote    = errors.New("quote must be 1 to 2000 characters")
+)
+
+// Repost shares the post as is. Reposting a repost shares its original.
+func (p *PostService) Repost(ctx context.Context, userId int32, username string, postId int32) (*PostView, error) {
+	return p.sharePost(ctx, userId, username, postId, PostKindRepost, "", "post.reposted")
+}
+
+// Quote shares the post with the user's commentary as the body.
+func (p *PostService) Quote(ctx context.Context, userId int32, username string, postId int32, body string) (*PostView, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > maxQuoteLength {
+		return nil, ErrInvalidQuote
+	}
+	return p.sharePost(ctx, userId, username, postId, PostKindQuote, body, "post.quoted")
+}
+
+// Unrepost removes the user's repost of the post, it is a no-op when there
+// is none.
+func (p *PostService) Unrepost(ctx context.Context, userId int32, postId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	original, err := resolveShareTarget(timeoutCtx, txQuries, postId)
+	if err != nil {
+		return err
+	}
+	repostId, err := txQuries.DeleteRepost(timeoutCtx, posts.DeleteRepostParams{
+		UserID:         userId,
+		OriginalPostID: sql.NullInt32{Int32: original.ID, Valid: true},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = txQuries.UpdatePostShareCounts(timeoutCtx, posts.UpdatePostShareCountsParams{
+		ID:          original.ID,
+		RepostDelta: -1,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return p.publishShareEvent("post.unreposted", repostId, original, userId, "")
+}
+
+func (p *PostService) sharePost(ctx context.Context, userId int32, username string, postId int32, kind string, body string, topic string) (*PostView, error) {
+	mentions := p.resolveMentions(ctx, userId, body)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := p.postDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	txQuries := p.postQuries.WithTx(tx)
+
+	original, err := resolveShareTarget(timeoutCtx, txQuries, postId)
+	if err != nil {
+		return nil, err
+	}
+	if original.Visibility != VisibilityPublic {
+		// release the row locks before the follow graph rpc
+		tx.Rollback()
+		err = p.checkReadable(timeoutCtx, userId, original)
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrPostNotShareable
+	}
+	post, err := txQuries.CreateSharedPost(timeoutCtx, posts.CreateSharedPostParams{
+		UserID:         userId,
+		Username:       username,
+		Body:           body,
+		Kind:           kind,
+		OriginalPostID: sql.NullInt32{Int32: original.ID, Valid: true},
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrAlreadyReposted
+		}
+		return nil, err
+	}
+	tags := extractHashtags(body)
+	err = setPostHashtags(timeoutCtx, txQuries, post.ID, tags)
+	if err != nil {
+		return nil, err
+	}
+	err = setPostMentions(timeoutCtx, txQuries, post.ID, mentions)
+	if err != nil {
+		return nil, err
+	}
+	counts := posts.UpdatePostShareCountsParams{ID: original.ID}
+	if kind == PostKindRepost {
+		counts.RepostDelta = 1
+	} else {
+		counts.QuoteDelta = 1
+	}
+	err = txQuries.UpdatePostShareCounts(timeoutCtx, counts)
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	p.recordTrendingTags(timeoutCtx, tags, post.CreatedAt, 1)
+	p.fanOutPost(userId, post.ID)
+	p.publishMentions(post.ID, userId, username, mentions, nil)
+	err = p.publishShareEvent(topic, post.ID, original, userId, username)
+	if err != nil {
+		return nil, err
+	}
+	views, err := p.toPostViews(timeoutCtx, userId, []posts.Post{post})
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// resolveShareTarget locks the post being shared, following a repost to its
+// original so shares always point at the post with the content.
+func resolveShareTarget(ctx context.Context, txQuries *posts.Queries, postId int32) (posts.Post, error) {
+	post, err := txQuries.GetPostForUpdate(ctx, postId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return posts.Post{}, ErrPostNotFound
+	}
+	if err != nil {
+		return posts.Post{}, err
+	}
+	if post.Kind != PostKindRepost {
+		return post, nil
+	}
+	if !post.OriginalPostID.Valid {
+		return posts.Post{}, ErrPostNotFound
+	}
+	return resolveShareTarget(ctx, txQuries, post.OriginalPostID.Int32)
+}
+
+func (p *PostService) publishShareEvent(topic string, postId int32, original posts.Post, userId int32, username string) error {
+	msg, err := json.Marshal(rabbitmq_producer.PostSharedMsg{
+		PostId:           postId,
+		OriginalPostId:   original.ID,
+		OriginalAuthorId: original.UserID,
+		UserId:           userId,
+		Username:         username,
+	})
+	if err != nil {
+		return err
+	}
+	return p.rabbitmProducer.Publish("post_events", topic, msg)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRepostAndQuote(t *testing.T) {
+	ctx := context.Background()
+	postService, producer := newTestPostService(t)
+	originalId := createTestPost(t, postService, 1, "original post")
+
+	repost, err := postService.Repost(ctx, 2, "user2", originalId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repost.Original == nil || repost.Original.ID != originalId {
+		t.Errorf("repost embeds %+v, want post %d", repost.Original, originalId)
+	}
+	_, err = postService.Repost(ctx, 2, "user2", originalId)
+	if !errors.Is(err, ErrAlreadyReposted) {
+		t.Errorf("reposting twice = %v, want %v", err, ErrAlreadyReposted)
+	}
+	// reposting a repost shares its original
+	second, err := postService.Repost(ctx, 3, "user3", repost.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.OriginalPostID.Int32 != originalId {
+		t.Errorf("repost of a repost points at %d, want %d", second.OriginalPostID.Int32, originalId)
+	}
+	for _, body := range []string{"  ", strings.Repeat("a", maxQuoteLength+1)} {
+		_, err = postService.Quote(ctx, 2, "user2", originalId, body)
+		if !errors.Is(err, ErrInvalidQuote) {
+			t.Errorf("quoting with a %d character body = %v, want %v", len(body), err, ErrInvalidQuote)
+		}
+	}
+	quote, err := postService.Quote(ctx, 2, "user2", originalId, "so true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err = postService.Unrepost(ctx, 2, originalId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rows, err := postService.postQuries.GetPostsByIds(ctx, []int32{originalId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].RepostCount != 1 || rows[0].QuoteCount != 1 {
+		t.Errorf("share counts = %+v, want 1 repost and 1 quote", rows)
+	}
+
+	// the quote outlives the original as a tombstone
+	err = postService.DeletePost(ctx, originalId, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := postService.GetUserPostsPaginated(ctx, 2, 2, PostPageReq{PageNo: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Posts) != 1 || page.Posts[0].ID != quote.ID || !page.Posts[0].Tombstone {
+		t.Errorf("posts after the original was deleted = %+v, want the quote as a tombstone", page.Posts)
+	}
+	want := []string{"post.reposted", "post.reposted", "post.quoted", "post.unreposted", "media.deleted"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// authors with more followers than this are not fanned out on write,
+	// their posts are merged into timelines when they are read
+	fanoutFollowerLimit  = 10000
+	fanoutBatchSize      = 1000
+	timelineMaxLength    = 800
+	timelineTTL          = 7 * 24 * time.Hour
+	timelineBackfillSize = 50
+)
+
+// addIfTimelineLoaded only adds to timelines that are already in redis, a
+// cold timeline is rebuilt from postgres on its next read and would
+// otherwise look complete with a single post in it.
+var addIfTimelineLoaded = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	redis.call("ZADD", KEYS[1], ARGV[1], ARGV[1])
+	redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -(tonumber(ARGV[2]) + 1))
+	return 1
+end
+return 0
+`)
+
+// timelineKey is a sorted set of post ids scored by the id itself, ids grow
+// with creation time so the set is newest first when read in reverse.
+func timelineKey(userId int32) string {
+	return fmt.Sprintf("timeline:%d", userId)
+}
+
+// fanOutPost pushes a new post into the timelines of the author and their
+// followers. It runs in the background so posting does not wait on it.
+func (p *PostService) fanOutPost(authorId int32, postId int32) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		err := p.addToTimelines(ctx, []int32{authorId}, postId)
+		if err != nil {
+			log.Println("fan out post:", err)
+			return
+		}
+		followerCount, err := p.postQuries.GetFollowerCount(ctx, authorId)
+		if err != nil {
+			log.Println("fan out post:", err)
+			return
+		}
+		if followerCount > fanoutFollowerLimit {
+			return
+		}
+		var afterId int32
+		for {
+			followerIds, err := p.postQuries.GetFollowerIdsPage(ctx, posts.GetFollowerIdsPageParams{
+				FolloweeID: authorId,
+				Limit:      fanoutBatchSize,
+				AfterID:    afterId,
+			})
+			if err != nil {
+				log.Println("fan out post:", err)
+				return
+			}
+			if len(followerIds) == 0 {
+				return
+			}
+			err = p.addToTimelines(ctx, followerIds, postId)
+			if err != nil {
+				log.Println("fan out post:", err)
+				return
+			}
+			if len(followerIds) < fanoutBatchSize {
+				return
+			}
+			afterId = followerIds[len(followerIds)-1]
+		}
+	}()
+}
+
+func (p *PostService) addToTimelines(ctx context.Context, userIds []int32, postId int32) error {
+	pipe := p.redisClient.Pipeline()
+	for _, userId := range userIds {
+		addIfTimelineLoaded.Eval(ctx, pipe, []string{timelineKey(userId)}, postId, timelineMaxLength)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// backfillTimeline adds the followee's recent posts to a loaded timeline
+// right after a follow. Accounts too big to fan out are merged on read.
+func (p *PostService) backfillTimeline(ctx context.Context, followerId int32, followeeId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	followerCount, err := p.postQuries.GetFollowerCount(timeoutCtx, followeeId)
+	if err != nil {
+		return err
+	}
+	if followerCount > fanoutFollowerLimit {
+		return nil
+	}
+	postIds, err := p.postQuries.GetRecentPostIdsByUser(timeoutCtx, posts.GetRecentPostIdsByUserParams{
+		UserID: followeeId,
+		Limit:  timelineBackfillSize,
+	})
+	if err != nil {
+		return err
+	}
+	pipe := p.redisClient.Pipeline()
+	for _, postId := range postIds {
+		addIfTimelineLoaded.Eval(timeoutCtx, pipe, []string{timelineKey(followerId)}, postId, timelineMaxLength)
+	}
+	_, err = pipe.Exec(timeoutCtx)
+	return err
+}
+
+// removeAuthorFromTimeline drops the author's recent posts from the user's
+// timeline. Older ones may linger in redis but are past most reads.
+func (p *PostService) removeAuthorFromTimeline(ctx context.Context, userId int32, authorId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	postIds, err := p.postQuries.GetRecentPostIdsByUser(timeoutCtx, posts.GetRecentPostIdsByUserParams{
+		UserID: authorId,
+		Limit:  timelineMaxLength,
+	})
+	if err != nil {
+		return err
+	}
+	if len(postIds) == 0 {
+		return nil
+	}
+	members := make([]interface{}, len(postIds))
+	for i, postId := range postIds {
+		members[i] = postId
+	}
+	return p.redisClient.ZRem(timeoutCtx, timelineKey(userId), members...).Err()
+}
+
+// GetTimeline returns the viewer's home timeline, newest first. Fanned out
+// posts come from redis, falling back to postgres past its end or when it is
+// unavailable, and posts from accounts too big to fan out are merged in.
+func (p *PostService) GetTimeline(ctx context.Context, viewerId int32, page TimelinePageReq) (*TimelinePageResp, error) {
+	beforeId, err := decodePostCursor(page.After)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	postIds, err := p.getTimelinePostIds(timeoutCtx, viewerId, beforeId, page.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	largeAuthorIds, err := p.postQuries.GetFanoutOnReadPostIds(timeoutCtx, posts.GetFanoutOnReadPostIdsParams{
+		ViewerID:    viewerId,
+		FanoutLimit: fanoutFollowerLimit,
+		BeforeID:    beforeId,
+		PageLimit:   page.Limit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	postIds = mergePostIds(postIds, largeAuthorIds)
+	hasMore := len(postIds) > int(page.Limit)
+	if hasMore {
+		postIds = postIds[:page.Limit]
+	}
+
+	rows, err := p.postQuries.GetPostsByIds(timeoutCtx, postIds)
+	if err != nil {
+		return nil, err
+	}
+	byId := make(map[int32]posts.Post, len(rows))
+	for _, row := range rows {
+		byId[row.ID] = row
+	}
+	ordered := make([]posts.Post, 0, len(rows))
+	for _, postId := range postIds {
+		if post, ok := byId[postId]; ok {
+			ordered = append(ordered, post)
+		}
+	}
+	// the follow mirror can lag behind the user service, which has the say
+	// on followers-only posts
+	ordered, err = p.visiblePosts(timeoutCtx, viewerId, ordered)
+	if err != nil {
+		return nil, err
+	}
+	views, err := p.toPostViews(timeoutCtx, viewerId, ordered)
+	if err != nil {
+		return nil, err
+	}
+	resp := &TimelinePageResp{Posts: views}
+	if hasMore {
+		resp.NextCursor = encodePostCursor(postIds[len(postIds)-1])
+	}
+	return resp, nil
+}
+
+// getTimelinePostIds reads up to limit fanned out post ids older than
+// beforeId, loading the timeline into redis first when it is cold.
+func (p *PostService) getTimelinePostIds(ctx context.Context, viewerId int32, beforeId int32, limit int32) ([]int32, error) {
+	key := timelineKey(viewerId)
+	postIds, err := p.readTimeline(ctx, key, viewerId, beforeId, limit)
+	if err != nil {
+		log.Println("read timeline:", err)
+		return p.postQuries.GetTimelinePostIds(ctx, posts.GetTimelinePostIdsParams{
+			ViewerID:  viewerId,
+			BeforeID:  beforeId,
+			PageLimit: limit,
+		})
+	}
+	if len(postIds) == int(limit) {
+		return postIds, nil
+	}
+	// redis only keeps the newest posts, page past them from postgres
+	if len(postIds) > 0 {
+		beforeId = postIds[len(postIds)-1]
+	}
+	olderIds, err := p.postQuries.GetTimelinePostIds(ctx, posts.GetTimelinePostIdsParams{
+		ViewerID:  viewerId,
+		BeforeID:  beforeId,
+		PageLimit: limit - int32(len(postIds)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(postIds, olderIds...), nil
+}
+
+func (p *PostService) readTimeline(ctx context.Context, key string, viewerId int32, beforeId int32, limit int32) ([]int32, error) {
+	exists, err := p.redisClient.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		err = p.loadTimeline(ctx, key, viewerId)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.redisClient.Expire(ctx, key, timelineTTL)
+	}
+	members, err := p.redisClient.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Max:   "(" + strconv.Itoa(int(beforeId)),
+		Min:   "-inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	postIds := make([]int32, 0, len(members))
+	for _, member := range members {
+		postId, err := strconv.Atoi(member)
+		if err != nil {
+			continue
+		}
+		postIds = append(postIds, int32(postId))
+	}
+	return postIds, nil
+}
+
+func (p *PostService) loadTimeline(ctx context.Context, key string, viewerId int32) error {
+	postIds, err := p.postQuries.GetTimelinePostIds(ctx, posts.GetTimelinePostIdsParams{
+		ViewerID:  viewerId,
+		BeforeID:  math.MaxInt32,
+		PageLimit: timelineMaxLength,
+	})
+	if err != nil {
+		return err
+	}
+	if len(postIds) == 0 {
+		return nil
+	}
+	members := make([]redis.Z, len(postIds))
+	for i, postId := range postIds {
+		members[i] = redis.Z{Score: float64(postId), Member: postId}
+	}
+	pipe := p.redisClient.TxPipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, timelineTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// mergePostIds combines newest first id lists, dropping duplicates.
+func mergePostIds(a []int32, b []int32) []int32 {
+	seen := make(map[int32]struct{}, len(a)+len(b))
+	merged := make([]int32, 0, len(a)+len(b))
+	for _, ids := range [][]int32{a, b} {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			seen[id] = struct{}{}
+			merged = append(merged, id)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i] > merged[j]
+	})
+	return merged
+}
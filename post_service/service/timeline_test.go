@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestMergePostIds(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []int32
+		b    []int32
+		want []int32
+	}{
+		{name: "both empty", a: nil, b: nil, want: []int32{}},
+		{name: "only fanned out", a: []int32{9, 5, 2}, b: nil, want: []int32{9, 5, 2}},
+		{name: "only pulled", a: nil, b: []int32{8, 3}, want: []int32{8, 3}},
+		{name: "interleaved", a: []int32{9, 5, 2}, b: []int32{8, 6, 1}, want: []int32{9, 8, 6, 5, 2, 1}},
+		{name: "duplicates across lists", a: []int32{9, 5}, b: []int32{9, 7, 5}, want: []int32{9, 7, 5}},
+		{name: "duplicates within a list", a: []int32{4, 4, 3}, b: []int32{3}, want: []int32{4, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergePostIds(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergePostIds(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTimeline(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	err := postService.FollowAuthor(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := createTestPost(t, postService, 1, "own post")
+	first := createTestPost(t, postService, 2, "followed first")
+	createTestPost(t, postService, 3, "not followed")
+	second := createTestPost(t, postService, 2, "followed second")
+
+	timelineIds := func() []int32 {
+		t.Helper()
+		got := []int32{}
+		after := ""
+		for pages := 0; pages < 10; pages++ {
+			resp, err := postService.GetTimeline(ctx, 1, TimelinePageReq{After: after, Limit: 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, post := range resp.Posts {
+				got = append(got, post.ID)
+			}
+			if resp.NextCursor == "" {
+				return got
+			}
+			after = resp.NextCursor
+		}
+		t.Fatalf("paging did not end, got %v so far", got)
+		return nil
+	}
+
+	want := []int32{second, first, own}
+	if got := timelineIds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("timeline = %v, want %v", got, want)
+	}
+	// the timeline is loaded in redis now, a block must still take effect
+	err = postService.RemoveFollowsBetween(ctx, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []int32{own}
+	if got := timelineIds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("timeline after block = %v, want %v", got, want)
+	}
+}
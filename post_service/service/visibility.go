@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/post_service/sql/posts"
+)
+
+// Besides its author a post can be read by everyone, by the author's
+// followers and the users it mentions, by the mentioned users only, or by
+// nobody.
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityMentioned = "mentioned"
+	VisibilityPrivate   = "private"
+)
+
+// followCheckBatchSize matches the most user ids the user service checks in
+// one call
+const followCheckBatchSize = 500
+
+var (
+	ErrInvalidVisibility = errors.New("visibility must be one of public, followers, mentioned or private")
+	ErrPostNotShareable  = errors.New("only public posts can be shared")
+)
+
+// normalizeVisibility defaults an empty visibility to public.
+func normalizeVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return VisibilityPublic, nil
+	case VisibilityPublic, VisibilityFollowers, VisibilityMentioned, VisibilityPrivate:
+		return visibility, nil
+	default:
+		return "", ErrInvalidVisibility
+	}
+}
+
+// visiblePosts keeps the rows viewerId can read, in order. viewerId is 0 for
+// anonymous readers, who only see public posts. Follows are checked with the
+// user service, when it cannot be reached followers-only posts are left out.
+func (p *PostService) visiblePosts(ctx context.Context, viewerId int32, rows []posts.Post) ([]posts.Post, error) {
+	restricted := make([]int32, 0)
+	authorIds := make([]int32, 0)
+	seenAuthors := make(map[int32]struct{})
+	for _, post := range rows {
+		if post.Visibility == VisibilityPublic || post.UserID == viewerId || viewerId <= 0 {
+			continue
+		}
+		if post.Visibility == VisibilityFollowers || post.Visibility == VisibilityMentioned {
+			restricted = append(restricted, post.ID)
+		}
+		if _, ok := seenAuthors[post.UserID]; !ok && post.Visibility == VisibilityFollowers {
+			seenAuthors[post.UserID] = struct{}{}
+			authorIds = append(authorIds, post.UserID)
+		}
+	}
+	mentioned := make(map[int32]struct{})
+	if len(restricted) > 0 {
+		postIds, err := p.postQuries.GetViewerMentionedPostIds(ctx, posts.GetViewerMentionedPostIdsParams{
+			ViewerID: viewerId,
+			PostIds:  restricted,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, postId := range postIds {
+			mentioned[postId] = struct{}{}
+		}
+	}
+	followed := p.followedAmong(ctx, viewerId, authorIds)
+
+	visible := make([]posts.Post, 0, len(rows))
+	for _, post := range rows {
+		ok := post.Visibility == VisibilityPublic || (viewerId > 0 && post.UserID == viewerId)
+		if !ok && viewerId > 0 {
+			_, isMentioned := mentioned[post.ID]
+			_, isFollower := followed[post.UserID]
+			switch post.Visibility {
+			case VisibilityFollowers:
+				ok = isFollower || isMentioned
+			case VisibilityMentioned:
+				ok = isMentioned
+			}
+		}
+		if ok {
+			visible = append(visible, post)
+		}
+	}
+	return visible, nil
+}
+
+// followedAmong returns the authors viewerId follows. Failures, including a
+// user service that does not answer in time, are logged and treated as not
+// following.
+func (p *PostService) followedAmong(ctx context.Context, viewerId int32, authorIds []int32) map[int32]struct{} {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	followed := make(map[int32]struct{})
+	for start := 0; start < len(authorIds); start += followCheckBatchSize {
+		end := start + followCheckBatchSize
+		if end > len(authorIds) {
+			end = len(authorIds)
+		}
+		ids, err := p.rpcClient.GetFollowedAmong(timeoutCtx, viewerId, authorIds[start:end])
+		if err != nil {
+			log.Println("check follows:", err)
+			return followed
+		}
+		for _, id := range ids {
+			followed[id] = struct{}{}
+		}
+	}
+	return followed
+}
+
+// viewerFollows reports whether viewerId follows userId, for queries that
+// filter one author's posts in sql.
+func (p *PostService) viewerFollows(ctx context.Context, viewerId int32, userId int32) bool {
+	if viewerId <= 0 || viewerId == userId {
+		return false
+	}
+	_, ok := p.followedAmong(ctx, viewerId, []int32{userId})[userId]
+	return ok
+}
+
+// getReadablePost loads a post viewerId can read. Posts the viewer cannot
+// see are reported as not found so their existence is not revealed.
+func (p *PostService) getReadablePost(ctx context.Context, viewerId int32, postId int32) (posts.Post, error) {
+	post, err := p.postQuries.GetVisiblePost(ctx, postId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return posts.Post{}, ErrPostNotFound
+	}
+	if err != nil {
+		return posts.Post{}, err
+	}
+	err = p.checkReadable(ctx, viewerId, post)
+	if err != nil {
+		return posts.Post{}, err
+	}
+	return post, nil
+}
+
+// checkReadable returns ErrPostNotFound when viewerId cannot read post.
+func (p *PostService) checkReadable(ctx context.Context, viewerId int32, post posts.Post) error {
+	visible, err := p.visiblePosts(ctx, viewerId, []posts.Post{post})
+	if err != nil {
+		return err
+	}
+	if len(visible) == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// GetPost returns a single post the viewer can read.
+func (p *PostService) GetPost(ctx context.Context, viewerId int32, postId int32) (*PostView, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	post, err := p.getReadablePost(timeoutCtx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+	views, err := p.toPostViews(timeoutCtx, viewerId, []posts.Post{post})
+	if err != nil {
+		return nil, err
+	}
+	return &views[0], nil
+}
+
+// CanViewMedia reports whether viewerId can read a post showing the media,
+// now or in an earlier revision.
+func (p *PostService) CanViewMedia(ctx context.Context, viewerId int32, mediaId int32) (bool, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	rows, err := p.postQuries.GetPostsByMediaId(timeoutCtx, mediaId)
+	if err != nil {
+		return false, err
+	}
+	visible, err := p.visiblePosts(timeoutCtx, viewerId, rows)
+	if err != nil {
+		return false, err
+	}
+	return len(visible) > 0, nil
+}
+
+// restrictMedia has the media service check access to media attached to a
+// post that is not public. It runs before the post is committed, so the
+// media is never served to readers the post is hidden from.
+func (p *PostService) restrictMedia(ctx context.Context, visibility string, mediaIds []int32) error {
+	if visibility == VisibilityPublic {
+		return nil
+	}
+	return p.hideMedia(ctx, mediaIds)
+}
+
+// hideMedia restricts the media whatever the visibility. Attachments of
+// scheduled posts and drafts are only served to their author until they are
+// published, no post grants anyone else access before then.
+func (p *PostService) hideMedia(ctx context.Context, mediaIds []int32) error {
+	if len(mediaIds) == 0 {
+		return nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	return p.rpcClient.RestrictMedia(timeoutCtx, mediaIds)
+}
+
+// revealMedia lifts the restriction hideMedia put on the media once it went
+// live in a public post. It runs after the post is committed, a failure is
+// only logged and leaves the media to signed in readers through
+// CanViewMedia.
+func (p *PostService) revealMedia(ctx context.Context, visibility string, mediaIds []int32) {
+	if visibility != VisibilityPublic || len(mediaIds) == 0 {
+		return
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	err := p.rpcClient.UnrestrictMedia(timeoutCtx, mediaIds)
+	if err != nil {
+		log.Println("unrestrict media:", err)
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPostVisibility(t *testing.T) {
+	ctx := context.Background()
+	postService, _ := newTestPostService(t)
+	fake := SetupRpcServer(t, postService)
+	// user 2 follows the author, user 3 is mentioned, user 4 is neither
+	fake.AddFollow(2, 1)
+	fake.AddMentionable(3, "user3")
+
+	err := postService.CreatePost(ctx, CreatePostInput{UserId: 1, Username: "user1", Body: "hidden", Visibility: "friends"})
+	if !errors.Is(err, ErrInvalidVisibility) {
+		t.Errorf("unknown visibility = %v, want %v", err, ErrInvalidVisibility)
+	}
+	postIds := map[string]int32{}
+	for _, visibility := range []string{VisibilityPublic, VisibilityFollowers, VisibilityMentioned, VisibilityPrivate} {
+		postIds[visibility] = createTestPostWith(t, postService, CreatePostInput{
+			UserId:     1,
+			Body:       visibility + " post for @user3",
+			Visibility: visibility,
+		})
+	}
+	mediaPostId := createTestPostWith(t, postService, CreatePostInput{
+		UserId:      1,
+		Body:        "followers picture",
+		Visibility:  VisibilityFollowers,
+		Attachments: []AttachmentUpload{testAttachment(21, "image/png", "")},
+	})
+
+	readers := map[string][]int32{
+		VisibilityPublic:    {0, 1, 2, 3, 4},
+		VisibilityFollowers: {1, 2, 3},
+		VisibilityMentioned: {1, 3},
+		VisibilityPrivate:   {1},
+	}
+	for visibility, want := range readers {
+		got := []int32{}
+		for _, viewerId := range []int32{0, 1, 2, 3, 4} {
+			_, err := postService.GetPost(ctx, viewerId, postIds[visibility])
+			if errors.Is(err, ErrPostNotFound) {
+				continue
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, viewerId)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s post readable by %v, want %v", visibility, got, want)
+		}
+	}
+
+	// profile pages filter in sql and must agree with GetPost
+	profiles := map[int32]int{0: 1, 1: 5, 2: 3, 3: 3, 4: 1}
+	for viewerId, want := range profiles {
+		page, err := postService.GetUserPostsByCursor(ctx, viewerId, 1, PostPageReq{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Posts) != want {
+			t.Errorf("viewer %d sees %d posts on the profile, want %d", viewerId, len(page.Posts), want)
+		}
+	}
+
+	_, err = postService.Repost(ctx, 2, "user2", postIds[VisibilityFollowers])
+	if !errors.Is(err, ErrPostNotShareable) {
+		t.Errorf("reposting a followers post = %v, want %v", err, ErrPostNotShareable)
+	}
+	_, err = postService.Repost(ctx, 4, "user4", postIds[VisibilityPrivate])
+	if !errors.Is(err, ErrPostNotFound) {
+		t.Errorf("reposting an unreadable post = %v, want %v", err, ErrPostNotFound)
+	}
+
+	for viewerId, want := range map[int32]bool{0: false, 1: true, 2: true, 4: false} {
+		ok, err := postService.CanViewMedia(ctx, viewerId, 21)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != want {
+			t.Errorf("viewer %d can view media of post %d = %v, want %v", viewerId, mediaPostId, ok, want)
+		}
+	}
+	// the media is restricted before the post that shows it is saved
+	if got := fake.Restricted(); !reflect.DeepEqual(got, []int32{21}) {
+		t.Errorf("restricted media %v, want [21]", got)
+	}
+}
+
+func TestVisibilityRpcDeadlines(t *testing.T) {
+	postService := &PostService{}
+	fake := SetupRpcServer(t, postService)
+	fake.AddFollow(2, 1)
+	release := fake.Hang()
+	defer release()
+
+	start := time.Now()
+	if followed := postService.followedAmong(context.Background(), 2, []int32{1}); len(followed) != 0 {
+		t.Errorf("follows from a hung user service = %v, want none", followed)
+	}
+	err := postService.restrictMedia(context.Background(), VisibilityFollowers, []int32{1})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("restricting with a hung media service = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("rpc calls took %v with the services hung", elapsed)
+	}
+}
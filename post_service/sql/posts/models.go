@@ -9,11 +9,162 @@ import (
 	"time"
 )
 
+type AuthorBadge struct {
+	UserID     int32     `json:"userId"`
+	Badge      string    `json:"badge"`
+	VerifiedAt time.Time `json:"verifiedAt"`
+}
+
+type Comment struct {
+	ID              int32         `json:"id"`
+	PostID          int32         `json:"postId"`
+	ParentCommentID sql.NullInt32 `json:"parentCommentId"`
+	UserID          int32         `json:"userId"`
+	Username        string        `json:"username"`
+	AuthorBadge     string        `json:"authorBadge"`
+	Body            string        `json:"body"`
+	ReplyCount      int32         `json:"replyCount"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	EditedAt        sql.NullTime  `json:"editedAt"`
+	DeletedAt       sql.NullTime  `json:"deletedAt"`
+}
+
+type DeactivatedAuthor struct {
+	UserID        int32     `json:"userId"`
+	DeactivatedAt time.Time `json:"deactivatedAt"`
+}
+
+type Draft struct {
+	ID         int32     `json:"id"`
+	UserID     int32     `json:"userId"`
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	MediaIds   []int32   `json:"mediaIds"`
+	AltTexts   []string  `json:"altTexts"`
+	Version    int32     `json:"version"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type Follow struct {
+	FollowerID int32     `json:"followerId"`
+	FolloweeID int32     `json:"followeeId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type FollowerCount struct {
+	UserID int32 `json:"userId"`
+	Count  int32 `json:"count"`
+}
+
+type FollowsBackfill struct {
+	ID              int32        `json:"id"`
+	AfterFollowerID int32        `json:"afterFollowerId"`
+	AfterFolloweeID int32        `json:"afterFolloweeId"`
+	CompletedAt     sql.NullTime `json:"completedAt"`
+}
+
+type Hashtag struct {
+	HashtagID int32     `json:"hashtagId"`
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type Poll struct {
+	PostID         int32        `json:"postId"`
+	MultipleChoice bool         `json:"multipleChoice"`
+	EndsAt         time.Time    `json:"endsAt"`
+	ClosedAt       sql.NullTime `json:"closedAt"`
+	VoterCount     int32        `json:"voterCount"`
+}
+
+type PollOption struct {
+	PostID    int32  `json:"postId"`
+	Position  int32  `json:"position"`
+	Text      string `json:"text"`
+	VoteCount int32  `json:"voteCount"`
+}
+
+type PollVote struct {
+	PostID    int32     `json:"postId"`
+	UserID    int32     `json:"userId"`
+	Positions []int32   `json:"positions"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type Post struct {
-	ID        int32         `json:"id"`
-	UserID    int32         `json:"userId"`
-	Username  string        `json:"username"`
-	Body      string        `json:"body"`
-	MediaID   sql.NullInt32 `json:"mediaId"`
-	CreatedAt time.Time     `json:"createdAt"`
+	ID             int32         `json:"id"`
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Body           string        `json:"body"`
+	MediaID        sql.NullInt32 `json:"mediaId"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	AuthorBadge    string        `json:"authorBadge"`
+	CommentCount   int32         `json:"commentCount"`
+	Kind           string        `json:"kind"`
+	OriginalPostID sql.NullInt32 `json:"originalPostId"`
+	RepostCount    int32         `json:"repostCount"`
+	QuoteCount     int32         `json:"quoteCount"`
+	EditedAt       sql.NullTime  `json:"editedAt"`
+	Visibility     string        `json:"visibility"`
+}
+
+type PostHashtag struct {
+	PostID    int32 `json:"postId"`
+	HashtagID int32 `json:"hashtagId"`
+}
+
+type PostMedium struct {
+	PostID   int32  `json:"postId"`
+	Position int32  `json:"position"`
+	MediaID  int32  `json:"mediaId"`
+	AltText  string `json:"altText"`
+}
+
+type PostMention struct {
+	PostID      int32  `json:"postId"`
+	UserID      int32  `json:"userId"`
+	Username    string `json:"username"`
+	StartOffset int32  `json:"startOffset"`
+	EndOffset   int32  `json:"endOffset"`
+}
+
+type PostReactionCount struct {
+	PostID   int32  `json:"postId"`
+	Reaction string `json:"reaction"`
+	Count    int32  `json:"count"`
+}
+
+type PostRevision struct {
+	RevisionID int32         `json:"revisionId"`
+	PostID     int32         `json:"postId"`
+	Body       string        `json:"body"`
+	MediaID    sql.NullInt32 `json:"mediaId"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	ReplacedAt time.Time     `json:"replacedAt"`
+	MediaIds   []int32       `json:"mediaIds"`
+}
+
+type Reaction struct {
+	PostID    int32     `json:"postId"`
+	UserID    int32     `json:"userId"`
+	Reaction  string    `json:"reaction"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ScheduledPost struct {
+	ID         int32         `json:"id"`
+	UserID     int32         `json:"userId"`
+	Username   string        `json:"username"`
+	Body       string        `json:"body"`
+	Visibility string        `json:"visibility"`
+	MediaIds   []int32       `json:"mediaIds"`
+	AltTexts   []string      `json:"altTexts"`
+	PublishAt  time.Time     `json:"publishAt"`
+	Status     string        `json:"status"`
+	PostID     sql.NullInt32 `json:"postId"`
+	Attempts   int32         `json:"attempts"`
+	LastError  string        `json:"lastError"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
 }
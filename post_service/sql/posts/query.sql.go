@@ -8,67 +8,594 @@ package posts
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :exec
-INSERT INTO Posts(user_id,username,body,media_id) VALUES ($1,$2,$3,$4)
+const claimDuePoll = `-- name: ClaimDuePoll :one
+SELECT polls.post_id, polls.multiple_choice, polls.ends_at, polls.closed_at, polls.voter_count, posts.user_id AS author_id FROM polls
+JOIN posts ON posts.id = polls.post_id
+WHERE polls.closed_at IS NULL AND polls.ends_at <= NOW()
+ORDER BY polls.ends_at
+LIMIT 1
+FOR UPDATE OF polls SKIP LOCKED
+`
+
+type ClaimDuePollRow struct {
+	PostID         int32        `json:"postId"`
+	MultipleChoice bool         `json:"multipleChoice"`
+	EndsAt         time.Time    `json:"endsAt"`
+	ClosedAt       sql.NullTime `json:"closedAt"`
+	VoterCount     int32        `json:"voterCount"`
+	AuthorID       int32        `json:"authorId"`
+}
+
+// the oldest ended poll not announced yet, rows another replica is closing
+// are skipped
+func (q *Queries) ClaimDuePoll(ctx context.Context) (ClaimDuePollRow, error) {
+	row := q.db.QueryRowContext(ctx, claimDuePoll)
+	var i ClaimDuePollRow
+	err := row.Scan(
+		&i.PostID,
+		&i.MultipleChoice,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.VoterCount,
+		&i.AuthorID,
+	)
+	return i, err
+}
+
+const claimDueScheduledPost = `-- name: ClaimDueScheduledPost :one
+SELECT id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at FROM scheduled_posts
+WHERE id = $1 AND status = 'scheduled' AND publish_at <= NOW()
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ClaimDueScheduledPost(ctx context.Context, id int32) (ScheduledPost, error) {
+	row := q.db.QueryRowContext(ctx, claimDueScheduledPost, id)
+	var i ScheduledPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.PublishAt,
+		&i.Status,
+		&i.PostID,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const countDraftsByUser = `-- name: CountDraftsByUser :one
+SELECT COUNT(*) FROM drafts WHERE user_id = $1
+`
+
+func (q *Queries) CountDraftsByUser(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countDraftsByUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countPendingScheduledPosts = `-- name: CountPendingScheduledPosts :one
+SELECT COUNT(*) FROM scheduled_posts
+WHERE user_id = $1 AND status IN ('scheduled', 'failed')
+`
+
+func (q *Queries) CountPendingScheduledPosts(ctx context.Context, userID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPendingScheduledPosts, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createComment = `-- name: CreateComment :one
+INSERT INTO comments(post_id, parent_comment_id, user_id, username, body, author_badge)
+VALUES ($1, $2, $3, $4, $5, COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $3), ''))
+RETURNING id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at
+`
+
+type CreateCommentParams struct {
+	PostID          int32         `json:"postId"`
+	ParentCommentID sql.NullInt32 `json:"parentCommentId"`
+	UserID          int32         `json:"userId"`
+	Username        string        `json:"username"`
+	Body            string        `json:"body"`
+}
+
+func (q *Queries) CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, createComment,
+		arg.PostID,
+		arg.ParentCommentID,
+		arg.UserID,
+		arg.Username,
+		arg.Body,
+	)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.ParentCommentID,
+		&i.UserID,
+		&i.Username,
+		&i.AuthorBadge,
+		&i.Body,
+		&i.ReplyCount,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createDeactivatedAuthor = `-- name: CreateDeactivatedAuthor :exec
+INSERT INTO deactivated_authors(user_id) VALUES ($1) ON CONFLICT DO NOTHING
+`
+
+func (q *Queries) CreateDeactivatedAuthor(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, createDeactivatedAuthor, userID)
+	return err
+}
+
+const createDraft = `-- name: CreateDraft :one
+INSERT INTO drafts(user_id, body, visibility, media_ids, alt_texts)
+VALUES ($1, $2, $3, $4::int[], $5::text[])
+RETURNING id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at
+`
+
+type CreateDraftParams struct {
+	UserID     int32    `json:"userId"`
+	Body       string   `json:"body"`
+	Visibility string   `json:"visibility"`
+	MediaIds   []int32  `json:"mediaIds"`
+	AltTexts   []string `json:"altTexts"`
+}
+
+func (q *Queries) CreateDraft(ctx context.Context, arg CreateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, createDraft,
+		arg.UserID,
+		arg.Body,
+		arg.Visibility,
+		pq.Array(arg.MediaIds),
+		pq.Array(arg.AltTexts),
+	)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createFollow = `-- name: CreateFollow :execrows
+INSERT INTO follows(follower_id, followee_id) VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type CreateFollowParams struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) CreateFollow(ctx context.Context, arg CreateFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createFollow, arg.FollowerID, arg.FolloweeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createFollows = `-- name: CreateFollows :many
+INSERT INTO follows(follower_id, followee_id)
+SELECT unnest($1::int[]), unnest($2::int[])
+ON CONFLICT DO NOTHING
+RETURNING follower_id, followee_id
+`
+
+type CreateFollowsParams struct {
+	FollowerIds []int32 `json:"followerIds"`
+	FolloweeIds []int32 `json:"followeeIds"`
+}
+
+type CreateFollowsRow struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) CreateFollows(ctx context.Context, arg CreateFollowsParams) ([]CreateFollowsRow, error) {
+	rows, err := q.db.QueryContext(ctx, createFollows, pq.Array(arg.FollowerIds), pq.Array(arg.FolloweeIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CreateFollowsRow
+	for rows.Next() {
+		var i CreateFollowsRow
+		if err := rows.Scan(&i.FollowerID, &i.FolloweeID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPoll = `-- name: CreatePoll :exec
+INSERT INTO polls(post_id, multiple_choice, ends_at) VALUES ($1, $2, $3)
+`
+
+type CreatePollParams struct {
+	PostID         int32     `json:"postId"`
+	MultipleChoice bool      `json:"multipleChoice"`
+	EndsAt         time.Time `json:"endsAt"`
+}
+
+func (q *Queries) CreatePoll(ctx context.Context, arg CreatePollParams) error {
+	_, err := q.db.ExecContext(ctx, createPoll, arg.PostID, arg.MultipleChoice, arg.EndsAt)
+	return err
+}
+
+const createPollOptions = `-- name: CreatePollOptions :exec
+INSERT INTO poll_options(post_id, position, text)
+SELECT $1::int, i - 1, ($2::text[])[i]
+FROM generate_subscripts($2::text[], 1) AS i
+`
+
+type CreatePollOptionsParams struct {
+	PostID int32    `json:"postId"`
+	Texts  []string `json:"texts"`
+}
+
+func (q *Queries) CreatePollOptions(ctx context.Context, arg CreatePollOptionsParams) error {
+	_, err := q.db.ExecContext(ctx, createPollOptions, arg.PostID, pq.Array(arg.Texts))
+	return err
+}
+
+const createPollVote = `-- name: CreatePollVote :execrows
+INSERT INTO poll_votes(post_id, user_id, positions) VALUES ($1, $2, $3::int[])
+ON CONFLICT DO NOTHING
+`
+
+type CreatePollVoteParams struct {
+	PostID    int32   `json:"postId"`
+	UserID    int32   `json:"userId"`
+	Positions []int32 `json:"positions"`
+}
+
+func (q *Queries) CreatePollVote(ctx context.Context, arg CreatePollVoteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPollVote, arg.PostID, arg.UserID, pq.Array(arg.Positions))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createPost = `-- name: CreatePost :one
+INSERT INTO Posts(user_id,username,body,media_id,visibility,author_badge)
+VALUES ($1,$2,$3,$4,$5,COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $1), ''))
+RETURNING id
 `
 
 type CreatePostParams struct {
-	UserID   int32         `json:"userId"`
-	Username string        `json:"username"`
-	Body     string        `json:"body"`
-	MediaID  sql.NullInt32 `json:"mediaId"`
+	UserID     int32         `json:"userId"`
+	Username   string        `json:"username"`
+	Body       string        `json:"body"`
+	MediaID    sql.NullInt32 `json:"mediaId"`
+	Visibility string        `json:"visibility"`
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
-	_, err := q.db.ExecContext(ctx, createPost,
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, createPost,
 		arg.UserID,
 		arg.Username,
 		arg.Body,
 		arg.MediaID,
+		arg.Visibility,
 	)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createPostHashtags = `-- name: CreatePostHashtags :exec
+INSERT INTO post_hashtags(post_id, hashtag_id)
+SELECT $1::int, unnest($2::int[])
+ON CONFLICT DO NOTHING
+`
+
+type CreatePostHashtagsParams struct {
+	PostID     int32   `json:"postId"`
+	HashtagIds []int32 `json:"hashtagIds"`
+}
+
+func (q *Queries) CreatePostHashtags(ctx context.Context, arg CreatePostHashtagsParams) error {
+	_, err := q.db.ExecContext(ctx, createPostHashtags, arg.PostID, pq.Array(arg.HashtagIds))
 	return err
 }
 
-const deletePost = `-- name: DeletePost :exec
-DELETE FROM posts WHERE id = $1 AND user_id = $2
+const createPostMedia = `-- name: CreatePostMedia :exec
+INSERT INTO post_media(post_id, position, media_id, alt_text)
+SELECT $1::int, i - 1, ($2::int[])[i], ($3::text[])[i]
+FROM generate_subscripts($2::int[], 1) AS i
 `
 
-type DeletePostParams struct {
+type CreatePostMediaParams struct {
+	PostID   int32    `json:"postId"`
+	MediaIds []int32  `json:"mediaIds"`
+	AltTexts []string `json:"altTexts"`
+}
+
+func (q *Queries) CreatePostMedia(ctx context.Context, arg CreatePostMediaParams) error {
+	_, err := q.db.ExecContext(ctx, createPostMedia, arg.PostID, pq.Array(arg.MediaIds), pq.Array(arg.AltTexts))
+	return err
+}
+
+const createPostMentions = `-- name: CreatePostMentions :exec
+INSERT INTO post_mentions(post_id, user_id, username, start_offset, end_offset)
+SELECT $1::int,
+       unnest($2::int[]),
+       unnest($3::text[]),
+       unnest($4::int[]),
+       unnest($5::int[])
+`
+
+type CreatePostMentionsParams struct {
+	PostID       int32    `json:"postId"`
+	UserIds      []int32  `json:"userIds"`
+	Usernames    []string `json:"usernames"`
+	StartOffsets []int32  `json:"startOffsets"`
+	EndOffsets   []int32  `json:"endOffsets"`
+}
+
+func (q *Queries) CreatePostMentions(ctx context.Context, arg CreatePostMentionsParams) error {
+	_, err := q.db.ExecContext(ctx, createPostMentions,
+		arg.PostID,
+		pq.Array(arg.UserIds),
+		pq.Array(arg.Usernames),
+		pq.Array(arg.StartOffsets),
+		pq.Array(arg.EndOffsets),
+	)
+	return err
+}
+
+const createPostRevision = `-- name: CreatePostRevision :exec
+INSERT INTO post_revisions(post_id, body, media_id, media_ids, created_at)
+VALUES ($1, $2, $3, $5::int[], $4)
+`
+
+type CreatePostRevisionParams struct {
+	PostID    int32         `json:"postId"`
+	Body      string        `json:"body"`
+	MediaID   sql.NullInt32 `json:"mediaId"`
+	CreatedAt time.Time     `json:"createdAt"`
+	MediaIds  []int32       `json:"mediaIds"`
+}
+
+func (q *Queries) CreatePostRevision(ctx context.Context, arg CreatePostRevisionParams) error {
+	_, err := q.db.ExecContext(ctx, createPostRevision,
+		arg.PostID,
+		arg.Body,
+		arg.MediaID,
+		arg.CreatedAt,
+		pq.Array(arg.MediaIds),
+	)
+	return err
+}
+
+const createReaction = `-- name: CreateReaction :execrows
+INSERT INTO reactions(post_id, user_id, reaction) VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type CreateReactionParams struct {
+	PostID   int32  `json:"postId"`
+	UserID   int32  `json:"userId"`
+	Reaction string `json:"reaction"`
+}
+
+func (q *Queries) CreateReaction(ctx context.Context, arg CreateReactionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createReaction, arg.PostID, arg.UserID, arg.Reaction)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createScheduledPost = `-- name: CreateScheduledPost :one
+INSERT INTO scheduled_posts(user_id, username, body, visibility, media_ids, alt_texts, publish_at)
+VALUES ($1, $2, $3, $4, $6::int[], $7::text[], $5)
+RETURNING id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at
+`
+
+type CreateScheduledPostParams struct {
+	UserID     int32     `json:"userId"`
+	Username   string    `json:"username"`
+	Body       string    `json:"body"`
+	Visibility string    `json:"visibility"`
+	PublishAt  time.Time `json:"publishAt"`
+	MediaIds   []int32   `json:"mediaIds"`
+	AltTexts   []string  `json:"altTexts"`
+}
+
+func (q *Queries) CreateScheduledPost(ctx context.Context, arg CreateScheduledPostParams) (ScheduledPost, error) {
+	row := q.db.QueryRowContext(ctx, createScheduledPost,
+		arg.UserID,
+		arg.Username,
+		arg.Body,
+		arg.Visibility,
+		arg.PublishAt,
+		pq.Array(arg.MediaIds),
+		pq.Array(arg.AltTexts),
+	)
+	var i ScheduledPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.PublishAt,
+		&i.Status,
+		&i.PostID,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createSharedPost = `-- name: CreateSharedPost :one
+INSERT INTO posts(user_id, username, body, kind, original_post_id, author_badge)
+VALUES ($1, $2, $3, $4, $5, COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $1), ''))
+RETURNING id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility
+`
+
+type CreateSharedPostParams struct {
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Body           string        `json:"body"`
+	Kind           string        `json:"kind"`
+	OriginalPostID sql.NullInt32 `json:"originalPostId"`
+}
+
+func (q *Queries) CreateSharedPost(ctx context.Context, arg CreateSharedPostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, createSharedPost,
+		arg.UserID,
+		arg.Username,
+		arg.Body,
+		arg.Kind,
+		arg.OriginalPostID,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.MediaID,
+		&i.CreatedAt,
+		&i.AuthorBadge,
+		&i.CommentCount,
+		&i.Kind,
+		&i.OriginalPostID,
+		&i.RepostCount,
+		&i.QuoteCount,
+		&i.EditedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const decrementCommentReplyCount = `-- name: DecrementCommentReplyCount :one
+UPDATE comments SET reply_count = reply_count - 1 WHERE id = $1
+RETURNING id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at
+`
+
+func (q *Queries) DecrementCommentReplyCount(ctx context.Context, id int32) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, decrementCommentReplyCount, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.ParentCommentID,
+		&i.UserID,
+		&i.Username,
+		&i.AuthorBadge,
+		&i.Body,
+		&i.ReplyCount,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const deleteAuthorBadge = `-- name: DeleteAuthorBadge :exec
+DELETE FROM author_badges WHERE user_id = $1
+`
+
+func (q *Queries) DeleteAuthorBadge(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteAuthorBadge, userID)
+	return err
+}
+
+const deleteComment = `-- name: DeleteComment :exec
+DELETE FROM comments WHERE id = $1
+`
+
+func (q *Queries) DeleteComment(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, deleteComment, id)
+	return err
+}
+
+const deleteDeactivatedAuthor = `-- name: DeleteDeactivatedAuthor :exec
+DELETE FROM deactivated_authors WHERE user_id = $1
+`
+
+func (q *Queries) DeleteDeactivatedAuthor(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteDeactivatedAuthor, userID)
+	return err
+}
+
+const deleteDraft = `-- name: DeleteDraft :one
+DELETE FROM drafts WHERE id = $1 AND user_id = $2
+RETURNING media_ids
+`
+
+type DeleteDraftParams struct {
 	ID     int32 `json:"id"`
 	UserID int32 `json:"userId"`
 }
 
-func (q *Queries) DeletePost(ctx context.Context, arg DeletePostParams) error {
-	_, err := q.db.ExecContext(ctx, deletePost, arg.ID, arg.UserID)
-	return err
+func (q *Queries) DeleteDraft(ctx context.Context, arg DeleteDraftParams) ([]int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteDraft, arg.ID, arg.UserID)
+	var media_ids []int32
+	err := row.Scan(pq.Array(&media_ids))
+	return media_ids, err
 }
 
-const getAll = `-- name: GetAll :many
-SELECT id, user_id, username, body, media_id, created_at FROM posts
+const deleteDraftsByUser = `-- name: DeleteDraftsByUser :many
+DELETE FROM drafts WHERE user_id = $1
+RETURNING media_ids
 `
 
-func (q *Queries) GetAll(ctx context.Context) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getAll)
+func (q *Queries) DeleteDraftsByUser(ctx context.Context, userID int32) ([][]int32, error) {
+	rows, err := q.db.QueryContext(ctx, deleteDraftsByUser, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items [][]int32
 	for rows.Next() {
-		var i Post
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Username,
-			&i.Body,
-			&i.MediaID,
-			&i.CreatedAt,
-		); err != nil {
+		var media_ids []int32
+		if err := rows.Scan(pq.Array(&media_ids)); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, media_ids)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -79,33 +606,59 @@ func (q *Queries) GetAll(ctx context.Context) ([]Post, error) {
 	return items, nil
 }
 
-const getPostPage = `-- name: GetPostPage :many
-SELECT id, user_id, username, body, media_id, created_at FROM posts WHERE user_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3
+const deleteFollow = `-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2
 `
 
-type GetPostPageParams struct {
-	UserID int32 `json:"userId"`
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type DeleteFollowParams struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
 }
 
-func (q *Queries) GetPostPage(ctx context.Context, arg GetPostPageParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostPage, arg.UserID, arg.Limit, arg.Offset)
+func (q *Queries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFollow, arg.FollowerID, arg.FolloweeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFollowerCount = `-- name: DeleteFollowerCount :exec
+DELETE FROM follower_counts WHERE user_id = $1
+`
+
+func (q *Queries) DeleteFollowerCount(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteFollowerCount, userID)
+	return err
+}
+
+const deleteFollowsBetween = `-- name: DeleteFollowsBetween :many
+DELETE FROM follows
+WHERE (follower_id = $1::int AND followee_id = $2::int)
+   OR (follower_id = $2::int AND followee_id = $1::int)
+RETURNING follower_id, followee_id
+`
+
+type DeleteFollowsBetweenParams struct {
+	UserA int32 `json:"userA"`
+	UserB int32 `json:"userB"`
+}
+
+type DeleteFollowsBetweenRow struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) DeleteFollowsBetween(ctx context.Context, arg DeleteFollowsBetweenParams) ([]DeleteFollowsBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteFollowsBetween, arg.UserA, arg.UserB)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Post
+	var items []DeleteFollowsBetweenRow
 	for rows.Next() {
-		var i Post
-		if err := rows.Scan(
-			&i.ID,
-			&i.UserID,
-			&i.Username,
-			&i.Body,
-			&i.MediaID,
-			&i.CreatedAt,
-		); err != nil {
+		var i DeleteFollowsBetweenRow
+		if err := rows.Scan(&i.FollowerID, &i.FolloweeID); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -119,18 +672,2494 @@ func (q *Queries) GetPostPage(ctx context.Context, arg GetPostPageParams) ([]Pos
 	return items, nil
 }
 
-const getPostUserAndMediaId = `-- name: GetPostUserAndMediaId :one
-SELECT user_id, media_id FROM posts WHERE id = $1
+const deleteFollowsByUser = `-- name: DeleteFollowsByUser :many
+DELETE FROM follows
+WHERE follower_id = $1::int OR followee_id = $1::int
+RETURNING follower_id, followee_id
 `
 
-type GetPostUserAndMediaIdRow struct {
-	UserID  int32         `json:"userId"`
-	MediaID sql.NullInt32 `json:"mediaId"`
+type DeleteFollowsByUserRow struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
 }
 
-func (q *Queries) GetPostUserAndMediaId(ctx context.Context, id int32) (GetPostUserAndMediaIdRow, error) {
-	row := q.db.QueryRowContext(ctx, getPostUserAndMediaId, id)
-	var i GetPostUserAndMediaIdRow
-	err := row.Scan(&i.UserID, &i.MediaID)
-	return i, err
+func (q *Queries) DeleteFollowsByUser(ctx context.Context, userID int32) ([]DeleteFollowsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteFollowsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteFollowsByUserRow
+	for rows.Next() {
+		var i DeleteFollowsByUserRow
+		if err := rows.Scan(&i.FollowerID, &i.FolloweeID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteMentionsBetween = `-- name: DeleteMentionsBetween :exec
+DELETE FROM post_mentions
+USING posts
+WHERE posts.id = post_mentions.post_id
+  AND ((posts.user_id = $1::int AND post_mentions.user_id = $2::int)
+    OR (posts.user_id = $2::int AND post_mentions.user_id = $1::int))
+`
+
+type DeleteMentionsBetweenParams struct {
+	UserA int32 `json:"userA"`
+	UserB int32 `json:"userB"`
+}
+
+// a block turns mentions between the two users back into plain text
+func (q *Queries) DeleteMentionsBetween(ctx context.Context, arg DeleteMentionsBetweenParams) error {
+	_, err := q.db.ExecContext(ctx, deleteMentionsBetween, arg.UserA, arg.UserB)
+	return err
+}
+
+const deleteMentionsOfUser = `-- name: DeleteMentionsOfUser :exec
+DELETE FROM post_mentions WHERE user_id = $1
+`
+
+func (q *Queries) DeleteMentionsOfUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteMentionsOfUser, userID)
+	return err
+}
+
+const deletePollVotesByUser = `-- name: DeletePollVotesByUser :many
+DELETE FROM poll_votes WHERE user_id = $1
+RETURNING post_id, positions
+`
+
+type DeletePollVotesByUserRow struct {
+	PostID    int32   `json:"postId"`
+	Positions []int32 `json:"positions"`
+}
+
+func (q *Queries) DeletePollVotesByUser(ctx context.Context, userID int32) ([]DeletePollVotesByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, deletePollVotesByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeletePollVotesByUserRow
+	for rows.Next() {
+		var i DeletePollVotesByUserRow
+		if err := rows.Scan(&i.PostID, pq.Array(&i.Positions)); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deletePost = `-- name: DeletePost :exec
+DELETE FROM posts WHERE id = $1 AND user_id = $2
+`
+
+type DeletePostParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) DeletePost(ctx context.Context, arg DeletePostParams) error {
+	_, err := q.db.ExecContext(ctx, deletePost, arg.ID, arg.UserID)
+	return err
+}
+
+const deletePostHashtags = `-- name: DeletePostHashtags :exec
+DELETE FROM post_hashtags WHERE post_id = $1
+`
+
+func (q *Queries) DeletePostHashtags(ctx context.Context, postID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostHashtags, postID)
+	return err
+}
+
+const deletePostMedia = `-- name: DeletePostMedia :exec
+DELETE FROM post_media WHERE post_id = $1
+`
+
+func (q *Queries) DeletePostMedia(ctx context.Context, postID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostMedia, postID)
+	return err
+}
+
+const deletePostMentions = `-- name: DeletePostMentions :exec
+DELETE FROM post_mentions WHERE post_id = $1
+`
+
+func (q *Queries) DeletePostMentions(ctx context.Context, postID int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostMentions, postID)
+	return err
+}
+
+const deletePostReactionCounts = `-- name: DeletePostReactionCounts :exec
+DELETE FROM post_reaction_counts WHERE post_id = ANY($1::int[])
+`
+
+func (q *Queries) DeletePostReactionCounts(ctx context.Context, postIds []int32) error {
+	_, err := q.db.ExecContext(ctx, deletePostReactionCounts, pq.Array(postIds))
+	return err
+}
+
+const deleteReaction = `-- name: DeleteReaction :execrows
+DELETE FROM reactions WHERE post_id = $1 AND user_id = $2 AND reaction = $3
+`
+
+type DeleteReactionParams struct {
+	PostID   int32  `json:"postId"`
+	UserID   int32  `json:"userId"`
+	Reaction string `json:"reaction"`
+}
+
+func (q *Queries) DeleteReaction(ctx context.Context, arg DeleteReactionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteReaction, arg.PostID, arg.UserID, arg.Reaction)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteRepost = `-- name: DeleteRepost :one
+DELETE FROM posts
+WHERE user_id = $1 AND original_post_id = $2 AND kind = 'repost'
+RETURNING id
+`
+
+type DeleteRepostParams struct {
+	UserID         int32         `json:"userId"`
+	OriginalPostID sql.NullInt32 `json:"originalPostId"`
+}
+
+func (q *Queries) DeleteRepost(ctx context.Context, arg DeleteRepostParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteRepost, arg.UserID, arg.OriginalPostID)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const deleteScheduledPost = `-- name: DeleteScheduledPost :one
+DELETE FROM scheduled_posts
+WHERE id = $1 AND user_id = $2 AND status IN ('scheduled', 'failed')
+RETURNING media_ids
+`
+
+type DeleteScheduledPostParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) DeleteScheduledPost(ctx context.Context, arg DeleteScheduledPostParams) ([]int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteScheduledPost, arg.ID, arg.UserID)
+	var media_ids []int32
+	err := row.Scan(pq.Array(&media_ids))
+	return media_ids, err
+}
+
+const deleteScheduledPostsByUser = `-- name: DeleteScheduledPostsByUser :many
+DELETE FROM scheduled_posts WHERE user_id = $1
+RETURNING status, media_ids
+`
+
+type DeleteScheduledPostsByUserRow struct {
+	Status   string  `json:"status"`
+	MediaIds []int32 `json:"mediaIds"`
+}
+
+func (q *Queries) DeleteScheduledPostsByUser(ctx context.Context, userID int32) ([]DeleteScheduledPostsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteScheduledPostsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteScheduledPostsByUserRow
+	for rows.Next() {
+		var i DeleteScheduledPostsByUserRow
+		if err := rows.Scan(&i.Status, pq.Array(&i.MediaIds)); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteStaleDrafts = `-- name: DeleteStaleDrafts :many
+WITH stale AS (
+    SELECT id FROM drafts
+    WHERE updated_at < $1::timestamp
+    ORDER BY id
+    LIMIT $2::int
+    FOR UPDATE SKIP LOCKED
+)
+DELETE FROM drafts USING stale
+WHERE drafts.id = stale.id
+RETURNING drafts.media_ids
+`
+
+type DeleteStaleDraftsParams struct {
+	UpdatedBefore time.Time `json:"updatedBefore"`
+	BatchSize     int32     `json:"batchSize"`
+}
+
+// abandoned drafts in batches, rows another replica is deleting are skipped
+func (q *Queries) DeleteStaleDrafts(ctx context.Context, arg DeleteStaleDraftsParams) ([][]int32, error) {
+	rows, err := q.db.QueryContext(ctx, deleteStaleDrafts, arg.UpdatedBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items [][]int32
+	for rows.Next() {
+		var media_ids []int32
+		if err := rows.Scan(pq.Array(&media_ids)); err != nil {
+			return nil, err
+		}
+		items = append(items, media_ids)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAll = `-- name: GetAll :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+`
+
+func (q *Queries) GetAll(ctx context.Context) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllCommentsByUserId = `-- name: GetAllCommentsByUserId :many
+SELECT id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at FROM comments WHERE user_id = $1 AND deleted_at IS NULL ORDER BY id
+`
+
+func (q *Queries) GetAllCommentsByUserId(ctx context.Context, userID int32) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCommentsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.ParentCommentID,
+			&i.UserID,
+			&i.Username,
+			&i.AuthorBadge,
+			&i.Body,
+			&i.ReplyCount,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllDraftsByUserId = `-- name: GetAllDraftsByUserId :many
+SELECT id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at FROM drafts WHERE user_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetAllDraftsByUserId(ctx context.Context, userID int32) ([]Draft, error) {
+	rows, err := q.db.QueryContext(ctx, getAllDraftsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Draft
+	for rows.Next() {
+		var i Draft
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Body,
+			&i.Visibility,
+			pq.Array(&i.MediaIds),
+			pq.Array(&i.AltTexts),
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllPollVotesByUserId = `-- name: GetAllPollVotesByUserId :many
+SELECT post_id, user_id, positions, created_at FROM poll_votes WHERE user_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetAllPollVotesByUserId(ctx context.Context, userID int32) ([]PollVote, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPollVotesByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollVote
+	for rows.Next() {
+		var i PollVote
+		if err := rows.Scan(
+			&i.PostID,
+			&i.UserID,
+			pq.Array(&i.Positions),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllPostRevisionsByUserId = `-- name: GetAllPostRevisionsByUserId :many
+SELECT post_revisions.revision_id, post_revisions.post_id, post_revisions.body, post_revisions.media_id, post_revisions.created_at, post_revisions.replaced_at, post_revisions.media_ids FROM post_revisions
+JOIN posts ON posts.id = post_revisions.post_id
+WHERE posts.user_id = $1
+ORDER BY post_revisions.revision_id
+`
+
+func (q *Queries) GetAllPostRevisionsByUserId(ctx context.Context, userID int32) ([]PostRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPostRevisionsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostRevision
+	for rows.Next() {
+		var i PostRevision
+		if err := rows.Scan(
+			&i.RevisionID,
+			&i.PostID,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.ReplacedAt,
+			pq.Array(&i.MediaIds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllPostsByUserId = `-- name: GetAllPostsByUserId :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts WHERE user_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetAllPostsByUserId(ctx context.Context, userID int32) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getAllPostsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllReactionsByUserId = `-- name: GetAllReactionsByUserId :many
+SELECT post_id, user_id, reaction, created_at FROM reactions WHERE user_id = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetAllReactionsByUserId(ctx context.Context, userID int32) ([]Reaction, error) {
+	rows, err := q.db.QueryContext(ctx, getAllReactionsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reaction
+	for rows.Next() {
+		var i Reaction
+		if err := rows.Scan(
+			&i.PostID,
+			&i.UserID,
+			&i.Reaction,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllScheduledPostsByUserId = `-- name: GetAllScheduledPostsByUserId :many
+SELECT id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at FROM scheduled_posts WHERE user_id = $1 ORDER BY id
+`
+
+func (q *Queries) GetAllScheduledPostsByUserId(ctx context.Context, userID int32) ([]ScheduledPost, error) {
+	rows, err := q.db.QueryContext(ctx, getAllScheduledPostsByUserId, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduledPost
+	for rows.Next() {
+		var i ScheduledPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.Visibility,
+			pq.Array(&i.MediaIds),
+			pq.Array(&i.AltTexts),
+			&i.PublishAt,
+			&i.Status,
+			&i.PostID,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getClaimedMediaIds = `-- name: GetClaimedMediaIds :many
+SELECT m.id::int FROM unnest($1::int[]) AS m(id)
+WHERE EXISTS (SELECT 1 FROM post_media pm WHERE pm.media_id = m.id)
+   OR EXISTS (SELECT 1 FROM post_revisions r WHERE r.media_ids @> ARRAY[m.id])
+   OR EXISTS (SELECT 1 FROM drafts d WHERE d.media_ids @> ARRAY[m.id])
+   OR EXISTS (SELECT 1 FROM scheduled_posts s WHERE s.media_ids @> ARRAY[m.id])
+ORDER BY m.id
+`
+
+// the media ids a post, an earlier revision, a draft or a scheduled post
+// holds on to
+func (q *Queries) GetClaimedMediaIds(ctx context.Context, mediaIds []int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getClaimedMediaIds, pq.Array(mediaIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var m_id int32
+		if err := rows.Scan(&m_id); err != nil {
+			return nil, err
+		}
+		items = append(items, m_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCommentForUpdate = `-- name: GetCommentForUpdate :one
+SELECT id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at FROM comments WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetCommentForUpdate(ctx context.Context, id int32) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, getCommentForUpdate, id)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.ParentCommentID,
+		&i.UserID,
+		&i.Username,
+		&i.AuthorBadge,
+		&i.Body,
+		&i.ReplyCount,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getCommentPage = `-- name: GetCommentPage :many
+SELECT id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at FROM comments
+WHERE comments.post_id = $1
+  AND comments.parent_comment_id IS NULL
+  AND comments.id > $3::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = comments.user_id)
+ORDER BY comments.id
+LIMIT $2
+`
+
+type GetCommentPageParams struct {
+	PostID  int32 `json:"postId"`
+	Limit   int32 `json:"limit"`
+	AfterID int32 `json:"afterId"`
+}
+
+func (q *Queries) GetCommentPage(ctx context.Context, arg GetCommentPageParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, getCommentPage, arg.PostID, arg.Limit, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.ParentCommentID,
+			&i.UserID,
+			&i.Username,
+			&i.AuthorBadge,
+			&i.Body,
+			&i.ReplyCount,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDraft = `-- name: GetDraft :one
+SELECT id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at FROM drafts WHERE id = $1 AND user_id = $2
+`
+
+type GetDraftParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) GetDraft(ctx context.Context, arg GetDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraft, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDraftForUpdate = `-- name: GetDraftForUpdate :one
+SELECT id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at FROM drafts WHERE id = $1 AND user_id = $2 FOR UPDATE
+`
+
+type GetDraftForUpdateParams struct {
+	ID     int32 `json:"id"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) GetDraftForUpdate(ctx context.Context, arg GetDraftForUpdateParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraftForUpdate, arg.ID, arg.UserID)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDraftsByUser = `-- name: GetDraftsByUser :many
+SELECT id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at FROM drafts WHERE user_id = $1 ORDER BY updated_at DESC, id DESC
+`
+
+func (q *Queries) GetDraftsByUser(ctx context.Context, userID int32) ([]Draft, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Draft
+	for rows.Next() {
+		var i Draft
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Body,
+			&i.Visibility,
+			pq.Array(&i.MediaIds),
+			pq.Array(&i.AltTexts),
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFanoutOnReadPostIds = `-- name: GetFanoutOnReadPostIds :many
+SELECT posts.id FROM posts
+WHERE posts.user_id IN (
+        SELECT f.followee_id FROM follows f
+        JOIN follower_counts c ON c.user_id = f.followee_id
+        WHERE f.follower_id = $1::int AND c.count > $2::int
+      )
+  AND posts.visibility IN ('public', 'followers')
+  AND posts.id < $3::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT $4::int
+`
+
+type GetFanoutOnReadPostIdsParams struct {
+	ViewerID    int32 `json:"viewerId"`
+	FanoutLimit int32 `json:"fanoutLimit"`
+	BeforeID    int32 `json:"beforeId"`
+	PageLimit   int32 `json:"pageLimit"`
+}
+
+// posts of followed accounts too big to fan out on write
+func (q *Queries) GetFanoutOnReadPostIds(ctx context.Context, arg GetFanoutOnReadPostIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getFanoutOnReadPostIds,
+		arg.ViewerID,
+		arg.FanoutLimit,
+		arg.BeforeID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowerCount = `-- name: GetFollowerCount :one
+SELECT COALESCE((SELECT count FROM follower_counts WHERE user_id = $1), 0)::int
+`
+
+func (q *Queries) GetFollowerCount(ctx context.Context, userID int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getFollowerCount, userID)
+	var column_1 int32
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getFollowerIdsPage = `-- name: GetFollowerIdsPage :many
+SELECT follower_id FROM follows
+WHERE followee_id = $1 AND follower_id > $3::int
+ORDER BY follower_id
+LIMIT $2
+`
+
+type GetFollowerIdsPageParams struct {
+	FolloweeID int32 `json:"followeeId"`
+	Limit      int32 `json:"limit"`
+	AfterID    int32 `json:"afterId"`
+}
+
+func (q *Queries) GetFollowerIdsPage(ctx context.Context, arg GetFollowerIdsPageParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowerIdsPage, arg.FolloweeID, arg.Limit, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var follower_id int32
+		if err := rows.Scan(&follower_id); err != nil {
+			return nil, err
+		}
+		items = append(items, follower_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowsBackfill = `-- name: GetFollowsBackfill :one
+SELECT id, after_follower_id, after_followee_id, completed_at FROM follows_backfill WHERE id = 1
+`
+
+func (q *Queries) GetFollowsBackfill(ctx context.Context) (FollowsBackfill, error) {
+	row := q.db.QueryRowContext(ctx, getFollowsBackfill)
+	var i FollowsBackfill
+	err := row.Scan(
+		&i.ID,
+		&i.AfterFollowerID,
+		&i.AfterFolloweeID,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getFollowsBackfillForUpdate = `-- name: GetFollowsBackfillForUpdate :one
+SELECT id, after_follower_id, after_followee_id, completed_at FROM follows_backfill WHERE id = 1 FOR UPDATE
+`
+
+func (q *Queries) GetFollowsBackfillForUpdate(ctx context.Context) (FollowsBackfill, error) {
+	row := q.db.QueryRowContext(ctx, getFollowsBackfillForUpdate)
+	var i FollowsBackfill
+	err := row.Scan(
+		&i.ID,
+		&i.AfterFollowerID,
+		&i.AfterFolloweeID,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getHashtagPostPage = `-- name: GetHashtagPostPage :many
+SELECT posts.id, posts.user_id, posts.username, posts.body, posts.media_id, posts.created_at, posts.author_badge, posts.comment_count, posts.kind, posts.original_post_id, posts.repost_count, posts.quote_count, posts.edited_at, posts.visibility FROM posts
+JOIN post_hashtags ON post_hashtags.post_id = posts.id
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE hashtags.tag = $1::text
+  AND posts.id < $2::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT $3::int
+`
+
+type GetHashtagPostPageParams struct {
+	Tag       string `json:"tag"`
+	BeforeID  int32  `json:"beforeId"`
+	PageLimit int32  `json:"pageLimit"`
+}
+
+func (q *Queries) GetHashtagPostPage(ctx context.Context, arg GetHashtagPostPageParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getHashtagPostPage, arg.Tag, arg.BeforeID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMentionsByPostIds = `-- name: GetMentionsByPostIds :many
+SELECT post_id, user_id, username, start_offset, end_offset FROM post_mentions
+WHERE post_id = ANY($1::int[])
+ORDER BY post_id, start_offset
+`
+
+func (q *Queries) GetMentionsByPostIds(ctx context.Context, postIds []int32) ([]PostMention, error) {
+	rows, err := q.db.QueryContext(ctx, getMentionsByPostIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostMention
+	for rows.Next() {
+		var i PostMention
+		if err := rows.Scan(
+			&i.PostID,
+			&i.UserID,
+			&i.Username,
+			&i.StartOffset,
+			&i.EndOffset,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getNextDueScheduledPost = `-- name: GetNextDueScheduledPost :one
+SELECT id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at FROM scheduled_posts
+WHERE status = 'scheduled' AND publish_at <= NOW()
+  AND NOT (id = ANY($1::int[]))
+ORDER BY publish_at, id
+LIMIT 1
+FOR UPDATE SKIP LOCKED
+`
+
+// the oldest due post. Run outside a transaction the lock is released right
+// away, it only skips rows another replica is publishing
+func (q *Queries) GetNextDueScheduledPost(ctx context.Context, skipIds []int32) (ScheduledPost, error) {
+	row := q.db.QueryRowContext(ctx, getNextDueScheduledPost, pq.Array(skipIds))
+	var i ScheduledPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.PublishAt,
+		&i.Status,
+		&i.PostID,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPendingScheduledPosts = `-- name: GetPendingScheduledPosts :many
+SELECT id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at FROM scheduled_posts
+WHERE user_id = $1 AND status IN ('scheduled', 'failed')
+ORDER BY publish_at, id
+`
+
+func (q *Queries) GetPendingScheduledPosts(ctx context.Context, userID int32) ([]ScheduledPost, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingScheduledPosts, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduledPost
+	for rows.Next() {
+		var i ScheduledPost
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.Visibility,
+			pq.Array(&i.MediaIds),
+			pq.Array(&i.AltTexts),
+			&i.PublishAt,
+			&i.Status,
+			&i.PostID,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollForUpdate = `-- name: GetPollForUpdate :one
+SELECT post_id, multiple_choice, ends_at, closed_at, voter_count FROM polls WHERE post_id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetPollForUpdate(ctx context.Context, postID int32) (Poll, error) {
+	row := q.db.QueryRowContext(ctx, getPollForUpdate, postID)
+	var i Poll
+	err := row.Scan(
+		&i.PostID,
+		&i.MultipleChoice,
+		&i.EndsAt,
+		&i.ClosedAt,
+		&i.VoterCount,
+	)
+	return i, err
+}
+
+const getPollOptions = `-- name: GetPollOptions :many
+SELECT post_id, position, text, vote_count FROM poll_options WHERE post_id = $1 ORDER BY position
+`
+
+func (q *Queries) GetPollOptions(ctx context.Context, postID int32) ([]PollOption, error) {
+	rows, err := q.db.QueryContext(ctx, getPollOptions, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.PostID,
+			&i.Position,
+			&i.Text,
+			&i.VoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollOptionsByPostIds = `-- name: GetPollOptionsByPostIds :many
+SELECT post_id, position, text, vote_count FROM poll_options
+WHERE post_id = ANY($1::int[])
+ORDER BY post_id, position
+`
+
+func (q *Queries) GetPollOptionsByPostIds(ctx context.Context, postIds []int32) ([]PollOption, error) {
+	rows, err := q.db.QueryContext(ctx, getPollOptionsByPostIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollOption
+	for rows.Next() {
+		var i PollOption
+		if err := rows.Scan(
+			&i.PostID,
+			&i.Position,
+			&i.Text,
+			&i.VoteCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPollsByPostIds = `-- name: GetPollsByPostIds :many
+SELECT post_id, multiple_choice, ends_at, closed_at, voter_count FROM polls WHERE post_id = ANY($1::int[])
+`
+
+func (q *Queries) GetPollsByPostIds(ctx context.Context, postIds []int32) ([]Poll, error) {
+	rows, err := q.db.QueryContext(ctx, getPollsByPostIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Poll
+	for rows.Next() {
+		var i Poll
+		if err := rows.Scan(
+			&i.PostID,
+			&i.MultipleChoice,
+			&i.EndsAt,
+			&i.ClosedAt,
+			&i.VoterCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostForUpdate = `-- name: GetPostForUpdate :one
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetPostForUpdate(ctx context.Context, id int32) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUpdate, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.MediaID,
+		&i.CreatedAt,
+		&i.AuthorBadge,
+		&i.CommentCount,
+		&i.Kind,
+		&i.OriginalPostID,
+		&i.RepostCount,
+		&i.QuoteCount,
+		&i.EditedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getPostHashtags = `-- name: GetPostHashtags :many
+SELECT hashtags.tag FROM post_hashtags
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE post_hashtags.post_id = $1
+`
+
+func (q *Queries) GetPostHashtags(ctx context.Context, postID int32) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getPostHashtags, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostMediaByPostIds = `-- name: GetPostMediaByPostIds :many
+SELECT post_id, position, media_id, alt_text FROM post_media
+WHERE post_id = ANY($1::int[])
+ORDER BY post_id, position
+`
+
+func (q *Queries) GetPostMediaByPostIds(ctx context.Context, postIds []int32) ([]PostMedium, error) {
+	rows, err := q.db.QueryContext(ctx, getPostMediaByPostIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostMedium
+	for rows.Next() {
+		var i PostMedium
+		if err := rows.Scan(
+			&i.PostID,
+			&i.Position,
+			&i.MediaID,
+			&i.AltText,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostMediaIds = `-- name: GetPostMediaIds :many
+SELECT media_id FROM post_media WHERE post_id = $1 ORDER BY position
+`
+
+func (q *Queries) GetPostMediaIds(ctx context.Context, postID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getPostMediaIds, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var media_id int32
+		if err := rows.Scan(&media_id); err != nil {
+			return nil, err
+		}
+		items = append(items, media_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostMentionedUserIds = `-- name: GetPostMentionedUserIds :many
+SELECT DISTINCT user_id FROM post_mentions WHERE post_id = $1
+`
+
+func (q *Queries) GetPostMentionedUserIds(ctx context.Context, postID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getPostMentionedUserIds, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var user_id int32
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostPage = `-- name: GetPostPage :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE posts.user_id = $1
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = $4::int
+       OR (posts.visibility = 'followers' AND $5::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = $4::int
+       )))
+ORDER BY id DESC LIMIT $2 OFFSET $3
+`
+
+type GetPostPageParams struct {
+	UserID        int32 `json:"userId"`
+	Limit         int32 `json:"limit"`
+	Offset        int32 `json:"offset"`
+	ViewerID      int32 `json:"viewerId"`
+	ViewerFollows bool  `json:"viewerFollows"`
+}
+
+// the user's posts the viewer can see, viewer_follows says whether the viewer
+// follows the user
+func (q *Queries) GetPostPage(ctx context.Context, arg GetPostPageParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostPage,
+		arg.UserID,
+		arg.Limit,
+		arg.Offset,
+		arg.ViewerID,
+		arg.ViewerFollows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostPageAfter = `-- name: GetPostPageAfter :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE posts.user_id = $1
+  AND (posts.created_at, posts.id) < ($3::timestamp, $4::int)
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = $5::int
+       OR (posts.visibility = 'followers' AND $6::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = $5::int
+       )))
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT $2
+`
+
+type GetPostPageAfterParams struct {
+	UserID          int32     `json:"userId"`
+	Limit           int32     `json:"limit"`
+	CursorCreatedAt time.Time `json:"cursorCreatedAt"`
+	CursorID        int32     `json:"cursorId"`
+	ViewerID        int32     `json:"viewerId"`
+	ViewerFollows   bool      `json:"viewerFollows"`
+}
+
+// posts older than the cursor, newest first
+func (q *Queries) GetPostPageAfter(ctx context.Context, arg GetPostPageAfterParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostPageAfter,
+		arg.UserID,
+		arg.Limit,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.ViewerFollows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostPageBefore = `-- name: GetPostPageBefore :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE posts.user_id = $1
+  AND (posts.created_at, posts.id) > ($3::timestamp, $4::int)
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = $5::int
+       OR (posts.visibility = 'followers' AND $6::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = $5::int
+       )))
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $2
+`
+
+type GetPostPageBeforeParams struct {
+	UserID          int32     `json:"userId"`
+	Limit           int32     `json:"limit"`
+	CursorCreatedAt time.Time `json:"cursorCreatedAt"`
+	CursorID        int32     `json:"cursorId"`
+	ViewerID        int32     `json:"viewerId"`
+	ViewerFollows   bool      `json:"viewerFollows"`
+}
+
+// posts newer than the cursor, oldest first
+func (q *Queries) GetPostPageBefore(ctx context.Context, arg GetPostPageBeforeParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostPageBefore,
+		arg.UserID,
+		arg.Limit,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.ViewerID,
+		arg.ViewerFollows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostRevisionMediaIds = `-- name: GetPostRevisionMediaIds :many
+SELECT DISTINCT unnest(media_ids)::int FROM post_revisions WHERE post_id = $1
+`
+
+func (q *Queries) GetPostRevisionMediaIds(ctx context.Context, postID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRevisionMediaIds, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var column_1 int32
+		if err := rows.Scan(&column_1); err != nil {
+			return nil, err
+		}
+		items = append(items, column_1)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostRevisions = `-- name: GetPostRevisions :many
+SELECT revision_id, post_id, body, media_id, created_at, replaced_at, media_ids FROM post_revisions WHERE post_id = $1 ORDER BY revision_id DESC
+`
+
+func (q *Queries) GetPostRevisions(ctx context.Context, postID int32) ([]PostRevision, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRevisions, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostRevision
+	for rows.Next() {
+		var i PostRevision
+		if err := rows.Scan(
+			&i.RevisionID,
+			&i.PostID,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.ReplacedAt,
+			pq.Array(&i.MediaIds),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostUserAndMediaId = `-- name: GetPostUserAndMediaId :one
+SELECT user_id, media_id, kind, original_post_id FROM posts WHERE id = $1
+`
+
+type GetPostUserAndMediaIdRow struct {
+	UserID         int32         `json:"userId"`
+	MediaID        sql.NullInt32 `json:"mediaId"`
+	Kind           string        `json:"kind"`
+	OriginalPostID sql.NullInt32 `json:"originalPostId"`
+}
+
+func (q *Queries) GetPostUserAndMediaId(ctx context.Context, id int32) (GetPostUserAndMediaIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getPostUserAndMediaId, id)
+	var i GetPostUserAndMediaIdRow
+	err := row.Scan(
+		&i.UserID,
+		&i.MediaID,
+		&i.Kind,
+		&i.OriginalPostID,
+	)
+	return i, err
+}
+
+const getPostsByIds = `-- name: GetPostsByIds :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE id = ANY($1::int[])
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+`
+
+func (q *Queries) GetPostsByIds(ctx context.Context, postIds []int32) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsByMediaId = `-- name: GetPostsByMediaId :many
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE (EXISTS (SELECT 1 FROM post_media m WHERE m.post_id = posts.id AND m.media_id = $1::int)
+    OR EXISTS (SELECT 1 FROM post_revisions r WHERE r.post_id = posts.id AND r.media_ids @> ARRAY[$1::int]))
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+`
+
+// posts showing the media as an attachment now or in an earlier revision
+func (q *Queries) GetPostsByMediaId(ctx context.Context, mediaID int32) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsByMediaId, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Username,
+			&i.Body,
+			&i.MediaID,
+			&i.CreatedAt,
+			&i.AuthorBadge,
+			&i.CommentCount,
+			&i.Kind,
+			&i.OriginalPostID,
+			&i.RepostCount,
+			&i.QuoteCount,
+			&i.EditedAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReactionCountsByPostIds = `-- name: GetReactionCountsByPostIds :many
+SELECT post_id, reaction, count FROM post_reaction_counts
+WHERE post_id = ANY($1::int[])
+`
+
+func (q *Queries) GetReactionCountsByPostIds(ctx context.Context, postIds []int32) ([]PostReactionCount, error) {
+	rows, err := q.db.QueryContext(ctx, getReactionCountsByPostIds, pq.Array(postIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PostReactionCount
+	for rows.Next() {
+		var i PostReactionCount
+		if err := rows.Scan(&i.PostID, &i.Reaction, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReactors = `-- name: GetReactors :many
+SELECT user_id, reaction, created_at FROM reactions
+WHERE post_id = $1::int
+  AND ($2::text = '' OR reaction = $2::text)
+  AND (created_at, user_id) < ($3::timestamp, $4::int)
+ORDER BY created_at DESC, user_id DESC
+LIMIT $5::int
+`
+
+type GetReactorsParams struct {
+	PostID          int32     `json:"postId"`
+	Reaction        string    `json:"reaction"`
+	BeforeCreatedAt time.Time `json:"beforeCreatedAt"`
+	BeforeUserID    int32     `json:"beforeUserId"`
+	PageLimit       int32     `json:"pageLimit"`
+}
+
+type GetReactorsRow struct {
+	UserID    int32     `json:"userId"`
+	Reaction  string    `json:"reaction"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (q *Queries) GetReactors(ctx context.Context, arg GetReactorsParams) ([]GetReactorsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getReactors,
+		arg.PostID,
+		arg.Reaction,
+		arg.BeforeCreatedAt,
+		arg.BeforeUserID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReactorsRow
+	for rows.Next() {
+		var i GetReactorsRow
+		if err := rows.Scan(&i.UserID, &i.Reaction, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentHashtagUsesByUser = `-- name: GetRecentHashtagUsesByUser :many
+SELECT hashtags.tag, posts.created_at FROM posts
+JOIN post_hashtags ON post_hashtags.post_id = posts.id
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE posts.user_id = $1 AND posts.visibility = 'public'
+  AND posts.created_at > $2::timestamp
+`
+
+type GetRecentHashtagUsesByUserParams struct {
+	UserID int32     `json:"userId"`
+	Since  time.Time `json:"since"`
+}
+
+type GetRecentHashtagUsesByUserRow struct {
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// tags on the user's posts still inside the trending window
+func (q *Queries) GetRecentHashtagUsesByUser(ctx context.Context, arg GetRecentHashtagUsesByUserParams) ([]GetRecentHashtagUsesByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentHashtagUsesByUser, arg.UserID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentHashtagUsesByUserRow
+	for rows.Next() {
+		var i GetRecentHashtagUsesByUserRow
+		if err := rows.Scan(&i.Tag, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecentPostIdsByUser = `-- name: GetRecentPostIdsByUser :many
+SELECT id FROM posts
+WHERE user_id = $1 AND visibility IN ('public', 'followers')
+ORDER BY id DESC LIMIT $2
+`
+
+type GetRecentPostIdsByUserParams struct {
+	UserID int32 `json:"userId"`
+	Limit  int32 `json:"limit"`
+}
+
+// the user's recent posts that go on follower timelines
+func (q *Queries) GetRecentPostIdsByUser(ctx context.Context, arg GetRecentPostIdsByUserParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentPostIdsByUser, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getReplyPage = `-- name: GetReplyPage :many
+SELECT id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at FROM comments
+WHERE comments.post_id = $1
+  AND comments.parent_comment_id = $2
+  AND comments.id > $4::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = comments.user_id)
+ORDER BY comments.id
+LIMIT $3
+`
+
+type GetReplyPageParams struct {
+	PostID          int32         `json:"postId"`
+	ParentCommentID sql.NullInt32 `json:"parentCommentId"`
+	Limit           int32         `json:"limit"`
+	AfterID         int32         `json:"afterId"`
+}
+
+func (q *Queries) GetReplyPage(ctx context.Context, arg GetReplyPageParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, getReplyPage,
+		arg.PostID,
+		arg.ParentCommentID,
+		arg.Limit,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Comment
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.PostID,
+			&i.ParentCommentID,
+			&i.UserID,
+			&i.Username,
+			&i.AuthorBadge,
+			&i.Body,
+			&i.ReplyCount,
+			&i.CreatedAt,
+			&i.EditedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimelinePostIds = `-- name: GetTimelinePostIds :many
+SELECT posts.id FROM posts
+WHERE (posts.user_id = $1::int
+       OR (posts.user_id IN (SELECT followee_id FROM follows WHERE follower_id = $1::int)
+           AND posts.visibility IN ('public', 'followers')))
+  AND posts.id < $2::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT $3::int
+`
+
+type GetTimelinePostIdsParams struct {
+	ViewerID  int32 `json:"viewerId"`
+	BeforeID  int32 `json:"beforeId"`
+	PageLimit int32 `json:"pageLimit"`
+}
+
+func (q *Queries) GetTimelinePostIds(ctx context.Context, arg GetTimelinePostIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getTimelinePostIds, arg.ViewerID, arg.BeforeID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getViewerMentionedPostIds = `-- name: GetViewerMentionedPostIds :many
+SELECT DISTINCT post_id FROM post_mentions
+WHERE user_id = $1::int AND post_id = ANY($2::int[])
+`
+
+type GetViewerMentionedPostIdsParams struct {
+	ViewerID int32   `json:"viewerId"`
+	PostIds  []int32 `json:"postIds"`
+}
+
+func (q *Queries) GetViewerMentionedPostIds(ctx context.Context, arg GetViewerMentionedPostIdsParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getViewerMentionedPostIds, arg.ViewerID, pq.Array(arg.PostIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var post_id int32
+		if err := rows.Scan(&post_id); err != nil {
+			return nil, err
+		}
+		items = append(items, post_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getViewerPollVotes = `-- name: GetViewerPollVotes :many
+SELECT post_id, user_id, positions, created_at FROM poll_votes
+WHERE user_id = $1::int AND post_id = ANY($2::int[])
+`
+
+type GetViewerPollVotesParams struct {
+	ViewerID int32   `json:"viewerId"`
+	PostIds  []int32 `json:"postIds"`
+}
+
+func (q *Queries) GetViewerPollVotes(ctx context.Context, arg GetViewerPollVotesParams) ([]PollVote, error) {
+	rows, err := q.db.QueryContext(ctx, getViewerPollVotes, arg.ViewerID, pq.Array(arg.PostIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PollVote
+	for rows.Next() {
+		var i PollVote
+		if err := rows.Scan(
+			&i.PostID,
+			&i.UserID,
+			pq.Array(&i.Positions),
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getViewerReactions = `-- name: GetViewerReactions :many
+SELECT post_id, reaction FROM reactions
+WHERE user_id = $1::int AND post_id = ANY($2::int[])
+`
+
+type GetViewerReactionsParams struct {
+	UserID  int32   `json:"userId"`
+	PostIds []int32 `json:"postIds"`
+}
+
+type GetViewerReactionsRow struct {
+	PostID   int32  `json:"postId"`
+	Reaction string `json:"reaction"`
+}
+
+func (q *Queries) GetViewerReactions(ctx context.Context, arg GetViewerReactionsParams) ([]GetViewerReactionsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getViewerReactions, arg.UserID, pq.Array(arg.PostIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetViewerReactionsRow
+	for rows.Next() {
+		var i GetViewerReactionsRow
+		if err := rows.Scan(&i.PostID, &i.Reaction); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVisiblePost = `-- name: GetVisiblePost :one
+SELECT id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility FROM posts
+WHERE id = $1
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+`
+
+func (q *Queries) GetVisiblePost(ctx context.Context, id int32) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getVisiblePost, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.MediaID,
+		&i.CreatedAt,
+		&i.AuthorBadge,
+		&i.CommentCount,
+		&i.Kind,
+		&i.OriginalPostID,
+		&i.RepostCount,
+		&i.QuoteCount,
+		&i.EditedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const markPollClosed = `-- name: MarkPollClosed :exec
+UPDATE polls SET closed_at = NOW() WHERE post_id = $1
+`
+
+func (q *Queries) MarkPollClosed(ctx context.Context, postID int32) error {
+	_, err := q.db.ExecContext(ctx, markPollClosed, postID)
+	return err
+}
+
+const markScheduledPostPublished = `-- name: MarkScheduledPostPublished :exec
+UPDATE scheduled_posts SET status = 'published', post_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type MarkScheduledPostPublishedParams struct {
+	ID     int32         `json:"id"`
+	PostID sql.NullInt32 `json:"postId"`
+}
+
+func (q *Queries) MarkScheduledPostPublished(ctx context.Context, arg MarkScheduledPostPublishedParams) error {
+	_, err := q.db.ExecContext(ctx, markScheduledPostPublished, arg.ID, arg.PostID)
+	return err
+}
+
+const recordScheduledPostFailure = `-- name: RecordScheduledPostFailure :exec
+UPDATE scheduled_posts
+SET attempts = attempts + 1,
+    last_error = $2,
+    status = CASE WHEN attempts + 1 >= $3::int THEN 'failed' ELSE status END,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled'
+`
+
+type RecordScheduledPostFailureParams struct {
+	ID          int32  `json:"id"`
+	LastError   string `json:"lastError"`
+	MaxAttempts int32  `json:"maxAttempts"`
+}
+
+func (q *Queries) RecordScheduledPostFailure(ctx context.Context, arg RecordScheduledPostFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordScheduledPostFailure, arg.ID, arg.LastError, arg.MaxAttempts)
+	return err
+}
+
+const recountPostReactions = `-- name: RecountPostReactions :exec
+INSERT INTO post_reaction_counts(post_id, reaction, count)
+SELECT post_id, reaction, COUNT(*)
+FROM reactions
+WHERE post_id = ANY($1::int[])
+GROUP BY post_id, reaction
+`
+
+func (q *Queries) RecountPostReactions(ctx context.Context, postIds []int32) error {
+	_, err := q.db.ExecContext(ctx, recountPostReactions, pq.Array(postIds))
+	return err
+}
+
+const reschedulePost = `-- name: ReschedulePost :one
+UPDATE scheduled_posts
+SET publish_at = $3, status = 'scheduled', attempts = 0, last_error = '', updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND status IN ('scheduled', 'failed')
+RETURNING id, user_id, username, body, visibility, media_ids, alt_texts, publish_at, status, post_id, attempts, last_error, created_at, updated_at
+`
+
+type ReschedulePostParams struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"userId"`
+	PublishAt time.Time `json:"publishAt"`
+}
+
+// moving a failed post puts it back in line with fresh attempts
+func (q *Queries) ReschedulePost(ctx context.Context, arg ReschedulePostParams) (ScheduledPost, error) {
+	row := q.db.QueryRowContext(ctx, reschedulePost, arg.ID, arg.UserID, arg.PublishAt)
+	var i ScheduledPost
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.PublishAt,
+		&i.Status,
+		&i.PostID,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const softDeleteComment = `-- name: SoftDeleteComment :exec
+UPDATE comments SET body = '', deleted_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) SoftDeleteComment(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, softDeleteComment, id)
+	return err
+}
+
+const updateCommentBody = `-- name: UpdateCommentBody :one
+UPDATE comments SET body = $2, edited_at = NOW()
+WHERE id = $1
+RETURNING id, post_id, parent_comment_id, user_id, username, author_badge, body, reply_count, created_at, edited_at, deleted_at
+`
+
+type UpdateCommentBodyParams struct {
+	ID   int32  `json:"id"`
+	Body string `json:"body"`
+}
+
+func (q *Queries) UpdateCommentBody(ctx context.Context, arg UpdateCommentBodyParams) (Comment, error) {
+	row := q.db.QueryRowContext(ctx, updateCommentBody, arg.ID, arg.Body)
+	var i Comment
+	err := row.Scan(
+		&i.ID,
+		&i.PostID,
+		&i.ParentCommentID,
+		&i.UserID,
+		&i.Username,
+		&i.AuthorBadge,
+		&i.Body,
+		&i.ReplyCount,
+		&i.CreatedAt,
+		&i.EditedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateCommentReplyCount = `-- name: UpdateCommentReplyCount :exec
+UPDATE comments SET reply_count = reply_count + $2::int WHERE id = $1
+`
+
+type UpdateCommentReplyCountParams struct {
+	ID    int32 `json:"id"`
+	Delta int32 `json:"delta"`
+}
+
+func (q *Queries) UpdateCommentReplyCount(ctx context.Context, arg UpdateCommentReplyCountParams) error {
+	_, err := q.db.ExecContext(ctx, updateCommentReplyCount, arg.ID, arg.Delta)
+	return err
+}
+
+const updateCommentsAuthorBadge = `-- name: UpdateCommentsAuthorBadge :exec
+UPDATE comments SET author_badge = $2 WHERE user_id = $1
+`
+
+type UpdateCommentsAuthorBadgeParams struct {
+	UserID      int32  `json:"userId"`
+	AuthorBadge string `json:"authorBadge"`
+}
+
+func (q *Queries) UpdateCommentsAuthorBadge(ctx context.Context, arg UpdateCommentsAuthorBadgeParams) error {
+	_, err := q.db.ExecContext(ctx, updateCommentsAuthorBadge, arg.UserID, arg.AuthorBadge)
+	return err
+}
+
+const updateDraft = `-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $2, visibility = $3, media_ids = $4::int[], alt_texts = $5::text[],
+    version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, body, visibility, media_ids, alt_texts, version, created_at, updated_at
+`
+
+type UpdateDraftParams struct {
+	ID         int32    `json:"id"`
+	Body       string   `json:"body"`
+	Visibility string   `json:"visibility"`
+	MediaIds   []int32  `json:"mediaIds"`
+	AltTexts   []string `json:"altTexts"`
+}
+
+func (q *Queries) UpdateDraft(ctx context.Context, arg UpdateDraftParams) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, updateDraft,
+		arg.ID,
+		arg.Body,
+		arg.Visibility,
+		pq.Array(arg.MediaIds),
+		pq.Array(arg.AltTexts),
+	)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Body,
+		&i.Visibility,
+		pq.Array(&i.MediaIds),
+		pq.Array(&i.AltTexts),
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateFollowerCount = `-- name: UpdateFollowerCount :exec
+INSERT INTO follower_counts(user_id, count) VALUES ($1, GREATEST($2::int, 0))
+ON CONFLICT (user_id) DO UPDATE SET count = GREATEST(follower_counts.count + $2::int, 0)
+`
+
+type UpdateFollowerCountParams struct {
+	UserID int32 `json:"userId"`
+	Delta  int32 `json:"delta"`
+}
+
+func (q *Queries) UpdateFollowerCount(ctx context.Context, arg UpdateFollowerCountParams) error {
+	_, err := q.db.ExecContext(ctx, updateFollowerCount, arg.UserID, arg.Delta)
+	return err
+}
+
+const updateFollowsBackfill = `-- name: UpdateFollowsBackfill :exec
+UPDATE follows_backfill
+SET after_follower_id = $1,
+    after_followee_id = $2,
+    completed_at = CASE WHEN $3::boolean THEN NOW() END
+WHERE id = 1
+`
+
+type UpdateFollowsBackfillParams struct {
+	AfterFollowerID int32 `json:"afterFollowerId"`
+	AfterFolloweeID int32 `json:"afterFolloweeId"`
+	Completed       bool  `json:"completed"`
+}
+
+func (q *Queries) UpdateFollowsBackfill(ctx context.Context, arg UpdateFollowsBackfillParams) error {
+	_, err := q.db.ExecContext(ctx, updateFollowsBackfill, arg.AfterFollowerID, arg.AfterFolloweeID, arg.Completed)
+	return err
+}
+
+const updatePollOptionVoteCounts = `-- name: UpdatePollOptionVoteCounts :exec
+UPDATE poll_options SET vote_count = GREATEST(vote_count + $2::int, 0)
+WHERE post_id = $1 AND position = ANY($3::int[])
+`
+
+type UpdatePollOptionVoteCountsParams struct {
+	PostID    int32   `json:"postId"`
+	Delta     int32   `json:"delta"`
+	Positions []int32 `json:"positions"`
+}
+
+func (q *Queries) UpdatePollOptionVoteCounts(ctx context.Context, arg UpdatePollOptionVoteCountsParams) error {
+	_, err := q.db.ExecContext(ctx, updatePollOptionVoteCounts, arg.PostID, arg.Delta, pq.Array(arg.Positions))
+	return err
+}
+
+const updatePollVoterCount = `-- name: UpdatePollVoterCount :exec
+UPDATE polls SET voter_count = GREATEST(voter_count + $2::int, 0)
+WHERE post_id = $1
+`
+
+type UpdatePollVoterCountParams struct {
+	PostID int32 `json:"postId"`
+	Delta  int32 `json:"delta"`
+}
+
+func (q *Queries) UpdatePollVoterCount(ctx context.Context, arg UpdatePollVoterCountParams) error {
+	_, err := q.db.ExecContext(ctx, updatePollVoterCount, arg.PostID, arg.Delta)
+	return err
+}
+
+const updatePostCommentCount = `-- name: UpdatePostCommentCount :exec
+UPDATE posts SET comment_count = comment_count + $2::int WHERE id = $1
+`
+
+type UpdatePostCommentCountParams struct {
+	ID    int32 `json:"id"`
+	Delta int32 `json:"delta"`
+}
+
+func (q *Queries) UpdatePostCommentCount(ctx context.Context, arg UpdatePostCommentCountParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostCommentCount, arg.ID, arg.Delta)
+	return err
+}
+
+const updatePostContent = `-- name: UpdatePostContent :one
+UPDATE posts SET body = $2, media_id = $3, edited_at = NOW()
+WHERE id = $1
+RETURNING id, user_id, username, body, media_id, created_at, author_badge, comment_count, kind, original_post_id, repost_count, quote_count, edited_at, visibility
+`
+
+type UpdatePostContentParams struct {
+	ID      int32         `json:"id"`
+	Body    string        `json:"body"`
+	MediaID sql.NullInt32 `json:"mediaId"`
+}
+
+func (q *Queries) UpdatePostContent(ctx context.Context, arg UpdatePostContentParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePostContent, arg.ID, arg.Body, arg.MediaID)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Username,
+		&i.Body,
+		&i.MediaID,
+		&i.CreatedAt,
+		&i.AuthorBadge,
+		&i.CommentCount,
+		&i.Kind,
+		&i.OriginalPostID,
+		&i.RepostCount,
+		&i.QuoteCount,
+		&i.EditedAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const updatePostShareCounts = `-- name: UpdatePostShareCounts :exec
+UPDATE posts
+SET repost_count = repost_count + $2::int,
+    quote_count = quote_count + $3::int
+WHERE id = $1
+`
+
+type UpdatePostShareCountsParams struct {
+	ID          int32 `json:"id"`
+	RepostDelta int32 `json:"repostDelta"`
+	QuoteDelta  int32 `json:"quoteDelta"`
+}
+
+func (q *Queries) UpdatePostShareCounts(ctx context.Context, arg UpdatePostShareCountsParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostShareCounts, arg.ID, arg.RepostDelta, arg.QuoteDelta)
+	return err
+}
+
+const updatePostsAuthorBadge = `-- name: UpdatePostsAuthorBadge :exec
+UPDATE posts SET author_badge = $2 WHERE user_id = $1
+`
+
+type UpdatePostsAuthorBadgeParams struct {
+	UserID      int32  `json:"userId"`
+	AuthorBadge string `json:"authorBadge"`
+}
+
+func (q *Queries) UpdatePostsAuthorBadge(ctx context.Context, arg UpdatePostsAuthorBadgeParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostsAuthorBadge, arg.UserID, arg.AuthorBadge)
+	return err
+}
+
+const upsertAuthorBadge = `-- name: UpsertAuthorBadge :exec
+INSERT INTO author_badges(user_id, badge) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET badge = EXCLUDED.badge, verified_at = NOW()
+`
+
+type UpsertAuthorBadgeParams struct {
+	UserID int32  `json:"userId"`
+	Badge  string `json:"badge"`
+}
+
+func (q *Queries) UpsertAuthorBadge(ctx context.Context, arg UpsertAuthorBadgeParams) error {
+	_, err := q.db.ExecContext(ctx, upsertAuthorBadge, arg.UserID, arg.Badge)
+	return err
+}
+
+const upsertHashtags = `-- name: UpsertHashtags :many
+INSERT INTO hashtags(tag) SELECT unnest($1::text[])
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING hashtag_id
+`
+
+func (q *Queries) UpsertHashtags(ctx context.Context, tags []string) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, upsertHashtags, pq.Array(tags))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var hashtag_id int32
+		if err := rows.Scan(&hashtag_id); err != nil {
+			return nil, err
+		}
+		items = append(items, hashtag_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
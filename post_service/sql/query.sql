@@ -1,14 +1,568 @@
 -- name: GetAll :many
-SELECT * FROM posts;
+SELECT * FROM posts
+WHERE NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id);
 
 -- name: GetPostUserAndMediaId :one
-SELECT user_id, media_id FROM posts WHERE id = $1;
+SELECT user_id, media_id, kind, original_post_id FROM posts WHERE id = $1;
 
 -- name: DeletePost :exec
 DELETE FROM posts WHERE id = $1 AND user_id = $2;
 
 -- name: GetPostPage :many
-SELECT * FROM posts WHERE user_id = $1 ORDER BY id DESC LIMIT $2 OFFSET $3;
+-- the user's posts the viewer can see, viewer_follows says whether the viewer
+-- follows the user
+SELECT * FROM posts
+WHERE posts.user_id = $1
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = sqlc.arg(viewer_id)::int
+       OR (posts.visibility = 'followers' AND sqlc.arg(viewer_follows)::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = sqlc.arg(viewer_id)::int
+       )))
+ORDER BY id DESC LIMIT $2 OFFSET $3;
 
--- name: CreatePost :exec
-INSERT INTO Posts(user_id,username,body,media_id) VALUES ($1,$2,$3,$4);
+-- name: GetPostPageAfter :many
+-- posts older than the cursor, newest first
+SELECT * FROM posts
+WHERE posts.user_id = $1
+  AND (posts.created_at, posts.id) < (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::int)
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = sqlc.arg(viewer_id)::int
+       OR (posts.visibility = 'followers' AND sqlc.arg(viewer_follows)::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = sqlc.arg(viewer_id)::int
+       )))
+ORDER BY posts.created_at DESC, posts.id DESC
+LIMIT $2;
+
+-- name: GetPostPageBefore :many
+-- posts newer than the cursor, oldest first
+SELECT * FROM posts
+WHERE posts.user_id = $1
+  AND (posts.created_at, posts.id) > (sqlc.arg(cursor_created_at)::timestamp, sqlc.arg(cursor_id)::int)
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+  AND (posts.visibility = 'public'
+       OR posts.user_id = sqlc.arg(viewer_id)::int
+       OR (posts.visibility = 'followers' AND sqlc.arg(viewer_follows)::bool)
+       OR (posts.visibility IN ('followers', 'mentioned') AND EXISTS (
+           SELECT 1 FROM post_mentions m WHERE m.post_id = posts.id AND m.user_id = sqlc.arg(viewer_id)::int
+       )))
+ORDER BY posts.created_at ASC, posts.id ASC
+LIMIT $2;
+
+-- name: CreatePost :one
+INSERT INTO Posts(user_id,username,body,media_id,visibility,author_badge)
+VALUES ($1,$2,$3,$4,$5,COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $1), ''))
+RETURNING id;
+
+-- name: CreateDeactivatedAuthor :exec
+INSERT INTO deactivated_authors(user_id) VALUES ($1) ON CONFLICT DO NOTHING;
+
+-- name: DeleteDeactivatedAuthor :exec
+DELETE FROM deactivated_authors WHERE user_id = $1;
+
+-- name: GetAllPostsByUserId :many
+SELECT * FROM posts WHERE user_id = $1 ORDER BY id;
+
+-- name: UpsertAuthorBadge :exec
+INSERT INTO author_badges(user_id, badge) VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET badge = EXCLUDED.badge, verified_at = NOW();
+
+-- name: DeleteAuthorBadge :exec
+DELETE FROM author_badges WHERE user_id = $1;
+
+-- name: UpdatePostsAuthorBadge :exec
+UPDATE posts SET author_badge = $2 WHERE user_id = $1;
+
+-- name: GetPostForUpdate :one
+SELECT * FROM posts WHERE id = $1 FOR UPDATE;
+
+-- name: UpdatePostCommentCount :exec
+UPDATE posts SET comment_count = comment_count + sqlc.arg(delta)::int WHERE id = $1;
+
+-- name: CreateComment :one
+INSERT INTO comments(post_id, parent_comment_id, user_id, username, body, author_badge)
+VALUES ($1, $2, $3, $4, $5, COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $3), ''))
+RETURNING *;
+
+-- name: GetCommentForUpdate :one
+SELECT * FROM comments WHERE id = $1 FOR UPDATE;
+
+-- name: UpdateCommentReplyCount :exec
+UPDATE comments SET reply_count = reply_count + sqlc.arg(delta)::int WHERE id = $1;
+
+-- name: DecrementCommentReplyCount :one
+UPDATE comments SET reply_count = reply_count - 1 WHERE id = $1
+RETURNING *;
+
+-- name: UpdateCommentBody :one
+UPDATE comments SET body = $2, edited_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteComment :exec
+DELETE FROM comments WHERE id = $1;
+
+-- name: SoftDeleteComment :exec
+UPDATE comments SET body = '', deleted_at = NOW() WHERE id = $1;
+
+-- name: GetCommentPage :many
+SELECT * FROM comments
+WHERE comments.post_id = $1
+  AND comments.parent_comment_id IS NULL
+  AND comments.id > sqlc.arg(after_id)::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = comments.user_id)
+ORDER BY comments.id
+LIMIT $2;
+
+-- name: GetReplyPage :many
+SELECT * FROM comments
+WHERE comments.post_id = $1
+  AND comments.parent_comment_id = $2
+  AND comments.id > sqlc.arg(after_id)::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = comments.user_id)
+ORDER BY comments.id
+LIMIT $3;
+
+-- name: UpdateCommentsAuthorBadge :exec
+UPDATE comments SET author_badge = $2 WHERE user_id = $1;
+
+-- name: GetAllCommentsByUserId :many
+SELECT * FROM comments WHERE user_id = $1 AND deleted_at IS NULL ORDER BY id;
+
+-- name: CreateReaction :execrows
+INSERT INTO reactions(post_id, user_id, reaction) VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteReaction :execrows
+DELETE FROM reactions WHERE post_id = $1 AND user_id = $2 AND reaction = $3;
+
+-- name: GetReactors :many
+SELECT user_id, reaction, created_at FROM reactions
+WHERE post_id = sqlc.arg(post_id)::int
+  AND (sqlc.arg(reaction)::text = '' OR reaction = sqlc.arg(reaction)::text)
+  AND (created_at, user_id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_user_id)::int)
+ORDER BY created_at DESC, user_id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: GetViewerReactions :many
+SELECT post_id, reaction FROM reactions
+WHERE user_id = sqlc.arg(user_id)::int AND post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: GetReactionCountsByPostIds :many
+SELECT post_id, reaction, count FROM post_reaction_counts
+WHERE post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: DeletePostReactionCounts :exec
+DELETE FROM post_reaction_counts WHERE post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: RecountPostReactions :exec
+INSERT INTO post_reaction_counts(post_id, reaction, count)
+SELECT post_id, reaction, COUNT(*)
+FROM reactions
+WHERE post_id = ANY(sqlc.arg(post_ids)::int[])
+GROUP BY post_id, reaction;
+
+-- name: GetAllReactionsByUserId :many
+SELECT * FROM reactions WHERE user_id = $1 ORDER BY created_at;
+
+-- name: CreateSharedPost :one
+INSERT INTO posts(user_id, username, body, kind, original_post_id, author_badge)
+VALUES ($1, $2, $3, $4, $5, COALESCE((SELECT badge FROM author_badges WHERE author_badges.user_id = $1), ''))
+RETURNING *;
+
+-- name: DeleteRepost :one
+DELETE FROM posts
+WHERE user_id = $1 AND original_post_id = $2 AND kind = 'repost'
+RETURNING id;
+
+-- name: UpdatePostShareCounts :exec
+UPDATE posts
+SET repost_count = repost_count + sqlc.arg(repost_delta)::int,
+    quote_count = quote_count + sqlc.arg(quote_delta)::int
+WHERE id = $1;
+
+-- name: GetPostsByIds :many
+SELECT * FROM posts
+WHERE id = ANY(sqlc.arg(post_ids)::int[])
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id);
+
+-- name: CreateFollow :execrows
+INSERT INTO follows(follower_id, followee_id) VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: DeleteFollowsBetween :many
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_a)::int AND followee_id = sqlc.arg(user_b)::int)
+   OR (follower_id = sqlc.arg(user_b)::int AND followee_id = sqlc.arg(user_a)::int)
+RETURNING follower_id, followee_id;
+
+-- name: DeleteFollowsByUser :many
+DELETE FROM follows
+WHERE follower_id = sqlc.arg(user_id)::int OR followee_id = sqlc.arg(user_id)::int
+RETURNING follower_id, followee_id;
+
+-- name: CreateFollows :many
+INSERT INTO follows(follower_id, followee_id)
+SELECT unnest(sqlc.arg(follower_ids)::int[]), unnest(sqlc.arg(followee_ids)::int[])
+ON CONFLICT DO NOTHING
+RETURNING follower_id, followee_id;
+
+-- name: GetFollowsBackfill :one
+SELECT * FROM follows_backfill WHERE id = 1;
+
+-- name: GetFollowsBackfillForUpdate :one
+SELECT * FROM follows_backfill WHERE id = 1 FOR UPDATE;
+
+-- name: UpdateFollowsBackfill :exec
+UPDATE follows_backfill
+SET after_follower_id = $1,
+    after_followee_id = $2,
+    completed_at = CASE WHEN sqlc.arg(completed)::boolean THEN NOW() END
+WHERE id = 1;
+
+-- name: UpdateFollowerCount :exec
+INSERT INTO follower_counts(user_id, count) VALUES ($1, GREATEST(sqlc.arg(delta)::int, 0))
+ON CONFLICT (user_id) DO UPDATE SET count = GREATEST(follower_counts.count + sqlc.arg(delta)::int, 0);
+
+-- name: DeleteFollowerCount :exec
+DELETE FROM follower_counts WHERE user_id = $1;
+
+-- name: GetFollowerCount :one
+SELECT COALESCE((SELECT count FROM follower_counts WHERE user_id = $1), 0)::int;
+
+-- name: GetFollowerIdsPage :many
+SELECT follower_id FROM follows
+WHERE followee_id = $1 AND follower_id > sqlc.arg(after_id)::int
+ORDER BY follower_id
+LIMIT $2;
+
+-- name: GetRecentPostIdsByUser :many
+-- the user's recent posts that go on follower timelines
+SELECT id FROM posts
+WHERE user_id = $1 AND visibility IN ('public', 'followers')
+ORDER BY id DESC LIMIT $2;
+
+-- name: GetTimelinePostIds :many
+SELECT posts.id FROM posts
+WHERE (posts.user_id = sqlc.arg(viewer_id)::int
+       OR (posts.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg(viewer_id)::int)
+           AND posts.visibility IN ('public', 'followers')))
+  AND posts.id < sqlc.arg(before_id)::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: GetFanoutOnReadPostIds :many
+-- posts of followed accounts too big to fan out on write
+SELECT posts.id FROM posts
+WHERE posts.user_id IN (
+        SELECT f.followee_id FROM follows f
+        JOIN follower_counts c ON c.user_id = f.followee_id
+        WHERE f.follower_id = sqlc.arg(viewer_id)::int AND c.count > sqlc.arg(fanout_limit)::int
+      )
+  AND posts.visibility IN ('public', 'followers')
+  AND posts.id < sqlc.arg(before_id)::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: UpdatePostContent :one
+UPDATE posts SET body = $2, media_id = $3, edited_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreatePostRevision :exec
+INSERT INTO post_revisions(post_id, body, media_id, media_ids, created_at)
+VALUES ($1, $2, $3, sqlc.arg(media_ids)::int[], $4);
+
+-- name: GetPostRevisions :many
+SELECT * FROM post_revisions WHERE post_id = $1 ORDER BY revision_id DESC;
+
+-- name: GetPostRevisionMediaIds :many
+SELECT DISTINCT unnest(media_ids)::int FROM post_revisions WHERE post_id = $1;
+
+-- name: GetVisiblePost :one
+SELECT * FROM posts
+WHERE id = $1
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id);
+
+-- name: GetAllPostRevisionsByUserId :many
+SELECT post_revisions.* FROM post_revisions
+JOIN posts ON posts.id = post_revisions.post_id
+WHERE posts.user_id = $1
+ORDER BY post_revisions.revision_id;
+
+-- name: UpsertHashtags :many
+INSERT INTO hashtags(tag) SELECT unnest(sqlc.arg(tags)::text[])
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING hashtag_id;
+
+-- name: CreatePostHashtags :exec
+INSERT INTO post_hashtags(post_id, hashtag_id)
+SELECT sqlc.arg(post_id)::int, unnest(sqlc.arg(hashtag_ids)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePostHashtags :exec
+DELETE FROM post_hashtags WHERE post_id = $1;
+
+-- name: GetPostHashtags :many
+SELECT hashtags.tag FROM post_hashtags
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE post_hashtags.post_id = $1;
+
+-- name: GetHashtagPostPage :many
+SELECT posts.* FROM posts
+JOIN post_hashtags ON post_hashtags.post_id = posts.id
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg(tag)::text
+  AND posts.id < sqlc.arg(before_id)::int
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id)
+ORDER BY posts.id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: GetRecentHashtagUsesByUser :many
+-- tags on the user's posts still inside the trending window
+SELECT hashtags.tag, posts.created_at FROM posts
+JOIN post_hashtags ON post_hashtags.post_id = posts.id
+JOIN hashtags ON hashtags.hashtag_id = post_hashtags.hashtag_id
+WHERE posts.user_id = $1 AND posts.visibility = 'public'
+  AND posts.created_at > sqlc.arg(since)::timestamp;
+
+-- name: CreatePostMentions :exec
+INSERT INTO post_mentions(post_id, user_id, username, start_offset, end_offset)
+SELECT sqlc.arg(post_id)::int,
+       unnest(sqlc.arg(user_ids)::int[]),
+       unnest(sqlc.arg(usernames)::text[]),
+       unnest(sqlc.arg(start_offsets)::int[]),
+       unnest(sqlc.arg(end_offsets)::int[]);
+
+-- name: DeletePostMentions :exec
+DELETE FROM post_mentions WHERE post_id = $1;
+
+-- name: GetPostMentionedUserIds :many
+SELECT DISTINCT user_id FROM post_mentions WHERE post_id = $1;
+
+-- name: GetMentionsByPostIds :many
+SELECT * FROM post_mentions
+WHERE post_id = ANY(sqlc.arg(post_ids)::int[])
+ORDER BY post_id, start_offset;
+
+-- name: DeleteMentionsBetween :exec
+-- a block turns mentions between the two users back into plain text
+DELETE FROM post_mentions
+USING posts
+WHERE posts.id = post_mentions.post_id
+  AND ((posts.user_id = sqlc.arg(user_a)::int AND post_mentions.user_id = sqlc.arg(user_b)::int)
+    OR (posts.user_id = sqlc.arg(user_b)::int AND post_mentions.user_id = sqlc.arg(user_a)::int));
+
+-- name: DeleteMentionsOfUser :exec
+DELETE FROM post_mentions WHERE user_id = $1;
+
+-- name: CreatePostMedia :exec
+INSERT INTO post_media(post_id, position, media_id, alt_text)
+SELECT sqlc.arg(post_id)::int, i - 1, (sqlc.arg(media_ids)::int[])[i], (sqlc.arg(alt_texts)::text[])[i]
+FROM generate_subscripts(sqlc.arg(media_ids)::int[], 1) AS i;
+
+-- name: DeletePostMedia :exec
+DELETE FROM post_media WHERE post_id = $1;
+
+-- name: GetPostMediaIds :many
+SELECT media_id FROM post_media WHERE post_id = $1 ORDER BY position;
+
+-- name: GetPostMediaByPostIds :many
+SELECT * FROM post_media
+WHERE post_id = ANY(sqlc.arg(post_ids)::int[])
+ORDER BY post_id, position;
+
+-- name: GetViewerMentionedPostIds :many
+SELECT DISTINCT post_id FROM post_mentions
+WHERE user_id = sqlc.arg(viewer_id)::int AND post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: GetPostsByMediaId :many
+-- posts showing the media as an attachment now or in an earlier revision
+SELECT * FROM posts
+WHERE (EXISTS (SELECT 1 FROM post_media m WHERE m.post_id = posts.id AND m.media_id = sqlc.arg(media_id)::int)
+    OR EXISTS (SELECT 1 FROM post_revisions r WHERE r.post_id = posts.id AND r.media_ids @> ARRAY[sqlc.arg(media_id)::int]))
+  AND NOT EXISTS (SELECT 1 FROM deactivated_authors d WHERE d.user_id = posts.user_id);
+
+-- name: GetClaimedMediaIds :many
+-- the media ids a post, an earlier revision, a draft or a scheduled post
+-- holds on to
+SELECT m.id::int FROM unnest(sqlc.arg(media_ids)::int[]) AS m(id)
+WHERE EXISTS (SELECT 1 FROM post_media pm WHERE pm.media_id = m.id)
+   OR EXISTS (SELECT 1 FROM post_revisions r WHERE r.media_ids @> ARRAY[m.id])
+   OR EXISTS (SELECT 1 FROM drafts d WHERE d.media_ids @> ARRAY[m.id])
+   OR EXISTS (SELECT 1 FROM scheduled_posts s WHERE s.media_ids @> ARRAY[m.id])
+ORDER BY m.id;
+
+-- name: CountPendingScheduledPosts :one
+SELECT COUNT(*) FROM scheduled_posts
+WHERE user_id = $1 AND status IN ('scheduled', 'failed');
+
+-- name: CreateScheduledPost :one
+INSERT INTO scheduled_posts(user_id, username, body, visibility, media_ids, alt_texts, publish_at)
+VALUES ($1, $2, $3, $4, sqlc.arg(media_ids)::int[], sqlc.arg(alt_texts)::text[], $5)
+RETURNING *;
+
+-- name: GetPendingScheduledPosts :many
+SELECT * FROM scheduled_posts
+WHERE user_id = $1 AND status IN ('scheduled', 'failed')
+ORDER BY publish_at, id;
+
+-- name: ReschedulePost :one
+-- moving a failed post puts it back in line with fresh attempts
+UPDATE scheduled_posts
+SET publish_at = $3, status = 'scheduled', attempts = 0, last_error = '', updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND status IN ('scheduled', 'failed')
+RETURNING *;
+
+-- name: DeleteScheduledPost :one
+DELETE FROM scheduled_posts
+WHERE id = $1 AND user_id = $2 AND status IN ('scheduled', 'failed')
+RETURNING media_ids;
+
+-- name: GetNextDueScheduledPost :one
+-- the oldest due post. Run outside a transaction the lock is released right
+-- away, it only skips rows another replica is publishing
+SELECT * FROM scheduled_posts
+WHERE status = 'scheduled' AND publish_at <= NOW()
+  AND NOT (id = ANY(sqlc.arg(skip_ids)::int[]))
+ORDER BY publish_at, id
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: ClaimDueScheduledPost :one
+SELECT * FROM scheduled_posts
+WHERE id = $1 AND status = 'scheduled' AND publish_at <= NOW()
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkScheduledPostPublished :exec
+UPDATE scheduled_posts SET status = 'published', post_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: RecordScheduledPostFailure :exec
+UPDATE scheduled_posts
+SET attempts = attempts + 1,
+    last_error = $2,
+    status = CASE WHEN attempts + 1 >= sqlc.arg(max_attempts)::int THEN 'failed' ELSE status END,
+    updated_at = NOW()
+WHERE id = $1 AND status = 'scheduled';
+
+-- name: DeleteScheduledPostsByUser :many
+DELETE FROM scheduled_posts WHERE user_id = $1
+RETURNING status, media_ids;
+
+-- name: GetAllScheduledPostsByUserId :many
+SELECT * FROM scheduled_posts WHERE user_id = $1 ORDER BY id;
+
+-- name: CountDraftsByUser :one
+SELECT COUNT(*) FROM drafts WHERE user_id = $1;
+
+-- name: CreateDraft :one
+INSERT INTO drafts(user_id, body, visibility, media_ids, alt_texts)
+VALUES ($1, $2, $3, sqlc.arg(media_ids)::int[], sqlc.arg(alt_texts)::text[])
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts WHERE id = $1 AND user_id = $2;
+
+-- name: GetDraftForUpdate :one
+SELECT * FROM drafts WHERE id = $1 AND user_id = $2 FOR UPDATE;
+
+-- name: GetDraftsByUser :many
+SELECT * FROM drafts WHERE user_id = $1 ORDER BY updated_at DESC, id DESC;
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $2, visibility = $3, media_ids = sqlc.arg(media_ids)::int[], alt_texts = sqlc.arg(alt_texts)::text[],
+    version = version + 1, updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteDraft :one
+DELETE FROM drafts WHERE id = $1 AND user_id = $2
+RETURNING media_ids;
+
+-- name: DeleteStaleDrafts :many
+-- abandoned drafts in batches, rows another replica is deleting are skipped
+WITH stale AS (
+    SELECT id FROM drafts
+    WHERE updated_at < sqlc.arg(updated_before)::timestamp
+    ORDER BY id
+    LIMIT sqlc.arg(batch_size)::int
+    FOR UPDATE SKIP LOCKED
+)
+DELETE FROM drafts USING stale
+WHERE drafts.id = stale.id
+RETURNING drafts.media_ids;
+
+-- name: DeleteDraftsByUser :many
+DELETE FROM drafts WHERE user_id = $1
+RETURNING media_ids;
+
+-- name: GetAllDraftsByUserId :many
+SELECT * FROM drafts WHERE user_id = $1 ORDER BY id;
+
+-- name: CreatePoll :exec
+INSERT INTO polls(post_id, multiple_choice, ends_at) VALUES ($1, $2, $3);
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options(post_id, position, text)
+SELECT sqlc.arg(post_id)::int, i - 1, (sqlc.arg(texts)::text[])[i]
+FROM generate_subscripts(sqlc.arg(texts)::text[], 1) AS i;
+
+-- name: GetPollsByPostIds :many
+SELECT * FROM polls WHERE post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: GetPollOptionsByPostIds :many
+SELECT * FROM poll_options
+WHERE post_id = ANY(sqlc.arg(post_ids)::int[])
+ORDER BY post_id, position;
+
+-- name: GetViewerPollVotes :many
+SELECT * FROM poll_votes
+WHERE user_id = sqlc.arg(viewer_id)::int AND post_id = ANY(sqlc.arg(post_ids)::int[]);
+
+-- name: GetPollForUpdate :one
+SELECT * FROM polls WHERE post_id = $1 FOR UPDATE;
+
+-- name: CreatePollVote :execrows
+INSERT INTO poll_votes(post_id, user_id, positions) VALUES ($1, $2, sqlc.arg(positions)::int[])
+ON CONFLICT DO NOTHING;
+
+-- name: UpdatePollOptionVoteCounts :exec
+UPDATE poll_options SET vote_count = GREATEST(vote_count + sqlc.arg(delta)::int, 0)
+WHERE post_id = $1 AND position = ANY(sqlc.arg(positions)::int[]);
+
+-- name: UpdatePollVoterCount :exec
+UPDATE polls SET voter_count = GREATEST(voter_count + sqlc.arg(delta)::int, 0)
+WHERE post_id = $1;
+
+-- name: DeletePollVotesByUser :many
+DELETE FROM poll_votes WHERE user_id = $1
+RETURNING post_id, positions;
+
+-- name: ClaimDuePoll :one
+-- the oldest ended poll not announced yet, rows another replica is closing
+-- are skipped
+SELECT polls.*, posts.user_id AS author_id FROM polls
+JOIN posts ON posts.id = polls.post_id
+WHERE polls.closed_at IS NULL AND polls.ends_at <= NOW()
+ORDER BY polls.ends_at
+LIMIT 1
+FOR UPDATE OF polls SKIP LOCKED;
+
+-- name: MarkPollClosed :exec
+UPDATE polls SET closed_at = NOW() WHERE post_id = $1;
+
+-- name: GetPollOptions :many
+SELECT * FROM poll_options WHERE post_id = $1 ORDER BY position;
+
+-- name: GetAllPollVotesByUserId :many
+SELECT * FROM poll_votes WHERE user_id = $1 ORDER BY created_at;
@@ -5,5 +5,208 @@ CREATE TABLE posts
     username text NOT NULL,   
     body text NOT NULL, 
     media_id int,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    author_badge text NOT NULL DEFAULT '',
+    comment_count int NOT NULL DEFAULT 0,
+    kind text NOT NULL DEFAULT 'post' CHECK (kind IN ('post', 'repost', 'quote')),
+    original_post_id int REFERENCES posts(id) ON DELETE SET NULL,
+    repost_count int NOT NULL DEFAULT 0,
+    quote_count int NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_posts_original_post_id ON posts(original_post_id);
+CREATE UNIQUE INDEX idx_posts_one_repost ON posts(user_id, original_post_id) WHERE kind = 'repost';
+
+CREATE TABLE deactivated_authors
+(
+    user_id int PRIMARY KEY,
+    deactivated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE author_badges
+(
+    user_id int PRIMARY KEY,
+    badge text NOT NULL,
+    verified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE comments
+(
+    id SERIAL PRIMARY KEY,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    parent_comment_id int REFERENCES comments(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    author_badge text NOT NULL DEFAULT '',
+    body text NOT NULL,
+    reply_count int NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    edited_at TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+CREATE INDEX idx_comments_post_id ON comments(post_id, id) WHERE parent_comment_id IS NULL;
+CREATE INDEX idx_comments_parent_comment_id ON comments(parent_comment_id, id);
+CREATE INDEX idx_comments_user_id ON comments(user_id);
+
+CREATE TABLE reactions
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    reaction text NOT NULL CHECK (reaction IN ('like', 'love', 'laugh', 'wow', 'sad', 'angry')),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id, reaction)
+);
+CREATE INDEX idx_reactions_post_id_created_at ON reactions(post_id, created_at DESC, user_id DESC);
+CREATE INDEX idx_reactions_user_id ON reactions(user_id);
+
+CREATE TABLE post_reaction_counts
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    reaction text NOT NULL,
+    count int NOT NULL,
+    PRIMARY KEY (post_id, reaction)
+);
+
+CREATE TABLE follows
+(
+    follower_id int NOT NULL,
+    followee_id int NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id)
+);
+CREATE INDEX idx_follows_followee_id ON follows(followee_id, follower_id);
+
+CREATE TABLE follower_counts
+(
+    user_id int PRIMARY KEY,
+    count int NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_posts_user_id_id ON posts(user_id, id DESC);
+CREATE INDEX idx_posts_user_id_created_at_id ON posts(user_id, created_at DESC, id DESC);
+ALTER TABLE posts ADD COLUMN edited_at TIMESTAMP;
+
+CREATE TABLE post_revisions
+(
+    revision_id SERIAL PRIMARY KEY,
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    body text NOT NULL,
+    media_id int,
+    created_at TIMESTAMP NOT NULL,
+    replaced_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_post_revisions_post_id ON post_revisions(post_id, revision_id DESC);
+
+CREATE TABLE hashtags
+(
+    hashtag_id SERIAL PRIMARY KEY,
+    tag text NOT NULL UNIQUE,
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
+
+CREATE TABLE post_hashtags
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    hashtag_id int NOT NULL REFERENCES hashtags(hashtag_id) ON DELETE CASCADE,
+    PRIMARY KEY (post_id, hashtag_id)
+);
+CREATE INDEX idx_post_hashtags_hashtag_id ON post_hashtags(hashtag_id, post_id DESC);
+
+CREATE TABLE post_mentions
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    start_offset int NOT NULL,
+    end_offset int NOT NULL,
+    PRIMARY KEY (post_id, start_offset)
+);
+CREATE INDEX idx_post_mentions_user_id ON post_mentions(user_id, post_id DESC);
+
+CREATE TABLE post_media
+(
+    post_id int NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position int NOT NULL,
+    media_id int NOT NULL,
+    alt_text text NOT NULL DEFAULT '' CHECK (char_length(alt_text) <= 1000),
+    PRIMARY KEY (post_id, position)
+);
+
+ALTER TABLE post_revisions ADD COLUMN media_ids int[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE posts ADD COLUMN visibility text NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'followers', 'mentioned', 'private'));
+CREATE INDEX idx_post_media_media_id ON post_media(media_id);
+CREATE INDEX idx_post_revisions_media_ids ON post_revisions USING gin(media_ids);
+
+CREATE TABLE scheduled_posts
+(
+    id SERIAL PRIMARY KEY,
+    user_id int NOT NULL,
+    username text NOT NULL,
+    body text NOT NULL,
+    visibility text NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'followers', 'mentioned', 'private')),
+    media_ids int[] NOT NULL DEFAULT '{}',
+    alt_texts text[] NOT NULL DEFAULT '{}',
+    publish_at TIMESTAMP NOT NULL,
+    status text NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'published', 'failed')),
+    post_id int REFERENCES posts(id) ON DELETE SET NULL,
+    attempts int NOT NULL DEFAULT 0,
+    last_error text NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_scheduled_posts_due ON scheduled_posts(publish_at, id) WHERE status = 'scheduled';
+CREATE INDEX idx_scheduled_posts_user_id ON scheduled_posts(user_id, publish_at);
+
+CREATE TABLE drafts
+(
+    id SERIAL PRIMARY KEY,
+    user_id int NOT NULL,
+    body text NOT NULL DEFAULT '',
+    visibility text NOT NULL DEFAULT 'public'
+        CHECK (visibility IN ('public', 'followers', 'mentioned', 'private')),
+    media_ids int[] NOT NULL DEFAULT '{}',
+    alt_texts text[] NOT NULL DEFAULT '{}',
+    version int NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_drafts_user_id ON drafts(user_id, updated_at DESC);
+CREATE INDEX idx_drafts_updated_at ON drafts(updated_at);
+
+CREATE TABLE polls
+(
+    post_id int PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    multiple_choice boolean NOT NULL DEFAULT false,
+    ends_at TIMESTAMP NOT NULL,
+    closed_at TIMESTAMP,
+    voter_count int NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_polls_due ON polls(ends_at) WHERE closed_at IS NULL;
+
+CREATE TABLE poll_options
+(
+    post_id int NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    position int NOT NULL,
+    text text NOT NULL CHECK (char_length(text) BETWEEN 1 AND 100),
+    vote_count int NOT NULL DEFAULT 0,
+    PRIMARY KEY (post_id, position)
+);
+
+CREATE TABLE poll_votes
+(
+    post_id int NOT NULL REFERENCES polls(post_id) ON DELETE CASCADE,
+    user_id int NOT NULL,
+    positions int[] NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id)
+);
+CREATE INDEX idx_poll_votes_user_id ON poll_votes(user_id);
+
+CREATE TABLE follows_backfill
+(
+    id int PRIMARY KEY CHECK (id = 1),
+    after_follower_id int NOT NULL DEFAULT 0,
+    after_followee_id int NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP
+);
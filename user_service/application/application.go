@@ -1,6 +1,7 @@
 package application
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
@@ -71,7 +72,11 @@ func New() *Application {
 	if err != nil {
 		log.Fatal(err)
 	}
-	rpcClient, err := rpc_client.New(medaiServiceRpcClient)
+	authServiceRpcClient, err := ConnectToRpcServer("authentication-service:8081", 5, 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rpcClient, err := rpc_client.New(medaiServiceRpcClient, authServiceRpcClient)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -87,10 +92,32 @@ func New() *Application {
 		DB:       0,  // use default DB
 	})
 	//init service layer
-	userService, err := service.New(db, rdb, minioClient, rpcClient, rabbitmqProducer, service.UserServiceConfig{MinioBucketName: config.MinioBucketName})
+	userService, err := service.New(db, rdb, minioClient, rpcClient, rabbitmqProducer, service.UserServiceConfig{
+		MinioBucketName:         config.MinioBucketName,
+		ProfileCacheTTL:         config.ProfileCacheTTL,
+		NegativeCacheTTL:        config.NegativeCacheTTL,
+		DeactivationGracePeriod: config.DeactivationGracePeriod,
+	})
 	if err != nil {
 		log.Fatal(err)
 	}
+	//keep the local profile cache coherent across replicas
+	go func() {
+		for {
+			err := userService.ListenForCacheInvalidations(context.Background())
+			log.Println("cache invalidation listener stopped:", err)
+			time.Sleep(5 * time.Second)
+		}
+	}()
+
+	//hard delete accounts whose deactivation grace period has expired
+	purgeInterval := config.PurgeInterval
+	if purgeInterval <= 0 {
+		purgeInterval = time.Hour
+	}
+	go userService.RunPurgeJob(context.Background(), purgeInterval)
+	//drop expired data export archives
+	go userService.RunDataExportExpiryJob(context.Background(), 15*time.Minute)
 
 	// init rabbitmq Consumer and inject userService to handle messages
 	rabbitConsumer, err := rabbitmq_consumer.NewRabbitMQConsumer(rabbitmqConn, "user-service", userService)
@@ -2,6 +2,7 @@ package application
 
 import (
 	"os"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -15,6 +16,12 @@ type config struct {
 	MinioAccessKeyID     string `validate:"required"`
 	MinioSecretAccessKey string `validate:"required"`
 	MinioBucketName      string `validate:"required"`
+	// optional cache tuning, service defaults apply when unset
+	ProfileCacheTTL  time.Duration
+	NegativeCacheTTL time.Duration
+	// optional account deletion tuning
+	DeactivationGracePeriod time.Duration
+	PurgeInterval           time.Duration
 }
 
 func (c *config) Validate() error {
@@ -35,19 +42,47 @@ func getEnvConfig() (*config, error) {
 	minioSecretAccessKey := os.Getenv("minioSecretAccessKey")
 	minioEndpoint := os.Getenv("minioEndpoint")
 	minioBucketName := os.Getenv("minioBucketName")
+	profileCacheTTL, err := parseOptionalDuration("profileCacheTtl")
+	if err != nil {
+		return nil, err
+	}
+	negativeCacheTTL, err := parseOptionalDuration("negativeCacheTtl")
+	if err != nil {
+		return nil, err
+	}
+	deactivationGracePeriod, err := parseOptionalDuration("deactivationGracePeriod")
+	if err != nil {
+		return nil, err
+	}
+	purgeInterval, err := parseOptionalDuration("purgeInterval")
+	if err != nil {
+		return nil, err
+	}
 	config := config{
-		Port:                 port,
-		PostgresDsn:          postgresDsn,
-		JwtSecret:            jwtSecret,
-		RabbitUrl:            rabbitUrl,
-		MinioAccessKeyID:     minioAccessKeyID,
-		MinioSecretAccessKey: minioSecretAccessKey,
-		MinioEndpoint:        minioEndpoint,
-		MinioBucketName:      minioBucketName,
-	}
-	err := config.Validate()
+		Port:                    port,
+		PostgresDsn:             postgresDsn,
+		JwtSecret:               jwtSecret,
+		RabbitUrl:               rabbitUrl,
+		MinioAccessKeyID:        minioAccessKeyID,
+		MinioSecretAccessKey:    minioSecretAccessKey,
+		MinioEndpoint:           minioEndpoint,
+		MinioBucketName:         minioBucketName,
+		ProfileCacheTTL:         profileCacheTTL,
+		NegativeCacheTTL:        negativeCacheTTL,
+		DeactivationGracePeriod: deactivationGracePeriod,
+		PurgeInterval:           purgeInterval,
+	}
+	err = config.Validate()
 	if err != nil {
 		return nil, err
 	}
 	return &config, config.Validate()
 }
+
+func parseOptionalDuration(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+CREATE INDEX idx_users_firstname_trgm ON users USING gin (firstname gin_trgm_ops);
+CREATE INDEX idx_users_lastname_trgm ON users USING gin (lastname gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX idx_users_lastname_trgm;
+DROP INDEX idx_users_firstname_trgm;
+DROP INDEX idx_users_username_trgm;
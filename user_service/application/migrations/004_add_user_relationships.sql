@@ -0,0 +1,45 @@
+-- +goose Up
+-- the join time of existing users is unknown, they get the epoch so they
+-- are never suggested as recently joined; the default only applies to users
+-- created from now on
+ALTER TABLE users ADD COLUMN created_at TIMESTAMPTZ;
+UPDATE users SET created_at = 'epoch';
+ALTER TABLE users ALTER COLUMN created_at SET NOT NULL;
+ALTER TABLE users ALTER COLUMN created_at SET DEFAULT NOW();
+CREATE INDEX idx_users_created_at ON users(created_at);
+
+CREATE TABLE follows
+(
+    follower_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    followee_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+CREATE INDEX idx_follows_followee_id ON follows(followee_id);
+
+CREATE TABLE blocks
+(
+    blocker_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    blocked_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+CREATE INDEX idx_blocks_blocked_id ON blocks(blocked_id);
+
+CREATE TABLE mutes
+(
+    muter_id   int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    muted_id   int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (muter_id, muted_id),
+    CHECK (muter_id <> muted_id)
+);
+
+-- +goose Down
+DROP TABLE mutes;
+DROP TABLE blocks;
+DROP TABLE follows;
+DROP INDEX idx_users_created_at;
+ALTER TABLE users DROP COLUMN created_at;
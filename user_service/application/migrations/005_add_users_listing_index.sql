@@ -0,0 +1,7 @@
+-- +goose Up
+DROP INDEX idx_users_created_at;
+CREATE INDEX idx_users_created_at ON users(created_at, user_id);
+
+-- +goose Down
+DROP INDEX idx_users_created_at;
+CREATE INDEX idx_users_created_at ON users(created_at);
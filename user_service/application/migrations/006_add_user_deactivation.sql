@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN deactivated_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN purge_after TIMESTAMPTZ;
+CREATE INDEX idx_users_purge_after ON users(purge_after) WHERE purge_after IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_users_purge_after;
+ALTER TABLE users DROP COLUMN purge_after;
+ALTER TABLE users DROP COLUMN deactivated_at;
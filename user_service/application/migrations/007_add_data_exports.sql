@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE data_exports
+(
+    export_id      SERIAL PRIMARY KEY,
+    user_id        int NOT NULL,
+    status         text NOT NULL DEFAULT 'pending',
+    parts_expected int NOT NULL,
+    parts_received int NOT NULL DEFAULT 0,
+    object_key     text,
+    error          text,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at   TIMESTAMPTZ,
+    expires_at     TIMESTAMPTZ
+);
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id, created_at DESC);
+CREATE INDEX idx_data_exports_expires_at ON data_exports(expires_at) WHERE status = 'ready';
+CREATE UNIQUE INDEX idx_data_exports_active ON data_exports(user_id) WHERE status IN ('pending', 'assembling');
+
+CREATE TABLE data_export_parts
+(
+    export_id   int NOT NULL REFERENCES data_exports(export_id) ON DELETE CASCADE,
+    service     text NOT NULL,
+    payload     bytea NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (export_id, service)
+);
+
+-- +goose Down
+DROP TABLE data_export_parts;
+DROP TABLE data_exports;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- handles differing only by case were allowed before, the oldest keeps its
+-- handle and the others get their id appended so the index can be built.
+-- The authentication service applies the same rule to its copy.
+UPDATE users
+SET username = users.username || '_' || users.user_id
+WHERE EXISTS (
+    SELECT 1 FROM users older
+    WHERE LOWER(older.username) = LOWER(users.username) AND older.user_id < users.user_id
+);
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMPTZ;
+
+CREATE TABLE username_history
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    username   text NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    held_until TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_username_history_username ON username_history(LOWER(username), held_until);
+CREATE INDEX idx_username_history_user_id ON username_history(user_id, changed_at DESC);
+
+-- +goose Down
+DROP TABLE username_history;
+ALTER TABLE users DROP COLUMN username_changed_at;
+DROP INDEX idx_users_username_lower;
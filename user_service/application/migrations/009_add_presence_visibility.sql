@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN presence_visibility text NOT NULL DEFAULT 'everyone';
+ALTER TABLE users ADD CONSTRAINT chk_presence_visibility
+    CHECK (presence_visibility IN ('everyone', 'followers', 'nobody'));
+
+-- +goose Down
+ALTER TABLE users DROP CONSTRAINT chk_presence_visibility;
+ALTER TABLE users DROP COLUMN presence_visibility;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN banner_image_id int;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN banner_image_id;
@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE lists
+(
+    list_id      SERIAL PRIMARY KEY,
+    owner_id     int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name         text NOT NULL CHECK (char_length(name) BETWEEN 1 AND 50),
+    description  text NOT NULL DEFAULT '' CHECK (char_length(description) <= 160),
+    is_private   boolean NOT NULL DEFAULT false,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_lists_owner_id ON lists(owner_id, created_at DESC);
+
+CREATE TABLE list_members
+(
+    list_id  int NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    user_id  int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, user_id)
+);
+CREATE INDEX idx_list_members_user_id ON list_members(user_id);
+
+-- +goose Down
+DROP TABLE list_members;
+DROP TABLE lists;
@@ -0,0 +1,42 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN verified_badge text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN verified_at TIMESTAMPTZ;
+ALTER TABLE users ADD CONSTRAINT chk_verified_badge
+    CHECK (verified_badge IN ('', 'notable', 'official'));
+
+CREATE TABLE verification_requests
+(
+    request_id  SERIAL PRIMARY KEY,
+    user_id     int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    badge       text NOT NULL CHECK (badge IN ('notable', 'official')),
+    reason      text NOT NULL CHECK (char_length(reason) <= 1000),
+    status      text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewer_id int,
+    review_note text NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_verification_requests_pending ON verification_requests(user_id) WHERE status = 'pending';
+CREATE INDEX idx_verification_requests_status ON verification_requests(status, created_at);
+
+-- user_id and actor_id have no foreign keys so the trail outlives purged
+-- accounts, both the reviewed user's and the admin's
+CREATE TABLE verification_audit
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    int NOT NULL,
+    action     text NOT NULL CHECK (action IN ('requested', 'approved', 'rejected', 'granted', 'revoked')),
+    badge      text NOT NULL DEFAULT '',
+    actor_id   int NOT NULL,
+    request_id int REFERENCES verification_requests(request_id) ON DELETE SET NULL,
+    note       text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_verification_audit_user_id ON verification_audit(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE verification_audit;
+DROP TABLE verification_requests;
+ALTER TABLE users DROP CONSTRAINT chk_verified_badge;
+ALTER TABLE users DROP COLUMN verified_at;
+ALTER TABLE users DROP COLUMN verified_badge;
@@ -18,14 +18,55 @@ func SetupRouter(h *handler.Handler, tm *jwtauth.JWTAuth) *chi.Mux {
 	r.Use(middleware.Timeout(60 * time.Second))
 
 	r.Get("/api/v1/users/health", h.CheckHealth)
-	r.Get("/api/v1/users/all", h.GetAllUsers)
+	r.Get("/api/v1/users/all", h.ListUsers)
 	r.Get("/api/v1/users/{userId}", h.GetUser)
+	r.Get("/api/v1/users/by-username/{username}", h.GetUserByUsername)
+	r.Post("/api/v1/users/batch", h.GetUsersBatch)
+	// Optionally authenticated routes
+	r.Group(func(r chi.Router) {
+		r.Use(jwtauth.Verifier(tm))
+		r.Get("/api/v1/users/search", h.SearchUsers)
+		r.Post("/api/v1/users/presence", h.GetPresence)
+		r.Get("/api/v1/users/{userId}/lists", h.GetUserLists)
+		r.Get("/api/v1/users/lists/{listId}", h.GetList)
+		r.Get("/api/v1/users/lists/{listId}/members", h.GetListMembers)
+	})
 	// Protected routes
 	r.Group(func(r chi.Router) {
 		r.Use(jwtauth.Verifier(tm))
 		r.Use(jwtauth.Authenticator)
+		r.Use(h.TrackPresence)
+		r.Get("/api/v1/users/me", h.GetOwnProfile)
+		r.Patch("/api/v1/users/presence/settings", h.UpdatePresenceSettings)
 		r.Patch("/api/v1/users/{userId}", h.UpdateUser)
 		r.Delete("/api/v1/users/{userId}", h.DeleteUser)
+		r.Get("/api/v1/users/suggestions", h.GetSuggestedUsers)
+		r.Post("/api/v1/users/exports", h.RequestDataExport)
+		r.Get("/api/v1/users/exports/{exportId}", h.GetDataExport)
+		r.Post("/api/v1/users/{userId}/follow", h.FollowUser)
+		r.Delete("/api/v1/users/{userId}/follow", h.UnfollowUser)
+		r.Post("/api/v1/users/{userId}/block", h.BlockUser)
+		r.Delete("/api/v1/users/{userId}/block", h.UnblockUser)
+		r.Post("/api/v1/users/{userId}/mute", h.MuteUser)
+		r.Delete("/api/v1/users/{userId}/mute", h.UnmuteUser)
+		r.Post("/api/v1/users/lists", h.CreateList)
+		r.Patch("/api/v1/users/lists/{listId}", h.UpdateList)
+		r.Delete("/api/v1/users/lists/{listId}", h.DeleteList)
+		r.Post("/api/v1/users/lists/{listId}/members/{userId}", h.AddListMember)
+		r.Delete("/api/v1/users/lists/{listId}/members/{userId}", h.RemoveListMember)
+		r.Get("/api/v1/users/verification", h.GetVerificationStatus)
+		r.Post("/api/v1/users/verification/requests", h.RequestVerification)
+		// Admin routes
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireAdmin)
+			r.Get("/api/v1/users/cache/stats", h.GetCacheStats)
+			r.Get("/api/v1/users/verification/requests", h.ListVerificationRequests)
+			r.Post("/api/v1/users/verification/requests/{requestId}/approve", h.ApproveVerificationRequest)
+			r.Post("/api/v1/users/verification/requests/{requestId}/reject", h.RejectVerificationRequest)
+			r.Post("/api/v1/users/{userId}/verification/grant", h.GrantVerification)
+			r.Post("/api/v1/users/{userId}/verification/revoke", h.RevokeVerification)
+			r.Get("/api/v1/users/{userId}/verification/audit", h.GetVerificationAudit)
+		})
 	})
 	return r
 }
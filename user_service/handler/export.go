@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/user_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+func (h *Handler) RequestDataExport(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	status, err := h.UserService.RequestDataExport(r.Context(), int32(ctxUserId))
+	if errors.Is(err, service.ErrDataExportInProgress) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	err = json.NewEncoder(w).Encode(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetDataExport(w http.ResponseWriter, r *http.Request) {
+	exportId, err := strconv.Atoi(chi.URLParam(r, "exportId"))
+	if err != nil || exportId <= 0 {
+		http.Error(w, "invalid export id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	status, err := h.UserService.GetDataExport(r.Context(), int32(ctxUserId), int32(exportId))
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "export not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
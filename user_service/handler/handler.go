@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/BernardN38/socialstream-backend/user_service/service"
 	"github.com/go-chi/chi/v5"
@@ -24,13 +28,53 @@ func (h *Handler) CheckHealth(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("user service up and running"))
 }
 
-func (h *Handler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := h.UserService.GetAllUsers(r.Context())
+func (h *Handler) GetCacheStats(w http.ResponseWriter, r *http.Request) {
+	err := json.NewEncoder(w).Encode(h.UserService.GetCacheStats())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	err = json.NewEncoder(w).Encode(users)
+}
+
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := service.ListUsersReq{
+		After:          query.Get("after"),
+		Limit:          defaultPageSize,
+		Sort:           service.UserSortId,
+		UsernamePrefix: query.Get("usernamePrefix"),
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > maxPageSize {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		req.Limit = int32(limit)
+	}
+	if v := query.Get("sort"); v != "" {
+		switch v {
+		case service.UserSortId, service.UserSortNewest, service.UserSortUsername:
+			req.Sort = v
+		default:
+			http.Error(w, "invalid sort", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("joinedAfter"); v != "" {
+		joinedAfter, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid joinedAfter, expected RFC3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		req.JoinedAfter = joinedAfter
+	}
+	resp, err := h.UserService.ListUsers(r.Context(), req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = json.NewEncoder(w).Encode(resp)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -57,6 +101,101 @@ func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// GetOwnProfile serves the signed in user's profile, the only one that
+// includes an email.
+func (h *Handler) GetOwnProfile(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	user, err := h.UserService.GetOwnProfile(r.Context(), int32(ctxUserId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// GetUserByUsername serves the profile for a handle. Handles given up by a
+// recent rename redirect to the owner's current one.
+func (h *Handler) GetUserByUsername(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	user, redirectTo, err := h.UserService.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if redirectTo != "" {
+		http.Redirect(w, r, "/api/v1/users/by-username/"+url.PathEscape(redirectTo), http.StatusFound)
+		return
+	}
+	err = json.NewEncoder(w).Encode(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetUsersBatch(w http.ResponseWriter, r *http.Request) {
+	var batchReq GetUsersBatchRequest
+	err := json.NewDecoder(r.Body).Decode(&batchReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(batchReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cards, err := h.UserService.GetUsersByIds(r.Context(), batchReq.UserIds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(cards)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "missing search query", http.StatusBadRequest)
+		return
+	}
+	pageNo, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	viewerId, err := optionalViewerId(r)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	resp, err := h.UserService.SearchUsers(r.Context(), service.SearchUsersReq{
+		Query:    query,
+		PageNo:   pageNo,
+		PageSize: pageSize,
+		ViewerId: viewerId,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userId := chi.URLParam(r, "userId")
 	userIdInt, err := strconv.Atoi(userId)
@@ -86,6 +225,10 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	err = h.UserService.UpdateUser(r.Context(), int32(ctxUserId), service.UpdateUserInput(updateUserReq))
+	if errors.Is(err, service.ErrUsernameTaken) || errors.Is(err, service.ErrRenameConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
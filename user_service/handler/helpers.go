@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/jwtauth/v5"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	adminRole       = "admin"
+)
+
+// parsePagination reads the optional pageNo and pageSize query params,
+// falling back to the first page of defaultPageSize results.
+func parsePagination(r *http.Request) (int32, int32, error) {
+	pageNo, pageSize := 1, defaultPageSize
+	var err error
+	if v := r.URL.Query().Get("pageNo"); v != "" {
+		pageNo, err = strconv.Atoi(v)
+		if err != nil || pageNo <= 0 {
+			return 0, 0, errors.New("invalid pageNo")
+		}
+	}
+	if v := r.URL.Query().Get("pageSize"); v != "" {
+		pageSize, err = strconv.Atoi(v)
+		if err != nil || pageSize <= 0 || pageSize > maxPageSize {
+			return 0, 0, errors.New("invalid pageSize")
+		}
+	}
+	return int32(pageNo), int32(pageSize), nil
+}
+
+// viewerIdFromContext returns the user id of a verified token when one was
+// sent, or 0 for anonymous requests on routes where auth is optional.
+func viewerIdFromContext(r *http.Request) int32 {
+	token, claims, err := jwtauth.FromContext(r.Context())
+	if err != nil || token == nil {
+		return 0
+	}
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0
+	}
+	return int32(userId)
+}
+
+// optionalViewerId is viewerIdFromContext for routes that filter results
+// per viewer. A token that was sent but failed verification is an error
+// rather than an anonymous request, so the filter is never silently skipped.
+func optionalViewerId(r *http.Request) (int32, error) {
+	_, _, err := jwtauth.FromContext(r.Context())
+	if errors.Is(err, jwtauth.ErrNoTokenFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return viewerIdFromContext(r), nil
+}
+
+// RequireAdmin only lets through tokens carrying the admin role. It must run
+// after jwtauth.Authenticator.
+func (h *Handler) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, claims, _ := jwtauth.FromContext(r.Context())
+		role, _ := claims["role"].(string)
+		if role != adminRole {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/user_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+func (h *Handler) CreateList(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var createListReq CreateListRequest
+	err := json.NewDecoder(r.Body).Decode(&createListReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(createListReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := h.UserService.CreateList(r.Context(), int32(ctxUserId), service.CreateListInput(createListReq))
+	if errors.Is(err, service.ErrListLimitReached) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := listIdFromUrl(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.UserService.GetList(r.Context(), viewerIdFromContext(r), listId)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) GetUserLists(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil || userId <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	lists, err := h.UserService.GetUserLists(r.Context(), viewerIdFromContext(r), int32(userId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(lists)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := listIdFromUrl(w, r)
+	if !ok {
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var updateListReq UpdateListRequest
+	err := json.NewDecoder(r.Body).Decode(&updateListReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(updateListReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	list, err := h.UserService.UpdateList(r.Context(), int32(ctxUserId), listId, service.UpdateListInput(updateListReq))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	listId, ok := listIdFromUrl(w, r)
+	if !ok {
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err := h.UserService.DeleteList(r.Context(), int32(ctxUserId), listId)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GetListMembers(w http.ResponseWriter, r *http.Request) {
+	listId, ok := listIdFromUrl(w, r)
+	if !ok {
+		return
+	}
+	pageNo, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	members, err := h.UserService.GetListMembers(r.Context(), viewerIdFromContext(r), listId, pageNo, pageSize)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	err = json.NewEncoder(w).Encode(members)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) AddListMember(w http.ResponseWriter, r *http.Request) {
+	h.handleListMember(w, r, h.UserService.AddListMember)
+}
+
+func (h *Handler) RemoveListMember(w http.ResponseWriter, r *http.Request) {
+	h.handleListMember(w, r, h.UserService.RemoveListMember)
+}
+
+// handleListMember applies action from the token user to the {listId} and
+// {userId} in the url.
+func (h *Handler) handleListMember(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, ownerId int32, listId int32, userId int32) error) {
+	listId, ok := listIdFromUrl(w, r)
+	if !ok {
+		return
+	}
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil || userId <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = action(r.Context(), int32(ctxUserId), listId, int32(userId))
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func listIdFromUrl(w http.ResponseWriter, r *http.Request) (int32, bool) {
+	listId, err := strconv.Atoi(chi.URLParam(r, "listId"))
+	if err != nil || listId <= 0 {
+		http.Error(w, "invalid list id", http.StatusBadRequest)
+		return 0, false
+	}
+	return int32(listId), true
+}
+
+func writeListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrListNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrNotListOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrListFull):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -8,6 +8,44 @@ type UpdateUserRequest struct {
 	LastName  string `json:"lastName"`
 }
 
+type GetUsersBatchRequest struct {
+	UserIds []int32 `json:"userIds" validate:"required,min=1,max=500"`
+}
+
+type GetPresenceRequest struct {
+	UserIds []int32 `json:"userIds" validate:"required,min=1,max=500"`
+}
+
+type UpdatePresenceSettingsRequest struct {
+	Visibility string `json:"visibility" validate:"required,oneof=everyone followers nobody"`
+}
+
+type CreateListRequest struct {
+	Name        string `json:"name" validate:"required,max=50"`
+	Description string `json:"description" validate:"max=160"`
+	IsPrivate   bool   `json:"isPrivate"`
+}
+
+type UpdateListRequest struct {
+	Name        *string `json:"name" validate:"omitempty,min=1,max=50"`
+	Description *string `json:"description" validate:"omitempty,max=160"`
+	IsPrivate   *bool   `json:"isPrivate"`
+}
+
+type VerificationRequestRequest struct {
+	Badge  string `json:"badge" validate:"required,oneof=notable official"`
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+type ReviewVerificationRequest struct {
+	Note string `json:"note" validate:"max=1000"`
+}
+
+type GrantVerificationRequest struct {
+	Badge string `json:"badge" validate:"required,oneof=notable official"`
+	Note  string `json:"note" validate:"max=1000"`
+}
+
 func Validate(input interface{}) error {
 	validate := validator.New()
 	err := validate.Struct(input)
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/jwtauth/v5"
+)
+
+// TrackPresence records a heartbeat for the token user on every
+// authenticated request.
+func (h *Handler) TrackPresence(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := viewerIdFromContext(r)
+		if userId != 0 {
+			err := h.UserService.RecordActivity(r.Context(), userId)
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (h *Handler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	var presenceReq GetPresenceRequest
+	err := json.NewDecoder(r.Body).Decode(&presenceReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(presenceReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	presence, err := h.UserService.GetPresence(r.Context(), viewerIdFromContext(r), presenceReq.UserIds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(presence)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) UpdatePresenceSettings(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var settingsReq UpdatePresenceSettingsRequest
+	err := json.NewDecoder(r.Body).Decode(&settingsReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(settingsReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.UserService.UpdatePresenceVisibility(r.Context(), int32(ctxUserId), settingsReq.Visibility)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+func (h *Handler) FollowUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.FollowUser)
+}
+
+func (h *Handler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.UnfollowUser)
+}
+
+func (h *Handler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.BlockUser)
+}
+
+func (h *Handler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.UnblockUser)
+}
+
+func (h *Handler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.MuteUser)
+}
+
+func (h *Handler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	h.handleRelationship(w, r, h.UserService.UnmuteUser)
+}
+
+// handleRelationship applies action from the token user to the {userId} in the url.
+func (h *Handler) handleRelationship(w http.ResponseWriter, r *http.Request, action func(context.Context, int32, int32) error) {
+	userId := chi.URLParam(r, "userId")
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil || userIdInt <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	err = action(r.Context(), int32(ctxUserId), int32(userIdInt))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GetSuggestedUsers(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	limit := defaultPageSize
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+	suggestions, err := h.UserService.GetSuggestedUsers(r.Context(), int32(ctxUserId), limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(suggestions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/BernardN38/socialstream-backend/user_service/service"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+)
+
+func (h *Handler) GetVerificationStatus(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	status, err := h.UserService.GetVerificationStatus(r.Context(), int32(ctxUserId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(status)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) RequestVerification(w http.ResponseWriter, r *http.Request) {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var verificationReq VerificationRequestRequest
+	err := json.NewDecoder(r.Body).Decode(&verificationReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(verificationReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request, err := h.UserService.RequestVerification(r.Context(), int32(ctxUserId), verificationReq.Badge, verificationReq.Reason)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	err = json.NewEncoder(w).Encode(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) ListVerificationRequests(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "pending"
+	}
+	if status != "pending" && status != "approved" && status != "rejected" {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+	pageNo, pageSize, err := parsePagination(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requests, err := h.UserService.ListVerificationRequests(r.Context(), status, pageNo, pageSize)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(requests)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h *Handler) ApproveVerificationRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewVerificationRequest(w, r, true)
+}
+
+func (h *Handler) RejectVerificationRequest(w http.ResponseWriter, r *http.Request) {
+	h.reviewVerificationRequest(w, r, false)
+}
+
+func (h *Handler) reviewVerificationRequest(w http.ResponseWriter, r *http.Request, approve bool) {
+	requestId, err := strconv.Atoi(chi.URLParam(r, "requestId"))
+	if err != nil || requestId <= 0 {
+		http.Error(w, "invalid request id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var reviewReq ReviewVerificationRequest
+	err = json.NewDecoder(r.Body).Decode(&reviewReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(reviewReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.UserService.ReviewVerificationRequest(r.Context(), int32(ctxUserId), int32(requestId), approve, reviewReq.Note)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GrantVerification(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil || userId <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var grantReq GrantVerificationRequest
+	err = json.NewDecoder(r.Body).Decode(&grantReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(grantReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.UserService.GrantVerification(r.Context(), int32(ctxUserId), int32(userId), grantReq.Badge, grantReq.Note)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) RevokeVerification(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil || userId <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	ctxUserId := claims["user_id"].(float64)
+
+	var revokeReq ReviewVerificationRequest
+	err = json.NewDecoder(r.Body).Decode(&revokeReq)
+	if err != nil {
+		http.Error(w, "unable to decode json body", http.StatusBadRequest)
+		return
+	}
+	err = Validate(revokeReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = h.UserService.RevokeVerification(r.Context(), int32(ctxUserId), int32(userId), revokeReq.Note)
+	if err != nil {
+		writeVerificationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GetVerificationAudit(w http.ResponseWriter, r *http.Request) {
+	userId, err := strconv.Atoi(chi.URLParam(r, "userId"))
+	if err != nil || userId <= 0 {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+	entries, err := h.UserService.GetVerificationAudit(r.Context(), int32(userId))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	err = json.NewEncoder(w).Encode(entries)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func writeVerificationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, service.ErrVerificationRequestMissing):
+		http.Error(w, "not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrAlreadyVerified),
+		errors.Is(err, service.ErrNotVerified),
+		errors.Is(err, service.ErrVerificationPending),
+		errors.Is(err, service.ErrVerificationReviewed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -36,6 +36,22 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, userService *s
 		conn.Close()
 		return nil, err
 	}
+	// export parts come back on each service's own exchange
+	for _, exchange := range []string{"post_events", "media_events"} {
+		err = channel.ExchangeDeclare(
+			exchange,
+			"topic",
+			true,
+			false,
+			false,
+			false,
+			nil,
+		)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
 	queue, err := channel.QueueDeclare(
 		queueName,
 		false,
@@ -53,6 +69,38 @@ func NewRabbitMQConsumer(conn *amqp.Connection, queueName string, userService *s
 	if err != nil {
 		return nil, err
 	}
+	err = channel.QueueBind(queue.Name, "user.followed", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.unfollowed", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.loggedin", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.active", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.export.requested", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "user.export.part", "user_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "post.export.part", "post_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = channel.QueueBind(queue.Name, "media.export.part", "media_events", false, nil)
+	if err != nil {
+		return nil, err
+	}
 	return &RabbitMQConsumer{
 		conn:        conn,
 		channel:     channel,
@@ -107,6 +155,66 @@ func (c *RabbitMQConsumer) Consume() error {
 					log.Println(err)
 				}
 				log.Println("user created: ", createUserInput)
+			case "user.followed", "user.unfollowed":
+				var followMsg UserFollowedMsg
+				err := json.Unmarshal(msg.Body, &followMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.userService.HandleFollowChanged(ctx, followMsg.FollowerId, followMsg.FolloweeId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.loggedin":
+				var loggedInMsg UserLoggedInMsg
+				err := json.Unmarshal(msg.Body, &loggedInMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				// logging back in during the grace period restores the account
+				err = c.userService.ReactivateUser(ctx, loggedInMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+				err = c.userService.RecordActivity(ctx, loggedInMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.active":
+				var activeMsg UserActiveMsg
+				err := json.Unmarshal(msg.Body, &activeMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.userService.RecordActivity(ctx, activeMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.export.requested":
+				var exportMsg DataExportRequestedMsg
+				err := json.Unmarshal(msg.Body, &exportMsg)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.userService.HandleDataExportRequested(ctx, exportMsg.ExportId, exportMsg.UserId)
+				if err != nil {
+					log.Println(err)
+				}
+			case "user.export.part", "post.export.part", "media.export.part":
+				var part service.DataExportPart
+				err := json.Unmarshal(msg.Body, &part)
+				if err != nil {
+					log.Println(err)
+					continue
+				}
+				err = c.userService.RecordDataExportPart(ctx, part)
+				if err != nil {
+					log.Println(err)
+				}
 			default:
 				log.Println("did not recognize topic:", msg.RoutingKey)
 			}
@@ -120,3 +228,21 @@ type UserProfileImageUploadInput struct {
 	UserId  int32 `json:"userId"`
 	MediaId int32 `json:"mediaId"`
 }
+
+type UserFollowedMsg struct {
+	FollowerId int32 `json:"followerId"`
+	FolloweeId int32 `json:"followeeId"`
+}
+
+type UserLoggedInMsg struct {
+	UserId int32 `json:"userId"`
+}
+
+type UserActiveMsg struct {
+	UserId int32 `json:"userId"`
+}
+
+type DataExportRequestedMsg struct {
+	ExportId int32 `json:"exportId"`
+	UserId   int32 `json:"userId"`
+}
@@ -1,9 +1,64 @@
 package rabbitmq_producer
 
+import "time"
+
 type UserDeletedMsg struct {
 	UserId int32 `json:"userId"`
 }
 
+type UserDeactivatedMsg struct {
+	UserId     int32     `json:"userId"`
+	PurgeAfter time.Time `json:"purgeAfter"`
+}
+
+type UserReactivatedMsg struct {
+	UserId int32 `json:"userId"`
+}
+
 type MediaDeletedMsg struct {
 	MediaId string `json:"mediaId"`
 }
+
+type UserFollowedMsg struct {
+	FollowerId int32 `json:"followerId"`
+	FolloweeId int32 `json:"followeeId"`
+}
+
+type UserBlockedMsg struct {
+	BlockerId int32 `json:"blockerId"`
+	BlockedId int32 `json:"blockedId"`
+}
+
+type DataExportRequestedMsg struct {
+	ExportId int32 `json:"exportId"`
+	UserId   int32 `json:"userId"`
+}
+
+type DataExportReadyMsg struct {
+	ExportId  int32     `json:"exportId"`
+	UserId    int32     `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type UserRenamedMsg struct {
+	UserId      int32     `json:"userId"`
+	OldUsername string    `json:"oldUsername"`
+	NewUsername string    `json:"newUsername"`
+	HeldUntil   time.Time `json:"heldUntil"`
+}
+
+type ListMemberMsg struct {
+	ListId  int32 `json:"listId"`
+	OwnerId int32 `json:"ownerId"`
+	UserId  int32 `json:"userId"`
+}
+
+type ListDeletedMsg struct {
+	ListId  int32 `json:"listId"`
+	OwnerId int32 `json:"ownerId"`
+}
+
+type UserVerifiedMsg struct {
+	UserId int32  `json:"userId"`
+	Badge  string `json:"badge"`
+}
@@ -2,12 +2,14 @@ package rpc_client
 
 import (
 	"net/rpc"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type RpcClient struct {
 	mediaServiceRpcClient *rpc.Client
+	authServiceRpcClient  *rpc.Client
 }
 
 type RpcImageUpload struct {
@@ -18,9 +20,25 @@ type RpcImageUpload struct {
 	ProfileImage bool
 }
 
-func New(mediaServiceClient *rpc.Client) (*RpcClient, error) {
+// RenameUserReq asks the authentication service, which owns handles, to
+// apply a rename. HeldUntil is zero when the old handle is not held.
+type RenameUserReq struct {
+	UserId      int32
+	OldUsername string
+	NewUsername string
+	HeldUntil   time.Time
+}
+
+// RenameUserResp is empty when the rename was applied, Rejected is one of
+// invalid, reserved, taken or conflict otherwise.
+type RenameUserResp struct {
+	Rejected string
+}
+
+func New(mediaServiceClient *rpc.Client, authServiceClient *rpc.Client) (*RpcClient, error) {
 	return &RpcClient{
 		mediaServiceRpcClient: mediaServiceClient,
+		authServiceRpcClient:  authServiceClient,
 	}, nil
 }
 
@@ -44,3 +62,19 @@ func (rc *RpcClient) DeleteMedia(mediaId uuid.UUID) error {
 	}
 	return nil
 }
+
+// RenameUser returns the reason the authentication service refused the
+// rename, empty when it was applied.
+func (rc *RpcClient) RenameUser(req RenameUserReq) (string, error) {
+	var resp RenameUserResp
+	err := rc.authServiceRpcClient.Call("RpcServer.RenameUser", req, &resp)
+	if err != nil {
+		return "", err
+	}
+	return resp.Rejected, nil
+}
+
+func (rc *RpcClient) RevertRename(req RenameUserReq) error {
+	var reverted bool
+	return rc.authServiceRpcClient.Call("RpcServer.RevertRename", req, &reverted)
+}
@@ -22,6 +22,32 @@ type ProfileImageUpdateReq struct {
 	UserId  int32
 	MediaId int32
 }
+type BannerImageUpdateReq struct {
+	UserId  int32
+	MediaId int32
+}
+type ListMembersReq struct {
+	ListId int32
+	// ViewerId is checked against the list visibility, 0 when anonymous
+	ViewerId int32
+}
+type MentionsReq struct {
+	AuthorId  int32
+	Usernames []string
+}
+type FollowedAmongReq struct {
+	FollowerId  int32
+	FolloweeIds []int32
+}
+type FollowsPageReq struct {
+	AfterFollowerId int32
+	AfterFolloweeId int32
+	Limit           int32
+}
+type Follow struct {
+	FollowerId int32
+	FolloweeId int32
+}
 
 // New returns the object for the RPC handler
 func NewRpcServer(userService *service.UserService) (*RpcServer, error) {
@@ -54,3 +80,84 @@ func (s *RpcServer) UpdateUserProfileImageId(updateReq ProfileImageUpdateReq, re
 	}
 	return nil
 }
+
+func (s *RpcServer) GetUserBannerImageId(userId int32, reply *int32) error {
+	bannerImageMediaId, err := s.userService.GetUserBannerImage(context.Background(), userId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = bannerImageMediaId
+	return nil
+}
+func (s *RpcServer) UpdateUserBannerImageId(updateReq BannerImageUpdateReq, reply *error) error {
+	ctx := context.Background()
+	err := s.userService.UpdateUserBannerImageId(ctx, updateReq.UserId, updateReq.MediaId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	return nil
+}
+
+// GetUsersByIds returns compact profile cards for up to service.MaxBatchUserIds ids.
+func (s *RpcServer) GetUsersByIds(userIds []int32, reply *[]service.UserCard) error {
+	cards, err := s.userService.GetUsersByIds(context.Background(), userIds)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = cards
+	return nil
+}
+
+// GetListMemberIds returns the member ids of a list the viewer can see so
+// the post service can render its timeline.
+func (s *RpcServer) GetListMemberIds(req ListMembersReq, reply *[]int32) error {
+	memberIds, err := s.userService.GetListMemberIds(context.Background(), req.ViewerId, req.ListId)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = memberIds
+	return nil
+}
+
+// ResolveMentions returns the users in req.Usernames the author can mention.
+func (s *RpcServer) ResolveMentions(req MentionsReq, reply *[]service.MentionedUser) error {
+	mentioned, err := s.userService.ResolveMentions(context.Background(), req.AuthorId, req.Usernames)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = mentioned
+	return nil
+}
+
+// GetFollowedAmong returns the ids in req.FolloweeIds that req.FollowerId
+// follows, the post service uses it to show followers-only posts.
+func (s *RpcServer) GetFollowedAmong(req FollowedAmongReq, reply *[]int32) error {
+	followed, err := s.userService.GetFollowedAmong(context.Background(), req.FollowerId, req.FolloweeIds)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	*reply = followed
+	return nil
+}
+
+// GetFollowsPage returns the follows after the given pair, the post service
+// pages through it to backfill its copy of the follow graph.
+func (s *RpcServer) GetFollowsPage(req FollowsPageReq, reply *[]Follow) error {
+	rows, err := s.userService.GetFollowsPage(context.Background(), req.AfterFollowerId, req.AfterFolloweeId, req.Limit)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+	follows := make([]Follow, 0, len(rows))
+	for _, row := range rows {
+		follows = append(follows, Follow{FollowerId: row.FollowerID, FolloweeId: row.FolloweeID})
+	}
+	*reply = follows
+	return nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	cacheInvalidationChannel = "user-service:cache-invalidations"
+	defaultProfileCacheTTL   = 10 * time.Minute
+	defaultNegativeCacheTTL  = 30 * time.Second
+	localCacheTTL            = 30 * time.Second
+	localCacheMaxEntries     = 10000
+)
+
+// notFoundMarker is cached in place of a value when the row does not exist.
+var notFoundMarker = []byte("null")
+
+// CacheStats are the per replica counters exposed for ttl tuning.
+// NegativeHits is the subset of hits that found a cached not-found entry.
+type CacheStats struct {
+	LocalHits    int64 `json:"localHits"`
+	RedisHits    int64 `json:"redisHits"`
+	NegativeHits int64 `json:"negativeHits"`
+	Misses       int64 `json:"misses"`
+	Invalidated  int64 `json:"invalidated"`
+}
+
+// profileCache is a two level read-through cache. A short lived in-process
+// map sits in front of redis, and invalidations are broadcast over redis
+// pub/sub so every replica drops its local copy.
+type profileCache struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	mu    sync.RWMutex
+	local map[string]localCacheEntry
+
+	localHits    atomic.Int64
+	redisHits    atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+	invalidated  atomic.Int64
+}
+
+type localCacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func newProfileCache(redisClient *redis.Client, ttl time.Duration, negativeTTL time.Duration) *profileCache {
+	if ttl <= 0 {
+		ttl = defaultProfileCacheTTL
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = defaultNegativeCacheTTL
+	}
+	return &profileCache{
+		redisClient: redisClient,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		local:       make(map[string]localCacheEntry),
+	}
+}
+
+// getMany returns the cached values for keys, nil where nothing is cached.
+// Redis errors are logged and treated as misses.
+func (c *profileCache) getMany(ctx context.Context, keys []string) [][]byte {
+	values := make([][]byte, len(keys))
+	remoteKeys := []string{}
+	remoteIdx := []int{}
+	for i, key := range keys {
+		if value, ok := c.getLocal(key); ok {
+			c.localHits.Add(1)
+			values[i] = value
+			continue
+		}
+		remoteKeys = append(remoteKeys, key)
+		remoteIdx = append(remoteIdx, i)
+	}
+	if len(remoteKeys) == 0 {
+		return values
+	}
+	remoteValues, err := c.redisClient.MGet(ctx, remoteKeys...).Result()
+	if err != nil {
+		log.Println(err)
+		c.misses.Add(int64(len(remoteKeys)))
+		return values
+	}
+	for i, remoteValue := range remoteValues {
+		cached, ok := remoteValue.(string)
+		if !ok {
+			c.misses.Add(1)
+			continue
+		}
+		c.redisHits.Add(1)
+		values[remoteIdx[i]] = []byte(cached)
+		c.setLocal(remoteKeys[i], []byte(cached))
+	}
+	return values
+}
+
+func (c *profileCache) get(ctx context.Context, key string) ([]byte, bool) {
+	value := c.getMany(ctx, []string{key})[0]
+	return value, value != nil
+}
+
+func (c *profileCache) setMany(ctx context.Context, entries map[string][]byte) {
+	if len(entries) == 0 {
+		return
+	}
+	pipe := c.redisClient.Pipeline()
+	for key, value := range entries {
+		ttl := c.ttl
+		if isNotFoundMarker(value) {
+			ttl = c.negativeTTL
+		}
+		pipe.Set(ctx, key, value, ttl)
+		c.setLocal(key, value)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (c *profileCache) set(ctx context.Context, key string, value []byte) {
+	c.setMany(ctx, map[string][]byte{key: value})
+}
+
+// invalidate deletes the keys from redis and tells every replica, including
+// this one, to evict its local copies of the user's entries.
+func (c *profileCache) invalidate(ctx context.Context, userId int32, keys ...string) {
+	c.evictLocal(keys...)
+	err := c.redisClient.Del(ctx, keys...).Err()
+	if err != nil {
+		log.Println(err)
+	}
+	err = c.redisClient.Publish(ctx, cacheInvalidationChannel, userId).Err()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// listen evicts local entries announced on the invalidation channel until
+// ctx is cancelled.
+func (c *profileCache) listen(ctx context.Context, keysForUser func(int32) []string) error {
+	pubsub := c.redisClient.Subscribe(ctx, cacheInvalidationChannel)
+	defer pubsub.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return errors.New("cache invalidation subscription closed")
+			}
+			userId, err := strconv.Atoi(msg.Payload)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			c.evictLocal(keysForUser(int32(userId))...)
+		}
+	}
+}
+
+func (c *profileCache) stats() CacheStats {
+	return CacheStats{
+		LocalHits:    c.localHits.Load(),
+		RedisHits:    c.redisHits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Invalidated:  c.invalidated.Load(),
+	}
+}
+
+func (c *profileCache) getLocal(key string) ([]byte, bool) {
+	c.mu.RLock()
+	entry, ok := c.local[key]
+	c.mu.RUnlock()
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *profileCache) setLocal(key string, value []byte) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.local) >= localCacheMaxEntries {
+		for k, entry := range c.local {
+			if now.After(entry.expiresAt) {
+				delete(c.local, k)
+			}
+		}
+		// still full of live entries, start over rather than grow unbounded
+		if len(c.local) >= localCacheMaxEntries {
+			c.local = make(map[string]localCacheEntry)
+		}
+	}
+	c.local[key] = localCacheEntry{
+		value:     value,
+		expiresAt: now.Add(localCacheTTL),
+	}
+}
+
+func (c *profileCache) evictLocal(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if _, ok := c.local[key]; ok {
+			delete(c.local, key)
+			c.invalidated.Add(1)
+		}
+	}
+}
+
+func isNotFoundMarker(value []byte) bool {
+	return string(value) == string(notFoundMarker)
+}
+
+// readThrough returns the cached value for key or loads and caches it. A
+// sql.ErrNoRows from load is cached as a negative entry and returned as is.
+func readThrough[T any](ctx context.Context, c *profileCache, key string, load func(context.Context) (T, error)) (T, error) {
+	var value T
+	if cached, ok := c.get(ctx, key); ok {
+		if isNotFoundMarker(cached) {
+			c.negativeHits.Add(1)
+			return value, sql.ErrNoRows
+		}
+		err := json.Unmarshal(cached, &value)
+		if err == nil {
+			return value, nil
+		}
+		log.Println(err)
+	}
+	value, err := load(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.set(ctx, key, notFoundMarker)
+		return value, err
+	}
+	if err != nil {
+		return value, err
+	}
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return value, err
+	}
+	c.set(ctx, key, valueBytes)
+	return value, nil
+}
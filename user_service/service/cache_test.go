@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestProfileCacheLocalBound(t *testing.T) {
+	cache := newProfileCache(nil, 0, 0)
+	for i := 0; i < localCacheMaxEntries+10; i++ {
+		cache.setLocal(fmt.Sprintf("key:%d", i), []byte("value"))
+	}
+	if len(cache.local) > localCacheMaxEntries {
+		t.Errorf("local cache grew to %d entries, max is %d", len(cache.local), localCacheMaxEntries)
+	}
+	if _, ok := cache.getLocal(fmt.Sprintf("key:%d", localCacheMaxEntries+9)); !ok {
+		t.Error("latest entry was not kept")
+	}
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	cache := newProfileCache(SetupRedis(t), time.Minute, time.Minute)
+
+	loads := 0
+	load := func(context.Context) (string, error) {
+		loads++
+		return "profile", nil
+	}
+	for i := 0; i < 3; i++ {
+		value, err := readThrough(ctx, cache, "user:1:profile", load)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != "profile" {
+			t.Errorf("read %q, want profile", value)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loaded %d times, want once", loads)
+	}
+	// another replica only has the redis copy
+	cache.evictLocal("user:1:profile")
+	_, err := readThrough(ctx, cache, "user:1:profile", load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := cache.stats()
+	if stats.Misses != 1 || stats.LocalHits != 2 || stats.RedisHits != 1 || loads != 1 {
+		t.Errorf("stats = %+v after %d loads, want 1 miss, 2 local hits, 1 redis hit, 1 load", stats, loads)
+	}
+
+	missingLoads := 0
+	missing := func(context.Context) (string, error) {
+		missingLoads++
+		return "", sql.ErrNoRows
+	}
+	for i := 0; i < 2; i++ {
+		_, err := readThrough(ctx, cache, "user:2:profile", missing)
+		if !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("read of missing user = %v, want sql.ErrNoRows", err)
+		}
+	}
+	if missingLoads != 1 {
+		t.Errorf("missing user loaded %d times, want once", missingLoads)
+	}
+	if cache.stats().NegativeHits != 1 {
+		t.Errorf("negative hits = %d, want 1", cache.stats().NegativeHits)
+	}
+
+	cache.invalidate(ctx, 1, "user:1:profile")
+	_, err = readThrough(ctx, cache, "user:1:profile", load)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loads != 2 {
+		t.Errorf("invalidated entry loaded %d times in total, want 2", loads)
+	}
+}
+
+func TestProfileCacheInvalidationBroadcast(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	redisClient := SetupRedis(t)
+	replicaA := newProfileCache(redisClient, time.Minute, time.Minute)
+	replicaB := newProfileCache(redisClient, time.Minute, time.Minute)
+
+	go replicaA.listen(ctx, userCacheKeys)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		subscribers, err := redisClient.PubSubNumSub(ctx, cacheInvalidationChannel).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if subscribers[cacheInvalidationChannel] > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replica never subscribed to invalidations")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	replicaA.setLocal(userProfileCacheKey(7), []byte(`{"user_id":7}`))
+	replicaB.invalidate(ctx, 7, userCacheKeys(7)...)
+	for {
+		if _, ok := replicaA.getLocal(userProfileCacheKey(7)); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("replica kept its local copy after another replica invalidated it")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGetUserCachesNotFoundUntilCreated(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+
+	_, err := userService.GetUser(ctx, 5)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("GetUser of missing user = %v, want sql.ErrNoRows", err)
+	}
+	createTestUser(t, userService, 5, "lateuser", "Late", "User")
+	user, err := userService.GetUser(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "lateuser" {
+		t.Errorf("username = %q, want lateuser", user.Username)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+const (
+	defaultDeactivationGracePeriod = 30 * 24 * time.Hour
+	purgeBatchSize                 = 100
+)
+
+// DeleteUser deactivates the account instead of removing it. The profile is
+// hidden right away and the row is only purged, with user.deleted published,
+// once the grace period has passed without the user logging back in.
+func (u *UserService) DeleteUser(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	purgeAfter := time.Now().Add(u.config.DeactivationGracePeriod)
+	rows, err := u.userDbQuries.DeactivateUser(timeoutCtx, users.DeactivateUserParams{
+		UserID:     userId,
+		PurgeAfter: purgeAfter,
+	})
+	if err != nil {
+		return err
+	}
+	// unknown user or already deactivated
+	if rows == 0 {
+		return nil
+	}
+	u.invalidateUserCache(ctx, userId)
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserDeactivatedMsg{
+		UserId:     userId,
+		PurgeAfter: purgeAfter,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.deactivated", msgBytes)
+}
+
+// ReactivateUser restores an account that is still inside its grace period.
+// It is a no-op for accounts that are active.
+func (u *UserService) ReactivateUser(ctx context.Context, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.ReactivateUser(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	u.invalidateUserCache(ctx, userId)
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserReactivatedMsg{
+		UserId: userId,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.reactivated", msgBytes)
+}
+
+// PurgeDeactivatedUsers hard deletes up to one batch of accounts whose grace
+// period has expired and publishes user.deleted for each of them. Rows are
+// locked with SKIP LOCKED so several replicas can run the job concurrently.
+func (u *UserService) PurgeDeactivatedUsers(ctx context.Context) (int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	userIds, err := txQuries.GetUsersDueForPurge(timeoutCtx, purgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, userId := range userIds {
+		err = txQuries.DeleteUser(timeoutCtx, userId)
+		if err != nil {
+			return 0, err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	for _, userId := range userIds {
+		u.invalidateUserCache(ctx, userId)
+		err = u.clearPresence(ctx, userId)
+		if err != nil {
+			log.Println(err)
+		}
+		msgBytes, err := json.Marshal(rabbitmq_producer.UserDeletedMsg{
+			UserId: userId,
+		})
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		err = u.rabbitmqPorducer.Publish("user.deleted", msgBytes)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+	return len(userIds), nil
+}
+
+// RunPurgeJob purges expired accounts every interval until ctx is cancelled.
+// Full batches are drained straight away instead of waiting for the next tick.
+func (u *UserService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for {
+			purged, err := u.PurgeDeactivatedUsers(ctx)
+			if err != nil {
+				log.Println("purge deactivated users:", err)
+				break
+			}
+			if purged > 0 {
+				log.Printf("purged %d deactivated users", purged)
+			}
+			if purged < purgeBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
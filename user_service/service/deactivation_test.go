@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+)
+
+func TestDeactivateAndReactivateUser(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	userService.config.DeactivationGracePeriod = time.Hour
+	createTestUser(t, userService, 1, "leaving1", "Leaving", "User")
+
+	// warm the cache so deactivation has to invalidate it
+	_, err := userService.GetUser(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		err = userService.DeleteUser(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = userService.GetUser(ctx, 1)
+	if !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetUser of deactivated user = %v, want sql.ErrNoRows", err)
+	}
+	cards, err := userService.GetUsersByIds(ctx, []int32{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 0 {
+		t.Errorf("deactivated user still has a card: %+v", cards)
+	}
+	search, err := userService.SearchUsers(ctx, SearchUsersReq{Query: "leaving1", PageNo: 1, PageSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Users) != 0 {
+		t.Errorf("deactivated user still searchable: %+v", search.Users)
+	}
+
+	for i := 0; i < 2; i++ {
+		err = userService.ReactivateUser(ctx, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = userService.GetUser(ctx, 1)
+	if err != nil {
+		t.Errorf("GetUser of reactivated user = %v", err)
+	}
+	want := []string{"user.deactivated", "user.reactivated"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestPurgeDeactivatedUsers(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "expired1", "Expired", "One")
+	createTestUser(t, userService, 2, "expired2", "Expired", "Two")
+	createTestUser(t, userService, 3, "expired3", "Expired", "Three")
+	createTestUser(t, userService, 4, "ingrace4", "Grace", "Four")
+	createTestUser(t, userService, 5, "active55", "Active", "Five")
+
+	userService.config.DeactivationGracePeriod = -time.Minute
+	for _, userId := range []int32{1, 2, 3} {
+		err := userService.DeleteUser(ctx, userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	userService.config.DeactivationGracePeriod = time.Hour
+	err := userService.DeleteUser(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// replicas running the job at once must not purge a user twice
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			purged, err := userService.PurgeDeactivatedUsers(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			total += purged
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if total != 3 {
+		t.Errorf("purged %d users, want 3", total)
+	}
+
+	deleted := map[int32]int{}
+	producer.mu.Lock()
+	for i, topic := range producer.topics {
+		if topic != "user.deleted" {
+			continue
+		}
+		var msg rabbitmq_producer.UserDeletedMsg
+		err := json.Unmarshal(producer.messages[i], &msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		deleted[msg.UserId]++
+	}
+	producer.mu.Unlock()
+	if !reflect.DeepEqual(deleted, map[int32]int{1: 1, 2: 1, 3: 1}) {
+		t.Errorf("user.deleted published for %v, want users 1, 2 and 3 once each", deleted)
+	}
+
+	// the user still inside the grace period can come back
+	err = userService.ReactivateUser(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, userId := range []int32{4, 5} {
+		_, err = userService.GetUser(ctx, userId)
+		if err != nil {
+			t.Errorf("GetUser(%d) after purge = %v", userId, err)
+		}
+	}
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/lib/pq"
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	dataExportRetention     = 7 * 24 * time.Hour
+	dataExportLinkTTL       = time.Hour
+	dataExportAssemblyLimit = 30 * time.Minute
+	// exports still waiting on a service after this long are failed
+	dataExportPartsDeadline = time.Hour
+	dataExportExpiryBatch   = 100
+)
+
+// dataExportServices are the services expected to answer user.export.requested
+// with one part each.
+var dataExportServices = []string{"auth", "user", "post", "media"}
+
+var ErrDataExportInProgress = errors.New("a data export is already in progress")
+
+// RequestDataExport starts a new export of everything the platform stores
+// about the user. Every service receives user.export.requested and answers
+// with its part, the archive is assembled once all parts have arrived.
+func (u *UserService) RequestDataExport(ctx context.Context, userId int32) (*DataExportStatus, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	export, err := u.userDbQuries.CreateDataExport(timeoutCtx, users.CreateDataExportParams{
+		UserID:        userId,
+		PartsExpected: int32(len(dataExportServices)),
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, ErrDataExportInProgress
+		}
+		return nil, err
+	}
+	msgBytes, err := json.Marshal(rabbitmq_producer.DataExportRequestedMsg{
+		ExportId: export.ExportID,
+		UserId:   userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = u.rabbitmqPorducer.Publish("user.export.requested", msgBytes)
+	if err != nil {
+		u.failDataExport(ctx, export.ExportID, "unable to reach services")
+		return nil, err
+	}
+	return u.dataExportStatus(ctx, export)
+}
+
+// GetDataExport reports the progress of one of the user's exports, with a
+// fresh download link once it is ready.
+func (u *UserService) GetDataExport(ctx context.Context, userId int32, exportId int32) (*DataExportStatus, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	export, err := u.userDbQuries.GetDataExport(timeoutCtx, exportId)
+	if err != nil {
+		return nil, err
+	}
+	// do not reveal other users' exports
+	if export.UserID != userId {
+		return nil, sql.ErrNoRows
+	}
+	return u.dataExportStatus(ctx, export)
+}
+
+// HandleDataExportRequested contributes the user service's own part, the
+// profile and the follow, block and mute lists.
+func (u *UserService) HandleDataExportRequested(ctx context.Context, exportId int32, userId int32) error {
+	part := DataExportPart{
+		ExportId: exportId,
+		Service:  "user",
+	}
+	files, err := u.collectUserExportFiles(ctx, userId)
+	if err != nil {
+		log.Println(err)
+		part.Error = "unable to collect profile"
+	}
+	part.Files = files
+	return u.RecordDataExportPart(ctx, part)
+}
+
+// RecordDataExportPart stores a service's part. Duplicate deliveries are
+// ignored, and the part that completes the set triggers assembly.
+func (u *UserService) RecordDataExportPart(ctx context.Context, part DataExportPart) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	payload, err := json.Marshal(part)
+	if err != nil {
+		return err
+	}
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	rows, err := txQuries.CreateDataExportPart(timeoutCtx, users.CreateDataExportPartParams{
+		ExportID: part.ExportId,
+		Service:  part.Service,
+		Payload:  payload,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	if part.Error != "" {
+		err = txQuries.FailDataExport(timeoutCtx, users.FailDataExportParams{
+			ExportID: part.ExportId,
+			Error:    sql.NullString{String: fmt.Sprintf("%s: %s", part.Service, part.Error), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	progress, err := txQuries.IncrementDataExportParts(timeoutCtx, part.ExportId)
+	// the export already failed or expired, keep the part but do nothing
+	if errors.Is(err, sql.ErrNoRows) {
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if progress.PartsReceived < progress.PartsExpected {
+		return nil
+	}
+	claimed, err := u.userDbQuries.ClaimDataExportForAssembly(timeoutCtx, part.ExportId)
+	if err != nil {
+		return err
+	}
+	if claimed == 1 {
+		go u.assembleDataExport(part.ExportId)
+	}
+	return nil
+}
+
+// RunDataExportExpiryJob removes expired archives and fails exports that a
+// service never answered, every interval until ctx is cancelled.
+func (u *UserService) RunDataExportExpiryJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := u.expireDataExports(ctx)
+		if err != nil {
+			log.Println("expire data exports:", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (u *UserService) expireDataExports(ctx context.Context) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	stale, err := u.userDbQuries.FailStaleDataExports(timeoutCtx, time.Now().Add(-dataExportPartsDeadline))
+	if err != nil {
+		return err
+	}
+	if stale > 0 {
+		log.Printf("failed %d stale data exports", stale)
+	}
+	expired, err := u.userDbQuries.GetExpiredDataExports(timeoutCtx, dataExportExpiryBatch)
+	if err != nil {
+		return err
+	}
+	for _, export := range expired {
+		if export.ObjectKey.Valid {
+			err = u.minioClient.RemoveObject(timeoutCtx, u.config.MinioBucketName, export.ObjectKey.String, minio.RemoveObjectOptions{})
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+		}
+		err = u.userDbQuries.ExpireDataExport(timeoutCtx, export.ExportID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *UserService) assembleDataExport(exportId int32) {
+	ctx, cancel := context.WithTimeout(context.Background(), dataExportAssemblyLimit)
+	defer cancel()
+
+	export, err := u.userDbQuries.GetDataExport(ctx, exportId)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	parts, err := u.userDbQuries.ListDataExportParts(ctx, exportId)
+	if err != nil {
+		log.Println(err)
+		u.failDataExport(ctx, exportId, "unable to load parts")
+		return
+	}
+	objectKey := fmt.Sprintf("exports/%d/%d.zip", export.UserID, exportId)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(u.writeDataExportZip(ctx, pw, parts))
+	}()
+	_, err = u.minioClient.PutObject(ctx, u.config.MinioBucketName, objectKey, pr, -1, minio.PutObjectOptions{
+		ContentType: "application/zip",
+	})
+	pr.CloseWithError(err)
+	if err != nil {
+		log.Println(err)
+		u.failDataExport(ctx, exportId, "unable to assemble archive")
+		return
+	}
+	expiresAt := time.Now().Add(dataExportRetention)
+	err = u.userDbQuries.CompleteDataExport(ctx, users.CompleteDataExportParams{
+		ExportID:  exportId,
+		ObjectKey: sql.NullString{String: objectKey, Valid: true},
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	// the archive holds everything now
+	err = u.userDbQuries.DeleteDataExportParts(ctx, exportId)
+	if err != nil {
+		log.Println(err)
+	}
+	msgBytes, err := json.Marshal(rabbitmq_producer.DataExportReadyMsg{
+		ExportId:  exportId,
+		UserId:    export.UserID,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	err = u.rabbitmqPorducer.Publish("user.export.ready", msgBytes)
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// writeDataExportZip writes each part under a directory named after the
+// service that produced it.
+func (u *UserService) writeDataExportZip(ctx context.Context, w io.Writer, parts []users.DataExportPart) error {
+	zw := zip.NewWriter(w)
+	for _, row := range parts {
+		var part DataExportPart
+		err := json.Unmarshal(row.Payload, &part)
+		if err != nil {
+			return err
+		}
+		for _, file := range part.Files {
+			entry, err := zw.Create(dataExportEntryName(row.Service, file.Name))
+			if err != nil {
+				return err
+			}
+			_, err = entry.Write(file.Content)
+			if err != nil {
+				return err
+			}
+		}
+		for _, object := range part.Objects {
+			err = u.copyObjectToZip(ctx, zw, dataExportEntryName(row.Service, object.Name), object)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return zw.Close()
+}
+
+func (u *UserService) copyObjectToZip(ctx context.Context, zw *zip.Writer, name string, object DataExportObject) error {
+	reader, err := u.minioClient.GetObject(ctx, object.Bucket, object.Key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	entry, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, reader)
+	return err
+}
+
+func (u *UserService) collectUserExportFiles(ctx context.Context, userId int32) ([]DataExportFile, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	user, err := u.userDbQuries.GetUserRecord(timeoutCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	relationships, err := u.userDbQuries.ListUserRelationships(timeoutCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if relationships == nil {
+		relationships = []users.ListUserRelationshipsRow{}
+	}
+	lists, err := u.userDbQuries.GetListsByOwner(timeoutCtx, users.GetListsByOwnerParams{
+		OwnerID:        userId,
+		IncludePrivate: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if lists == nil {
+		lists = []users.GetListsByOwnerRow{}
+	}
+	profile, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	relationshipsBytes, err := json.Marshal(relationships)
+	if err != nil {
+		return nil, err
+	}
+	listsBytes, err := json.Marshal(lists)
+	if err != nil {
+		return nil, err
+	}
+	return []DataExportFile{
+		{Name: "profile.json", Content: profile},
+		{Name: "relationships.json", Content: relationshipsBytes},
+		{Name: "lists.json", Content: listsBytes},
+	}, nil
+}
+
+func (u *UserService) dataExportStatus(ctx context.Context, export users.DataExport) (*DataExportStatus, error) {
+	status := &DataExportStatus{
+		ExportId:      export.ExportID,
+		Status:        export.Status,
+		PartsReceived: export.PartsReceived,
+		PartsExpected: export.PartsExpected,
+		CreatedAt:     export.CreatedAt,
+		Error:         export.Error.String,
+	}
+	if export.CompletedAt.Valid {
+		status.CompletedAt = &export.CompletedAt.Time
+	}
+	if export.ExpiresAt.Valid {
+		status.ExpiresAt = &export.ExpiresAt.Time
+	}
+	if export.Status != "ready" || !export.ObjectKey.Valid {
+		return status, nil
+	}
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=\"socialstream-export-%d.zip\"", export.ExportID))
+	downloadUrl, err := u.minioClient.PresignedGetObject(ctx, u.config.MinioBucketName, export.ObjectKey.String, dataExportLinkTTL, params)
+	if err != nil {
+		return nil, err
+	}
+	status.DownloadUrl = downloadUrl.String()
+	return status, nil
+}
+
+func (u *UserService) failDataExport(ctx context.Context, exportId int32, reason string) {
+	err := u.userDbQuries.FailDataExport(ctx, users.FailDataExportParams{
+		ExportID: exportId,
+		Error:    sql.NullString{String: reason, Valid: true},
+	})
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// dataExportEntryName keeps names supplied by other services inside their
+// own directory of the archive.
+func dataExportEntryName(service string, name string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+name), "/")
+	return path.Join(service, cleaned)
+}
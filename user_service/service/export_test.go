@@ -0,0 +1,27 @@
+package service
+
+import "testing"
+
+func TestDataExportEntryName(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		entry   string
+		want    string
+	}{
+		{name: "plain", service: "posts", entry: "posts.json", want: "posts/posts.json"},
+		{name: "nested", service: "media", entry: "images/1.jpg", want: "media/images/1.jpg"},
+		{name: "leading slash", service: "media", entry: "/etc/passwd", want: "media/etc/passwd"},
+		{name: "parent dirs", service: "posts", entry: "../../user/profile.json", want: "posts/user/profile.json"},
+		{name: "parent dirs mid path", service: "posts", entry: "a/../../b.json", want: "posts/b.json"},
+		{name: "empty", service: "posts", entry: "", want: "posts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dataExportEntryName(tt.service, tt.entry)
+			if got != tt.want {
+				t.Errorf("dataExportEntryName(%q, %q) = %q, want %q", tt.service, tt.entry, got, tt.want)
+			}
+		})
+	}
+}
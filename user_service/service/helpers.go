@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+func calculateOffsetAndLimit(pageNo int32, pageSize int32) (int32, int32) {
+	// account for 0 index pageNo
+	offset := (pageNo - 1) * pageSize
+	// fetch one extra row to check if it is the last page
+	limit := pageSize + 1
+	return offset, limit
+}
+
+// userCursor holds the sort key of the last user on a page. It is handed to
+// clients base64 encoded so they treat it as opaque.
+type userCursor struct {
+	Sort      string    `json:"s"`
+	UserId    int32     `json:"i,omitempty"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	Username  string    `json:"u,omitempty"`
+}
+
+func encodeUserCursor(cursor userCursor) (string, error) {
+	cursorBytes, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(cursorBytes), nil
+}
+
+func decodeUserCursor(encoded string, sort string) (userCursor, error) {
+	if encoded == "" {
+		return userCursor{Sort: sort}, nil
+	}
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return userCursor{}, errors.New("invalid cursor")
+	}
+	var cursor userCursor
+	err = json.Unmarshal(cursorBytes, &cursor)
+	if err != nil {
+		return userCursor{}, errors.New("invalid cursor")
+	}
+	if cursor.Sort != sort {
+		return userCursor{}, errors.New("cursor does not match sort")
+	}
+	return cursor, nil
+}
+
+// escapeLikePattern escapes LIKE wildcards so user input is matched literally.
+func escapeLikePattern(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+}
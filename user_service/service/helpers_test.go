@@ -0,0 +1,81 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUserCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor userCursor
+	}{
+		{name: "by id", cursor: userCursor{Sort: UserSortId, UserId: 42}},
+		{name: "by newest", cursor: userCursor{Sort: UserSortNewest, UserId: 7, CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 678000, time.UTC)}},
+		{name: "by username", cursor: userCursor{Sort: UserSortUsername, Username: "jane_doe"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := encodeUserCursor(tt.cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decodeUserCursor(encoded, tt.cursor.Sort)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Sort != tt.cursor.Sort || got.UserId != tt.cursor.UserId || got.Username != tt.cursor.Username || !got.CreatedAt.Equal(tt.cursor.CreatedAt) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeUserCursor(t *testing.T) {
+	byId, err := encodeUserCursor(userCursor{Sort: UserSortId, UserId: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		encoded string
+		sort    string
+		wantErr bool
+	}{
+		{name: "first page", encoded: "", sort: UserSortNewest},
+		{name: "matching sort", encoded: byId, sort: UserSortId},
+		{name: "cursor from another sort", encoded: byId, sort: UserSortUsername, wantErr: true},
+		{name: "not base64", encoded: "%%", sort: UserSortId, wantErr: true},
+		{name: "not json", encoded: "bm90IGpzb24", sort: UserSortId, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeUserCursor(tt.encoded, tt.sort)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeUserCursor error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.Sort != tt.sort {
+				t.Errorf("decoded sort = %q, want %q", got.Sort, tt.sort)
+			}
+		})
+	}
+}
+
+func TestEscapeLikePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "jane", want: "jane"},
+		{pattern: "jane_doe", want: `jane\_doe`},
+		{pattern: "100%", want: `100\%`},
+		{pattern: `back\slash`, want: `back\\slash`},
+		{pattern: `\_%`, want: `\\\_\%`},
+	}
+	for _, tt := range tests {
+		got := escapeLikePattern(tt.pattern)
+		if got != tt.want {
+			t.Errorf("escapeLikePattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
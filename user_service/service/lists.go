@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+const (
+	maxListsPerUser = 100
+	maxListMembers  = 1000
+)
+
+var (
+	ErrListNotFound     = errors.New("list not found")
+	ErrNotListOwner     = errors.New("only the list owner can change it")
+	ErrListLimitReached = errors.New("list limit reached")
+	ErrListFull         = errors.New("list has reached its member limit")
+)
+
+func (u *UserService) CreateList(ctx context.Context, ownerId int32, input CreateListInput) (users.GetListRow, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return users.GetListRow{}, errors.New("list name is required")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	// lock the owner so concurrent creates cannot go over the limit
+	_, err = txQuries.GetUserForUpdate(timeoutCtx, ownerId)
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	count, err := txQuries.CountListsByOwner(timeoutCtx, ownerId)
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	if count >= maxListsPerUser {
+		return users.GetListRow{}, ErrListLimitReached
+	}
+	list, err := txQuries.CreateList(timeoutCtx, users.CreateListParams{
+		OwnerID:     ownerId,
+		Name:        name,
+		Description: strings.TrimSpace(input.Description),
+		IsPrivate:   input.IsPrivate,
+	})
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	return users.GetListRow{
+		ListID:      list.ListID,
+		OwnerID:     list.OwnerID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPrivate:   list.IsPrivate,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}, nil
+}
+
+// GetList returns the list if the viewer may see it. Private lists are only
+// visible to their owner and look like they do not exist to anyone else.
+func (u *UserService) GetList(ctx context.Context, viewerId int32, listId int32) (users.GetListRow, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	list, err := u.userDbQuries.GetList(timeoutCtx, listId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return users.GetListRow{}, ErrListNotFound
+	}
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	if list.IsPrivate && list.OwnerID != viewerId {
+		return users.GetListRow{}, ErrListNotFound
+	}
+	return list, nil
+}
+
+func (u *UserService) GetUserLists(ctx context.Context, viewerId int32, ownerId int32) ([]users.GetListRow, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.GetListsByOwner(timeoutCtx, users.GetListsByOwnerParams{
+		OwnerID:        ownerId,
+		IncludePrivate: viewerId == ownerId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	lists := make([]users.GetListRow, 0, len(rows))
+	for _, row := range rows {
+		lists = append(lists, users.GetListRow(row))
+	}
+	return lists, nil
+}
+
+func (u *UserService) UpdateList(ctx context.Context, ownerId int32, listId int32, input UpdateListInput) (users.GetListRow, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	list, err := u.getOwnedListForUpdate(timeoutCtx, txQuries, ownerId, listId)
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	params := users.UpdateListParams{
+		ListID:      list.ListID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPrivate:   list.IsPrivate,
+	}
+	if input.Name != nil {
+		params.Name = strings.TrimSpace(*input.Name)
+		if params.Name == "" {
+			return users.GetListRow{}, errors.New("list name is required")
+		}
+	}
+	if input.Description != nil {
+		params.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.IsPrivate != nil {
+		params.IsPrivate = *input.IsPrivate
+	}
+	// member events are only published for public lists, so a list going
+	// public announces its members and one going private takes them back
+	var memberIds []int32
+	if params.IsPrivate != list.IsPrivate {
+		memberIds, err = txQuries.GetAllListMemberIds(timeoutCtx, listId)
+		if err != nil {
+			return users.GetListRow{}, err
+		}
+	}
+	err = txQuries.UpdateList(timeoutCtx, params)
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return users.GetListRow{}, err
+	}
+	topic := "user.list.member.added"
+	if params.IsPrivate {
+		topic = "user.list.member.removed"
+	}
+	for _, memberId := range memberIds {
+		err = u.publishListMemberEvent(topic, list.ListID, list.OwnerID, memberId)
+		if err != nil {
+			return users.GetListRow{}, err
+		}
+	}
+	return u.GetList(ctx, ownerId, listId)
+}
+
+func (u *UserService) DeleteList(ctx context.Context, ownerId int32, listId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	list, err := u.getOwnedListForUpdate(timeoutCtx, txQuries, ownerId, listId)
+	if err != nil {
+		return err
+	}
+	err = txQuries.DeleteList(timeoutCtx, listId)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if list.IsPrivate {
+		return nil
+	}
+	msgBytes, err := json.Marshal(rabbitmq_producer.ListDeletedMsg{
+		ListId:  list.ListID,
+		OwnerId: list.OwnerID,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.list.deleted", msgBytes)
+}
+
+// AddListMember adds userId to the list. Adding an existing member, a
+// deactivated user or a user blocked in either direction is a no-op.
+func (u *UserService) AddListMember(ctx context.Context, ownerId int32, listId int32, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	list, err := u.getOwnedListForUpdate(timeoutCtx, txQuries, ownerId, listId)
+	if err != nil {
+		return err
+	}
+	count, err := txQuries.CountListMembers(timeoutCtx, listId)
+	if err != nil {
+		return err
+	}
+	if count >= maxListMembers {
+		return ErrListFull
+	}
+	rows, err := txQuries.CreateListMember(timeoutCtx, users.CreateListMemberParams{
+		ListID:  listId,
+		UserID:  userId,
+		OwnerID: ownerId,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if rows == 0 || list.IsPrivate {
+		return nil
+	}
+	return u.publishListMemberEvent("user.list.member.added", list.ListID, list.OwnerID, userId)
+}
+
+func (u *UserService) RemoveListMember(ctx context.Context, ownerId int32, listId int32, userId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	list, err := u.getOwnedListForUpdate(timeoutCtx, txQuries, ownerId, listId)
+	if err != nil {
+		return err
+	}
+	rows, err := txQuries.DeleteListMember(timeoutCtx, users.DeleteListMemberParams{
+		ListID: listId,
+		UserID: userId,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if rows == 0 || list.IsPrivate {
+		return nil
+	}
+	return u.publishListMemberEvent("user.list.member.removed", list.ListID, list.OwnerID, userId)
+}
+
+func (u *UserService) GetListMembers(ctx context.Context, viewerId int32, listId int32, pageNo int32, pageSize int32) (*GetListMembersResp, error) {
+	_, err := u.GetList(ctx, viewerId, listId)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	offset, limit := calculateOffsetAndLimit(pageNo, pageSize)
+	rows, err := u.userDbQuries.GetListMembers(timeoutCtx, users.GetListMembersParams{
+		ListID: listId,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	isLastPage := len(rows) <= int(pageSize)
+	if !isLastPage {
+		rows = rows[:pageSize]
+	}
+	members := make([]ListMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, ListMember{
+			UserCard: UserCard{
+				UserId:         row.UserID,
+				Username:       row.Username,
+				FirstName:      row.Firstname,
+				LastName:       row.Lastname,
+				ProfileImageId: row.ProfileImageID.Int32,
+				Badge:          row.VerifiedBadge,
+			},
+			AddedAt: row.AddedAt,
+		})
+	}
+	return &GetListMembersResp{
+		Members:    members,
+		IsLastPage: isLastPage,
+	}, nil
+}
+
+// GetListMemberIds returns the ids of every active member, used by the post
+// service to build a list timeline.
+func (u *UserService) GetListMemberIds(ctx context.Context, viewerId int32, listId int32) ([]int32, error) {
+	_, err := u.GetList(ctx, viewerId, listId)
+	if err != nil {
+		return nil, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	memberIds, err := u.userDbQuries.GetListMemberIds(timeoutCtx, listId)
+	if err != nil {
+		return nil, err
+	}
+	if memberIds == nil {
+		memberIds = []int32{}
+	}
+	return memberIds, nil
+}
+
+func (u *UserService) getOwnedListForUpdate(ctx context.Context, txQuries *users.Queries, ownerId int32, listId int32) (users.List, error) {
+	list, err := txQuries.GetListForUpdate(ctx, listId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return users.List{}, ErrListNotFound
+	}
+	if err != nil {
+		return users.List{}, err
+	}
+	if list.OwnerID != ownerId {
+		if list.IsPrivate {
+			return users.List{}, ErrListNotFound
+		}
+		return users.List{}, ErrNotListOwner
+	}
+	return list, nil
+}
+
+// publishRemovedListMembers announces memberships dropped by a block, only
+// for public lists since private membership is never published.
+func (u *UserService) publishRemovedListMembers(removed []users.DeleteListMembershipsBetweenRow) {
+	for _, row := range removed {
+		if row.IsPrivate {
+			continue
+		}
+		err := u.publishListMemberEvent("user.list.member.removed", row.ListID, row.OwnerID, row.UserID)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+func (u *UserService) publishListMemberEvent(topic string, listId int32, ownerId int32, userId int32) error {
+	msgBytes, err := json.Marshal(rabbitmq_producer.ListMemberMsg{
+		ListId:  listId,
+		OwnerId: ownerId,
+		UserId:  userId,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish(topic, msgBytes)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestListMembership(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "listowner", "List", "Owner")
+	createTestUser(t, userService, 2, "member2", "Member", "Two")
+	createTestUser(t, userService, 3, "member3", "Member", "Three")
+
+	public, err := userService.CreateList(ctx, 1, CreateListInput{Name: " friends "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if public.Name != "friends" {
+		t.Errorf("list name = %q, want it trimmed", public.Name)
+	}
+	private, err := userService.CreateList(ctx, 1, CreateListInput{Name: "secret", IsPrivate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, listId := range []int32{public.ListID, private.ListID} {
+		for _, userId := range []int32{2, 3, 2} {
+			err := userService.AddListMember(ctx, 1, listId, userId)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	err = userService.AddListMember(ctx, 2, public.ListID, 3)
+	if !errors.Is(err, ErrNotListOwner) {
+		t.Errorf("adding to someone else's list = %v, want %v", err, ErrNotListOwner)
+	}
+	// a private list looks like it does not exist to anyone but its owner
+	_, err = userService.GetListMemberIds(ctx, 2, private.ListID)
+	if !errors.Is(err, ErrListNotFound) {
+		t.Errorf("reading a private list = %v, want %v", err, ErrListNotFound)
+	}
+	err = userService.AddListMember(ctx, 2, private.ListID, 3)
+	if !errors.Is(err, ErrListNotFound) {
+		t.Errorf("adding to a private list = %v, want %v", err, ErrListNotFound)
+	}
+	lists, err := userService.GetUserLists(ctx, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lists) != 1 || lists[0].ListID != public.ListID {
+		t.Errorf("lists seen by another user = %+v, want only the public list", lists)
+	}
+
+	// a block drops memberships between the two users
+	err = userService.BlockUser(ctx, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, listId := range []int32{public.ListID, private.ListID} {
+		memberIds, err := userService.GetListMemberIds(ctx, 1, listId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(memberIds, []int32{2}) {
+			t.Errorf("list %d members after block = %v, want [2]", listId, memberIds)
+		}
+	}
+	err = userService.RemoveListMember(ctx, 1, public.ListID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.DeleteList(ctx, 1, private.ListID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.DeleteList(ctx, 1, public.ListID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// private lists never publish their membership
+	want := []string{
+		"user.list.member.added",
+		"user.list.member.added",
+		"user.list.member.removed",
+		"user.blocked",
+		"user.list.member.removed",
+		"user.list.deleted",
+	}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestUpdateListPrivacy(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "listowner", "List", "Owner")
+	createTestUser(t, userService, 2, "member2", "Member", "Two")
+	createTestUser(t, userService, 3, "member3", "Member", "Three")
+
+	list, err := userService.CreateList(ctx, 1, CreateListInput{Name: "friends"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, userId := range []int32{2, 3} {
+		err := userService.AddListMember(ctx, 1, list.ListID, userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	isPrivate, isPublic, name := true, false, "close friends"
+	// only a change of privacy moves the members in or out of view
+	for _, input := range []UpdateListInput{
+		{IsPrivate: &isPrivate},
+		{IsPrivate: &isPrivate},
+		{Name: &name},
+		{IsPrivate: &isPublic},
+	} {
+		_, err := userService.UpdateList(ctx, 1, list.ListID, input)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"user.list.member.added",
+		"user.list.member.added",
+		"user.list.member.removed",
+		"user.list.member.removed",
+		"user.list.member.added",
+		"user.list.member.added",
+	}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
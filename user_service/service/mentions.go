@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+const MaxMentionUsernames = 50
+
+// ResolveMentions maps usernames, matched case insensitively, to the users
+// the author can mention. Unknown, deactivated and blocked users are left
+// out so the post service renders them as plain text.
+func (u *UserService) ResolveMentions(ctx context.Context, authorId int32, usernames []string) ([]MentionedUser, error) {
+	if len(usernames) > MaxMentionUsernames {
+		return nil, fmt.Errorf("at most %d usernames can be resolved at once", MaxMentionUsernames)
+	}
+	if len(usernames) == 0 {
+		return []MentionedUser{}, nil
+	}
+	lowered := make([]string, len(usernames))
+	for i, username := range usernames {
+		lowered[i] = strings.ToLower(username)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.GetMentionableUsers(timeoutCtx, users.GetMentionableUsersParams{
+		Usernames: lowered,
+		AuthorID:  authorId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	mentioned := make([]MentionedUser, 0, len(rows))
+	for _, row := range rows {
+		mentioned = append(mentioned, MentionedUser{
+			UserId:   row.UserID,
+			Username: row.Username,
+		})
+	}
+	return mentioned, nil
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestResolveMentions(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	userService.config.DeactivationGracePeriod = time.Hour
+	createTestUser(t, userService, 1, "author1", "Post", "Author")
+	createTestUser(t, userService, 2, "Mentioned2", "Mentioned", "User")
+	createTestUser(t, userService, 3, "blocker3", "Blocker", "User")
+	createTestUser(t, userService, 4, "leaving4", "Leaving", "User")
+
+	err := userService.BlockUser(ctx, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.DeleteUser(ctx, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mentioned, err := userService.ResolveMentions(ctx, 1, []string{"mentioned2", "BLOCKER3", "leaving4", "nobody", "author1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(mentioned, func(i, j int) bool {
+		return mentioned[i].UserId < mentioned[j].UserId
+	})
+	// blocked and deactivated users are left out, names keep their case
+	want := []MentionedUser{
+		{UserId: 1, Username: "author1"},
+		{UserId: 2, Username: "Mentioned2"},
+	}
+	if !reflect.DeepEqual(mentioned, want) {
+		t.Errorf("resolved %+v, want %+v", mentioned, want)
+	}
+}
@@ -1,6 +1,13 @@
 package service
 
-import "github.com/go-playground/validator/v10"
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/go-playground/validator/v10"
+)
 
 type CreateUserInput struct {
 	UserId    int32  `json:"userId" validate:"required"`
@@ -23,3 +30,180 @@ func Validate(input interface{}) error {
 	}
 	return nil
 }
+
+type SearchUsersReq struct {
+	Query    string
+	PageNo   int32
+	PageSize int32
+	// ViewerId hides users blocked by or blocking the viewer, 0 when anonymous
+	ViewerId int32
+}
+type SearchUsersResp struct {
+	Users      []users.SearchUsersRow `json:"users"`
+	IsLastPage bool                   `json:"isLastPage"`
+}
+
+const (
+	UserSortId       = "id"
+	UserSortNewest   = "newest"
+	UserSortUsername = "username"
+)
+
+type ListUsersReq struct {
+	// After is the opaque cursor returned as nextCursor by the previous page
+	After          string
+	Limit          int32
+	Sort           string
+	UsernamePrefix string
+	JoinedAfter    time.Time
+}
+type ListUsersResp struct {
+	Users      []UserProfile `json:"users"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// UserProfile is the public part of a user row, what clients get back. The
+// email, account lifecycle and privacy settings stay internal.
+type UserProfile struct {
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Firstname      string        `json:"firstname"`
+	Lastname       string        `json:"lastname"`
+	ProfileImageID sql.NullInt32 `json:"profileImageId"`
+	BannerImageID  sql.NullInt32 `json:"bannerImageId"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	VerifiedBadge  string        `json:"verifiedBadge"`
+	VerifiedAt     sql.NullTime  `json:"verifiedAt"`
+}
+
+func toUserProfile(user users.User) UserProfile {
+	return UserProfile{
+		UserID:         user.UserID,
+		Username:       user.Username,
+		Firstname:      user.Firstname,
+		Lastname:       user.Lastname,
+		ProfileImageID: user.ProfileImageID,
+		BannerImageID:  user.BannerImageID,
+		CreatedAt:      user.CreatedAt,
+		VerifiedBadge:  user.VerifiedBadge,
+		VerifiedAt:     user.VerifiedAt,
+	}
+}
+
+// OwnProfile is the profile users get back for their own account, the only
+// place their email is shown.
+type OwnProfile struct {
+	UserProfile
+	Email string `json:"email"`
+}
+
+func toOwnProfile(user users.User) OwnProfile {
+	return OwnProfile{
+		UserProfile: toUserProfile(user),
+		Email:       user.Email,
+	}
+}
+
+// UserCard is the compact profile other services use to render authors.
+type UserCard struct {
+	UserId         int32  `json:"userId"`
+	Username       string `json:"username"`
+	FirstName      string `json:"firstName"`
+	LastName       string `json:"lastName"`
+	ProfileImageId int32  `json:"profileImageId,omitempty"`
+	// Badge is the verification badge, empty for unverified users
+	Badge string `json:"badge,omitempty"`
+}
+
+// MentionedUser is a username from a post body resolved to its account.
+type MentionedUser struct {
+	UserId   int32  `json:"userId"`
+	Username string `json:"username"`
+}
+
+// DataExportPart is the slice of a user's data one service contributes to a
+// data export. Files are small JSON documents written into the archive as
+// is, Objects are MinIO objects streamed into it by the user service.
+type DataExportPart struct {
+	ExportId int32              `json:"exportId"`
+	Service  string             `json:"service"`
+	Files    []DataExportFile   `json:"files"`
+	Objects  []DataExportObject `json:"objects"`
+	// Error is set when the service could not collect its slice
+	Error string `json:"error,omitempty"`
+}
+type DataExportFile struct {
+	Name    string          `json:"name"`
+	Content json.RawMessage `json:"content"`
+}
+type DataExportObject struct {
+	Name   string `json:"name"`
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+}
+
+type DataExportStatus struct {
+	ExportId      int32      `json:"exportId"`
+	Status        string     `json:"status"`
+	PartsReceived int32      `json:"partsReceived"`
+	PartsExpected int32      `json:"partsExpected"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	// DownloadUrl is a presigned link, only set while the export is ready
+	DownloadUrl string `json:"downloadUrl,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+const (
+	PresenceEveryone  = "everyone"
+	PresenceFollowers = "followers"
+	PresenceNobody    = "nobody"
+)
+
+// UserPresence is what a viewer may know about a user being online. Hidden
+// users only carry their id.
+type UserPresence struct {
+	UserId   int32      `json:"userId"`
+	Online   bool       `json:"online"`
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+	Hidden   bool       `json:"hidden,omitempty"`
+}
+
+type CreateListInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"isPrivate"`
+}
+
+// UpdateListInput only changes the fields that are set.
+type UpdateListInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPrivate   *bool   `json:"isPrivate"`
+}
+
+type ListMember struct {
+	UserCard
+	AddedAt time.Time `json:"addedAt"`
+}
+type GetListMembersResp struct {
+	Members    []ListMember `json:"members"`
+	IsLastPage bool         `json:"isLastPage"`
+}
+
+const (
+	BadgeNotable  = "notable"
+	BadgeOfficial = "official"
+)
+
+// VerificationStatus is what a user sees about their own verification.
+type VerificationStatus struct {
+	Badge         string                     `json:"badge,omitempty"`
+	VerifiedAt    *time.Time                 `json:"verifiedAt,omitempty"`
+	LatestRequest *users.VerificationRequest `json:"latestRequest,omitempty"`
+}
+type ListVerificationRequestsResp struct {
+	Requests   []users.VerificationRequest `json:"requests"`
+	IsLastPage bool                        `json:"isLastPage"`
+}
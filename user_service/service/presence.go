@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// presenceOnlineTTL is how long a heartbeat keeps a user "active now"
+	presenceOnlineTTL = 2 * time.Minute
+	// presenceWriteInterval throttles heartbeats per user on each replica
+	presenceWriteInterval = 30 * time.Second
+	presenceLastSeenKey   = "presence:lastSeen"
+	activityMaxEntries    = 10000
+)
+
+var ErrInvalidPresenceVisibility = errors.New("visibility must be everyone, followers or nobody")
+
+func userOnlineKey(userId int32) string {
+	return fmt.Sprintf("user:%d:online", userId)
+}
+
+// activityThrottle remembers when each user's heartbeat was last written so
+// bursts of requests only reach redis once per interval. It also covers the
+// user.active events the post and media services publish per request.
+type activityThrottle struct {
+	mu       sync.Mutex
+	lastSeen map[int32]time.Time
+}
+
+func newActivityThrottle() *activityThrottle {
+	return &activityThrottle{
+		lastSeen: make(map[int32]time.Time),
+	}
+}
+
+func (t *activityThrottle) allow(userId int32, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if last, ok := t.lastSeen[userId]; ok && now.Sub(last) < presenceWriteInterval {
+		return false
+	}
+	if len(t.lastSeen) >= activityMaxEntries {
+		for id, last := range t.lastSeen {
+			if now.Sub(last) >= presenceWriteInterval {
+				delete(t.lastSeen, id)
+			}
+		}
+	}
+	t.lastSeen[userId] = now
+	return true
+}
+
+// RecordActivity is the presence heartbeat. It marks the user online for
+// presenceOnlineTTL and moves their last seen time forward.
+func (u *UserService) RecordActivity(ctx context.Context, userId int32) error {
+	now := time.Now()
+	if userId <= 0 || !u.activity.allow(userId, now) {
+		return nil
+	}
+	pipe := u.redisClient.Pipeline()
+	pipe.Set(ctx, userOnlineKey(userId), now.Unix(), presenceOnlineTTL)
+	pipe.ZAdd(ctx, presenceLastSeenKey, redis.Z{
+		Score:  float64(now.Unix()),
+		Member: userId,
+	})
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// GetPresence returns the presence of each requested user the viewer is
+// allowed to see, in request order. Unknown and deactivated users are left
+// out, users hiding their presence from the viewer come back as hidden.
+func (u *UserService) GetPresence(ctx context.Context, viewerId int32, userIds []int32) ([]UserPresence, error) {
+	if len(userIds) > MaxBatchUserIds {
+		return nil, fmt.Errorf("at most %d user ids can be requested at once", MaxBatchUserIds)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	ids := dedupeUserIds(userIds)
+	if len(ids) == 0 {
+		return []UserPresence{}, nil
+	}
+	access, err := u.userDbQuries.GetPresenceAccess(timeoutCtx, users.GetPresenceAccessParams{
+		ViewerID: viewerId,
+		UserIds:  ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[int32]bool, len(access))
+	for _, row := range access {
+		visible[row.UserID] = canSeePresence(viewerId, row)
+	}
+
+	visibleIds := []int32{}
+	members := []string{}
+	for _, id := range ids {
+		if visible[id] {
+			visibleIds = append(visibleIds, id)
+			members = append(members, strconv.Itoa(int(id)))
+		}
+	}
+	online := make(map[int32]bool, len(visibleIds))
+	lastSeen := make(map[int32]time.Time, len(visibleIds))
+	if len(visibleIds) > 0 {
+		pipe := u.redisClient.Pipeline()
+		onlineCmds := make([]*redis.IntCmd, len(visibleIds))
+		for i, id := range visibleIds {
+			onlineCmds[i] = pipe.Exists(timeoutCtx, userOnlineKey(id))
+		}
+		lastSeenCmd := pipe.ZMScore(timeoutCtx, presenceLastSeenKey, members...)
+		_, err = pipe.Exec(timeoutCtx)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, err
+		}
+		scores := lastSeenCmd.Val()
+		for i, id := range visibleIds {
+			online[id] = onlineCmds[i].Val() > 0
+			if i < len(scores) && scores[i] > 0 {
+				lastSeen[id] = time.Unix(int64(scores[i]), 0).UTC()
+			}
+		}
+	}
+
+	presence := make([]UserPresence, 0, len(ids))
+	for _, id := range ids {
+		isVisible, exists := visible[id]
+		if !exists {
+			continue
+		}
+		if !isVisible {
+			presence = append(presence, UserPresence{UserId: id, Hidden: true})
+			continue
+		}
+		p := UserPresence{UserId: id, Online: online[id]}
+		if seen, ok := lastSeen[id]; ok {
+			p.LastSeen = &seen
+		}
+		presence = append(presence, p)
+	}
+	return presence, nil
+}
+
+func (u *UserService) UpdatePresenceVisibility(ctx context.Context, userId int32, visibility string) error {
+	switch visibility {
+	case PresenceEveryone, PresenceFollowers, PresenceNobody:
+	default:
+		return ErrInvalidPresenceVisibility
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_, err := u.userDbQuries.UpdatePresenceVisibility(timeoutCtx, users.UpdatePresenceVisibilityParams{
+		UserID:             userId,
+		PresenceVisibility: visibility,
+	})
+	if err != nil {
+		return err
+	}
+	u.invalidateUserCache(ctx, userId)
+	return nil
+}
+
+// clearPresence forgets a purged user's heartbeat and last seen time.
+func (u *UserService) clearPresence(ctx context.Context, userId int32) error {
+	pipe := u.redisClient.Pipeline()
+	pipe.Del(ctx, userOnlineKey(userId))
+	pipe.ZRem(ctx, presenceLastSeenKey, userId)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func canSeePresence(viewerId int32, access users.GetPresenceAccessRow) bool {
+	if access.UserID == viewerId {
+		return true
+	}
+	if access.Blocked {
+		return false
+	}
+	switch access.PresenceVisibility {
+	case PresenceEveryone:
+		return true
+	case PresenceFollowers:
+		return viewerId != 0 && access.ViewerFollows
+	default:
+		return false
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+func TestCanSeePresence(t *testing.T) {
+	tests := []struct {
+		name     string
+		viewerId int32
+		access   users.GetPresenceAccessRow
+		want     bool
+	}{
+		{
+			name:     "own presence",
+			viewerId: 1,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceNobody},
+			want:     true,
+		},
+		{
+			name:     "everyone",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceEveryone},
+			want:     true,
+		},
+		{
+			name:     "everyone anonymous",
+			viewerId: 0,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceEveryone},
+			want:     true,
+		},
+		{
+			name:     "everyone blocked",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceEveryone, Blocked: true},
+			want:     false,
+		},
+		{
+			name:     "followers follower",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceFollowers, ViewerFollows: true},
+			want:     true,
+		},
+		{
+			name:     "followers non follower",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceFollowers},
+			want:     false,
+		},
+		{
+			name:     "followers anonymous",
+			viewerId: 0,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceFollowers, ViewerFollows: true},
+			want:     false,
+		},
+		{
+			name:     "nobody follower",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: PresenceNobody, ViewerFollows: true},
+			want:     false,
+		},
+		{
+			name:     "unknown visibility",
+			viewerId: 2,
+			access:   users.GetPresenceAccessRow{UserID: 1, PresenceVisibility: ""},
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := canSeePresence(tt.viewerId, tt.access)
+			if got != tt.want {
+				t.Errorf("canSeePresence(%d, %+v) = %v, want %v", tt.viewerId, tt.access, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivityThrottle(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		userId int32
+		at     time.Duration
+		want   bool
+	}{
+		{name: "first heartbeat", userId: 1, at: 0, want: true},
+		{name: "inside interval", userId: 1, at: presenceWriteInterval - time.Second, want: false},
+		{name: "other user", userId: 2, at: time.Second, want: true},
+		{name: "interval elapsed", userId: 1, at: presenceWriteInterval, want: true},
+		{name: "inside next interval", userId: 1, at: presenceWriteInterval + time.Second, want: false},
+	}
+	throttle := newActivityThrottle()
+	for _, tt := range tests {
+		got := throttle.allow(tt.userId, start.Add(tt.at))
+		if got != tt.want {
+			t.Errorf("%s: allow(%d) = %v, want %v", tt.name, tt.userId, got, tt.want)
+		}
+	}
+}
+
+func TestGetPresence(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	createTestUser(t, userService, 1, "viewer1", "Viewer", "One")
+	createTestUser(t, userService, 2, "public2", "Public", "Two")
+	createTestUser(t, userService, 3, "followers3", "Followers", "Three")
+	createTestUser(t, userService, 4, "hidden4", "Hidden", "Four")
+
+	for _, userId := range []int32{2, 3, 4} {
+		err := userService.RecordActivity(ctx, userId)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := userService.UpdatePresenceVisibility(ctx, 3, PresenceFollowers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.UpdatePresenceVisibility(ctx, 4, PresenceNobody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	presenceOf := func(viewerId int32) map[int32]bool {
+		t.Helper()
+		presence, err := userService.GetPresence(ctx, viewerId, []int32{2, 3, 4, 99})
+		if err != nil {
+			t.Fatal(err)
+		}
+		online := map[int32]bool{}
+		for _, p := range presence {
+			if p.Hidden != (p.LastSeen == nil) {
+				t.Errorf("user %d: hidden %v with last seen %v", p.UserId, p.Hidden, p.LastSeen)
+			}
+			online[p.UserId] = p.Online
+		}
+		return online
+	}
+
+	// unknown users are left out and hidden users are never online
+	want := map[int32]bool{2: true, 3: false, 4: false}
+	if got := presenceOf(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("presence before following = %v, want %v", got, want)
+	}
+	err = userService.FollowUser(ctx, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[int32]bool{2: true, 3: true, 4: false}
+	if got := presenceOf(1); !reflect.DeepEqual(got, want) {
+		t.Errorf("presence after following = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+const MaxBatchUserIds = 500
+
+func userProfileCacheKey(userId int32) string {
+	return fmt.Sprintf("user:%d:profile", userId)
+}
+
+func userProfileImageCacheKey(userId int32) string {
+	return fmt.Sprintf("user:%d:profileImage", userId)
+}
+
+func userBannerImageCacheKey(userId int32) string {
+	return fmt.Sprintf("user:%d:bannerImage", userId)
+}
+
+func userCardCacheKey(userId int32) string {
+	return fmt.Sprintf("user:%d:card", userId)
+}
+
+// userCacheKeys lists every cached entry derived from the user row.
+func userCacheKeys(userId int32) []string {
+	return []string{
+		userProfileCacheKey(userId),
+		userProfileImageCacheKey(userId),
+		userBannerImageCacheKey(userId),
+		userCardCacheKey(userId),
+	}
+}
+
+// GetUsersByIds returns profile cards for the given ids in request order,
+// skipping ids that do not exist. Cards are read through the profile cache
+// and only the misses are loaded from postgres.
+func (u *UserService) GetUsersByIds(ctx context.Context, userIds []int32) ([]UserCard, error) {
+	if len(userIds) > MaxBatchUserIds {
+		return nil, fmt.Errorf("at most %d user ids can be requested at once", MaxBatchUserIds)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	ids := dedupeUserIds(userIds)
+	if len(ids) == 0 {
+		return []UserCard{}, nil
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = userCardCacheKey(id)
+	}
+	cards := make(map[int32]UserCard, len(ids))
+	misses := []int32{}
+	for i, cached := range u.profileCache.getMany(timeoutCtx, keys) {
+		if cached == nil {
+			misses = append(misses, ids[i])
+			continue
+		}
+		if isNotFoundMarker(cached) {
+			u.profileCache.negativeHits.Add(1)
+			continue
+		}
+		var card UserCard
+		err := json.Unmarshal(cached, &card)
+		if err != nil {
+			log.Println(err)
+			misses = append(misses, ids[i])
+			continue
+		}
+		cards[ids[i]] = card
+	}
+
+	if len(misses) > 0 {
+		rows, err := u.userDbQuries.GetUserCardsByIds(timeoutCtx, misses)
+		if err != nil {
+			return nil, err
+		}
+		loaded := make(map[string][]byte, len(misses))
+		for _, row := range rows {
+			card := userCardFromRow(row)
+			cards[card.UserId] = card
+			cardBytes, err := json.Marshal(card)
+			if err != nil {
+				return nil, err
+			}
+			loaded[userCardCacheKey(card.UserId)] = cardBytes
+		}
+		for _, id := range misses {
+			if _, ok := cards[id]; !ok {
+				loaded[userCardCacheKey(id)] = notFoundMarker
+			}
+		}
+		u.profileCache.setMany(timeoutCtx, loaded)
+	}
+
+	result := make([]UserCard, 0, len(ids))
+	for _, id := range ids {
+		if card, ok := cards[id]; ok {
+			result = append(result, card)
+		}
+	}
+	return result, nil
+}
+
+// GetCacheStats returns this replica's profile cache counters.
+func (u *UserService) GetCacheStats() CacheStats {
+	return u.profileCache.stats()
+}
+
+// ListenForCacheInvalidations keeps this replica's local profile cache
+// coherent with invalidations published by the other replicas.
+func (u *UserService) ListenForCacheInvalidations(ctx context.Context) error {
+	return u.profileCache.listen(ctx, userCacheKeys)
+}
+
+// invalidateUserCache drops every cached entry derived from the user row.
+func (u *UserService) invalidateUserCache(ctx context.Context, userId int32) {
+	u.profileCache.invalidate(ctx, userId, userCacheKeys(userId)...)
+}
+
+func (u *UserService) loadUser(userId int32) func(context.Context) (users.User, error) {
+	return func(ctx context.Context) (users.User, error) {
+		return u.userDbQuries.GetUserById(ctx, userId)
+	}
+}
+
+func (u *UserService) loadUserProfileImageId(userId int32) func(context.Context) (int32, error) {
+	return func(ctx context.Context) (int32, error) {
+		mediaId, err := u.userDbQuries.GetUserProfileImageByUserId(ctx, userId)
+		if err != nil {
+			return 0, err
+		}
+		return mediaId.Int32, nil
+	}
+}
+
+func (u *UserService) loadUserBannerImageId(userId int32) func(context.Context) (int32, error) {
+	return func(ctx context.Context) (int32, error) {
+		mediaId, err := u.userDbQuries.GetUserBannerImageByUserId(ctx, userId)
+		if err != nil {
+			return 0, err
+		}
+		return mediaId.Int32, nil
+	}
+}
+
+func userCardFromRow(row users.GetUserCardsByIdsRow) UserCard {
+	return UserCard{
+		UserId:         row.UserID,
+		Username:       row.Username,
+		FirstName:      row.Firstname,
+		LastName:       row.Lastname,
+		ProfileImageId: row.ProfileImageID.Int32,
+		Badge:          row.VerifiedBadge,
+	}
+}
+
+func dedupeUserIds(userIds []int32) []int32 {
+	seen := make(map[int32]bool, len(userIds))
+	ids := make([]int32, 0, len(userIds))
+	for _, id := range userIds {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
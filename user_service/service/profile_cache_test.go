@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDedupeUserIds(t *testing.T) {
+	tests := []struct {
+		name string
+		ids  []int32
+		want []int32
+	}{
+		{name: "empty", ids: nil, want: []int32{}},
+		{name: "keeps order", ids: []int32{3, 1, 2}, want: []int32{3, 1, 2}},
+		{name: "drops repeats", ids: []int32{2, 2, 1, 2}, want: []int32{2, 1}},
+		{name: "drops invalid ids", ids: []int32{0, -4, 5}, want: []int32{5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dedupeUserIds(tt.ids)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupeUserIds(%v) = %v, want %v", tt.ids, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUsersByIds(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	createTestUser(t, userService, 1, "carduser1", "Card", "One")
+	createTestUser(t, userService, 2, "carduser2", "Card", "Two")
+	createTestUser(t, userService, 3, "carduser3", "Card", "Three")
+
+	cardIds := func(cards []UserCard) []int32 {
+		ids := []int32{}
+		for _, card := range cards {
+			ids = append(ids, card.UserId)
+		}
+		return ids
+	}
+	tests := []struct {
+		name string
+		ids  []int32
+		want []int32
+	}{
+		{name: "request order", ids: []int32{3, 1, 2}, want: []int32{3, 1, 2}},
+		{name: "unknown ids skipped", ids: []int32{2, 99, 1}, want: []int32{2, 1}},
+		{name: "repeats collapsed", ids: []int32{1, 1, 3}, want: []int32{1, 3}},
+		{name: "nothing requested", ids: []int32{}, want: []int32{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the second lookup is served from the cache
+			for _, pass := range []string{"cold", "cached"} {
+				cards, err := userService.GetUsersByIds(ctx, tt.ids)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := cardIds(cards); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("%s lookup of %v = %v, want %v", pass, tt.ids, got, tt.want)
+				}
+			}
+		})
+	}
+
+	err := userService.UpdateUserProfileImageId(ctx, 2, 77)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cards, err := userService.GetUsersByIds(ctx, []int32{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0].ProfileImageId != 77 {
+		t.Errorf("card after profile image update = %+v, want profile image 77", cards)
+	}
+
+	tooMany := make([]int32, MaxBatchUserIds+1)
+	for i := range tooMany {
+		tooMany[i] = int32(i + 1)
+	}
+	_, err = userService.GetUsersByIds(ctx, tooMany)
+	if err == nil {
+		t.Errorf("expected more than %d ids to be rejected", MaxBatchUserIds)
+	}
+}
+
+func TestUpdateUserBannerImageId(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	createTestUser(t, userService, 1, "banner1", "Banner", "One")
+
+	bannerImageIs := func(want int32) {
+		t.Helper()
+		// read twice so the second read comes from the cache
+		for i := 0; i < 2; i++ {
+			got, err := userService.GetUserBannerImage(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("banner image = %d, want %d", got, want)
+			}
+		}
+	}
+	bannerImageIs(0)
+	// every update invalidates the cached banner, clearing it included
+	for _, mediaId := range []int32{11, 12, 0} {
+		err := userService.UpdateUserBannerImageId(ctx, 1, mediaId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bannerImageIs(mediaId)
+	}
+}
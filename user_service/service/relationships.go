@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+)
+
+// MaxFollowsPageSize caps a page of GetFollowsPage.
+const MaxFollowsPageSize = 1000
+
+func (u *UserService) FollowUser(ctx context.Context, followerId int32, followeeId int32) error {
+	if followerId == followeeId {
+		return errors.New("cannot follow yourself")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.CreateFollow(timeoutCtx, users.CreateFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		return err
+	}
+	// already following or blocked in either direction
+	if rows == 0 {
+		return nil
+	}
+	return u.publishFollowEvent("user.followed", followerId, followeeId)
+}
+
+func (u *UserService) UnfollowUser(ctx context.Context, followerId int32, followeeId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.DeleteFollow(timeoutCtx, users.DeleteFollowParams{
+		FollowerID: followerId,
+		FolloweeID: followeeId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	return u.publishFollowEvent("user.unfollowed", followerId, followeeId)
+}
+
+// BlockUser records the block and removes any follow relationship and list
+// membership between the two users in both directions.
+func (u *UserService) BlockUser(ctx context.Context, blockerId int32, blockedId int32) error {
+	if blockerId == blockedId {
+		return errors.New("cannot block yourself")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	rows, err := txQuries.CreateBlock(timeoutCtx, users.CreateBlockParams{
+		BlockerID: blockerId,
+		BlockedID: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	err = txQuries.DeleteFollowsBetween(timeoutCtx, users.DeleteFollowsBetweenParams{
+		FollowerID: blockerId,
+		FolloweeID: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	removedMembers, err := txQuries.DeleteListMembershipsBetween(timeoutCtx, users.DeleteListMembershipsBetweenParams{
+		UserA: blockerId,
+		UserB: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	u.invalidateSuggestions(ctx, blockerId, blockedId)
+	u.publishRemovedListMembers(removedMembers)
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserBlockedMsg{
+		BlockerId: blockerId,
+		BlockedId: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.blocked", msgBytes)
+}
+
+func (u *UserService) UnblockUser(ctx context.Context, blockerId int32, blockedId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	rows, err := u.userDbQuries.DeleteBlock(timeoutCtx, users.DeleteBlockParams{
+		BlockerID: blockerId,
+		BlockedID: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return nil
+	}
+	u.invalidateSuggestions(ctx, blockerId, blockedId)
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserBlockedMsg{
+		BlockerId: blockerId,
+		BlockedId: blockedId,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.unblocked", msgBytes)
+}
+
+func (u *UserService) MuteUser(ctx context.Context, muterId int32, mutedId int32) error {
+	if muterId == mutedId {
+		return errors.New("cannot mute yourself")
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_, err := u.userDbQuries.CreateMute(timeoutCtx, users.CreateMuteParams{
+		MuterID: muterId,
+		MutedID: mutedId,
+	})
+	if err != nil {
+		return err
+	}
+	u.invalidateSuggestions(ctx, muterId)
+	return nil
+}
+
+func (u *UserService) UnmuteUser(ctx context.Context, muterId int32, mutedId int32) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	_, err := u.userDbQuries.DeleteMute(timeoutCtx, users.DeleteMuteParams{
+		MuterID: muterId,
+		MutedID: mutedId,
+	})
+	if err != nil {
+		return err
+	}
+	u.invalidateSuggestions(ctx, muterId)
+	return nil
+}
+
+func (u *UserService) publishFollowEvent(topic string, followerId int32, followeeId int32) error {
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserFollowedMsg{
+		FollowerId: followerId,
+		FolloweeId: followeeId,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish(topic, msgBytes)
+}
+
+// GetFollowedAmong returns the ids in followeeIds that followerId follows.
+func (u *UserService) GetFollowedAmong(ctx context.Context, followerId int32, followeeIds []int32) ([]int32, error) {
+	if len(followeeIds) > MaxBatchUserIds {
+		return nil, fmt.Errorf("at most %d user ids can be requested at once", MaxBatchUserIds)
+	}
+	if followerId <= 0 || len(followeeIds) == 0 {
+		return []int32{}, nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	followed, err := u.userDbQuries.GetFollowedAmong(timeoutCtx, users.GetFollowedAmongParams{
+		FollowerID:  followerId,
+		FolloweeIds: followeeIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if followed == nil {
+		followed = []int32{}
+	}
+	return followed, nil
+}
+
+// GetFollowsPage returns up to limit follows after the given pair, in
+// follower then followee order. Other services page through it to backfill
+// their copy of the follow graph.
+func (u *UserService) GetFollowsPage(ctx context.Context, afterFollowerId int32, afterFolloweeId int32, limit int32) ([]users.GetFollowsPageRow, error) {
+	if limit <= 0 || limit > MaxFollowsPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", MaxFollowsPageSize)
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	follows, err := u.userDbQuries.GetFollowsPage(timeoutCtx, users.GetFollowsPageParams{
+		AfterFollowerID: afterFollowerId,
+		AfterFolloweeID: afterFolloweeId,
+		Limit:           limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if follows == nil {
+		follows = []users.GetFollowsPageRow{}
+	}
+	return follows, nil
+}
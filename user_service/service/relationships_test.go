@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/pressly/goose/v3"
+)
+
+func TestFollowUser(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "follower1", "Follower", "One")
+	createTestUser(t, userService, 2, "followee2", "Followee", "Two")
+
+	err := userService.FollowUser(ctx, 1, 1)
+	if err == nil {
+		t.Error("expected following yourself to fail")
+	}
+	err = userService.FollowUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a repeated follow changes nothing and publishes nothing
+	err = userService.FollowUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.UnfollowUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.UnfollowUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"user.followed", "user.unfollowed"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestBlockUserRemovesFollows(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "blocker1", "Blocker", "One")
+	createTestUser(t, userService, 2, "blocked2", "Blocked", "Two")
+
+	for _, follow := range [][2]int32{{1, 2}, {2, 1}} {
+		err := userService.FollowUser(ctx, follow[0], follow[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := userService.BlockUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, follow := range [][2]int32{{1, 2}, {2, 1}} {
+		rows, err := userService.userDbQuries.DeleteFollow(ctx, users.DeleteFollowParams{
+			FollowerID: follow[0],
+			FolloweeID: follow[1],
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rows != 0 {
+			t.Errorf("follow %d -> %d survived the block", follow[0], follow[1])
+		}
+	}
+	// neither side can follow while the block stands
+	err = userService.FollowUser(ctx, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.UnblockUser(ctx, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"user.followed", "user.followed", "user.blocked", "user.unblocked"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
+
+func TestSearchUsersHidesBlocked(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	createTestUser(t, userService, 1, "viewer1", "Viewer", "One")
+	createTestUser(t, userService, 2, "samsmith", "Sam", "Smith")
+	createTestUser(t, userService, 3, "samsmythe", "Sam", "Smythe")
+
+	err := userService.BlockUser(ctx, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		viewerId int32
+		want     []int32
+	}{
+		{name: "anonymous sees everyone", viewerId: 0, want: []int32{2, 3}},
+		{name: "blocked viewer", viewerId: 1, want: []int32{2}},
+		{name: "blocker", viewerId: 3, want: []int32{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := userService.SearchUsers(ctx, SearchUsersReq{Query: "samsm", PageNo: 1, PageSize: 10, ViewerId: tt.viewerId})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []int32{}
+			for _, user := range resp.Users {
+				got = append(got, user.UserID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("search as %d = %v, want %v", tt.viewerId, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSuggestedUsers(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	for i, username := range []string{"suggest1", "suggest2", "suggest3", "suggest4", "suggest5", "suggest6", "suggest7"} {
+		createTestUser(t, userService, int32(i+1), username, "Test", "User")
+	}
+	// 1 follows 2 and 3, who both follow 4, 3 also follows 5, and 6 follows
+	// 1 and 5
+	for _, follow := range [][2]int32{{1, 2}, {1, 3}, {2, 4}, {3, 4}, {3, 5}, {6, 1}, {6, 5}} {
+		err := userService.FollowUser(ctx, follow[0], follow[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	suggestionIds := func() []int32 {
+		t.Helper()
+		suggestions, err := userService.GetSuggestedUsers(ctx, 1, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int32{}
+		for _, suggestion := range suggestions {
+			ids = append(ids, suggestion.UserID)
+		}
+		return ids
+	}
+
+	// friends of friends outrank mutual followers, which outrank new users
+	want := []int32{4, 5, 6, 7}
+	if got := suggestionIds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions = %v, want %v", got, want)
+	}
+	err := userService.MuteUser(ctx, 1, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.BlockUser(ctx, 7, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.HandleFollowChanged(ctx, 1, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []int32{4, 6}
+	if got := suggestionIds(); !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions after mute and block = %v, want %v", got, want)
+	}
+}
+
+func TestSuggestedUsersSkipLegacyUsers(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	// a user that signed up before join times were recorded
+	err := goose.DownTo(userService.userDb, migrationsDir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = userService.userDb.ExecContext(ctx, `INSERT INTO users (user_id, username, email, firstname, lastname)
+		VALUES (1, 'legacyuser', 'legacyuser@test.com', 'Legacy', 'User')`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = goose.Up(userService.userDb, migrationsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	createTestUser(t, userService, 2, "newuser1", "New", "User")
+	createTestUser(t, userService, 3, "newuser2", "New", "User")
+
+	legacy, err := userService.userDbQuries.GetUserById(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !legacy.CreatedAt.Equal(time.Unix(0, 0)) {
+		t.Errorf("legacy user created at %v, want the epoch", legacy.CreatedAt)
+	}
+	suggestions, err := userService.GetSuggestedUsers(ctx, 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int32{}
+	for _, suggestion := range suggestions {
+		ids = append(ids, suggestion.UserID)
+	}
+	if want := []int32{3}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("suggestions = %v, want only the user who just joined %v", ids, want)
+	}
+}
+
+func TestGetFollowsPage(t *testing.T) {
+	ctx := context.Background()
+	userService, _ := newTestUserService(t)
+	for i, username := range []string{"pageuser1", "pageuser2", "pageuser3"} {
+		createTestUser(t, userService, int32(i+1), username, "Page", "User")
+	}
+	for _, follow := range [][2]int32{{3, 1}, {1, 3}, {1, 2}, {2, 1}} {
+		err := userService.FollowUser(ctx, follow[0], follow[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := [][2]int32{}
+	var afterFollowerId, afterFolloweeId int32
+	for {
+		page, err := userService.GetFollowsPage(ctx, afterFollowerId, afterFolloweeId, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, row := range page {
+			got = append(got, [2]int32{row.FollowerID, row.FolloweeID})
+		}
+		if len(page) < 3 {
+			break
+		}
+		afterFollowerId, afterFolloweeId = page[len(page)-1].FollowerID, page[len(page)-1].FolloweeID
+	}
+	want := [][2]int32{{1, 2}, {1, 3}, {2, 1}, {3, 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("follows = %v, want %v", got, want)
+	}
+	_, err := userService.GetFollowsPage(ctx, 0, 0, MaxFollowsPageSize+1)
+	if err == nil {
+		t.Error("expected a page over the limit to fail")
+	}
+}
@@ -3,8 +3,6 @@ package service
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
-	"errors"
 	"time"
 
 	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
@@ -20,15 +18,26 @@ type UserService struct {
 	redisClient      *redis.Client
 	minioClient      *minio.Client
 	rpcClient        *rpc_client.RpcClient
-	rabbitmqPorducer *rabbitmq_producer.RabbitMQProducer
+	rabbitmqPorducer rabbitmq_producer.RabbitMQProducerInterface
+	profileCache     *profileCache
+	activity         *activityThrottle
 	config           *UserServiceConfig
 }
 type UserServiceConfig struct {
 	MinioBucketName string
+	// ProfileCacheTTL and NegativeCacheTTL fall back to defaults when zero
+	ProfileCacheTTL  time.Duration
+	NegativeCacheTTL time.Duration
+	// DeactivationGracePeriod is how long a deactivated account can still be
+	// restored before it is purged, defaults to 30 days when zero
+	DeactivationGracePeriod time.Duration
 }
 
 func New(userDb *sql.DB, redisClient *redis.Client, minioClient *minio.Client, rpcClient *rpc_client.RpcClient, rabbitmqProducer *rabbitmq_producer.RabbitMQProducer, config UserServiceConfig) (*UserService, error) {
 	userDbQueries := users.New(userDb)
+	if config.DeactivationGracePeriod <= 0 {
+		config.DeactivationGracePeriod = defaultDeactivationGracePeriod
+	}
 	err := setup(*minioClient, "user-service")
 	if err != nil {
 		return nil, err
@@ -40,6 +49,8 @@ func New(userDb *sql.DB, redisClient *redis.Client, minioClient *minio.Client, r
 		minioClient:      minioClient,
 		rpcClient:        rpcClient,
 		rabbitmqPorducer: rabbitmqProducer,
+		profileCache:     newProfileCache(redisClient, config.ProfileCacheTTL, config.NegativeCacheTTL),
+		activity:         newActivityThrottle(),
 		config:           &config,
 	}, nil
 }
@@ -55,34 +66,112 @@ func (u *UserService) CreateUser(ctx context.Context, createUserInput CreateUser
 	if err != nil {
 		return err
 	}
+	// drop any not-found entry cached before the user existed
+	u.invalidateUserCache(ctx, createUserInput.UserId)
 	return nil
 }
 
-func (u *UserService) GetAllUsers(ctx context.Context) ([]users.User, error) {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
-	defer cancel() // Make sure to call cancel to release resources when done
-	userChan := make(chan []users.User)
-	errChan := make(chan error)
+// ListUsers returns one keyset page of users in the requested sort order.
+// The page is resumed from an opaque cursor so deep pages stay as cheap as
+// the first one.
+func (u *UserService) ListUsers(ctx context.Context, req ListUsersReq) (*ListUsersResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	cursor, err := decodeUserCursor(req.After, req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	joinedAfter := sql.NullTime{
+		Time:  req.JoinedAfter,
+		Valid: !req.JoinedAfter.IsZero(),
+	}
+	usernamePrefix := escapeLikePattern(req.UsernamePrefix)
+	// fetch one extra row to know if there is a next page
+	limit := req.Limit + 1
 
+	usersCh := make(chan []users.User)
+	errCh := make(chan error)
 	go func() {
-		users, err := u.userDbQuries.ListUsers(ctx)
+		var page []users.User
+		var err error
+		switch req.Sort {
+		case UserSortNewest:
+			page, err = u.userDbQuries.ListUsersByNewest(timeoutCtx, users.ListUsersByNewestParams{
+				AfterCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: !cursor.CreatedAt.IsZero()},
+				AfterID:        cursor.UserId,
+				UsernamePrefix: usernamePrefix,
+				JoinedAfter:    joinedAfter,
+				PageLimit:      limit,
+			})
+		case UserSortUsername:
+			page, err = u.userDbQuries.ListUsersByUsername(timeoutCtx, users.ListUsersByUsernameParams{
+				AfterUsername:  cursor.Username,
+				UsernamePrefix: usernamePrefix,
+				JoinedAfter:    joinedAfter,
+				PageLimit:      limit,
+			})
+		default:
+			page, err = u.userDbQuries.ListUsersById(timeoutCtx, users.ListUsersByIdParams{
+				AfterID:        cursor.UserId,
+				UsernamePrefix: usernamePrefix,
+				JoinedAfter:    joinedAfter,
+				PageLimit:      limit,
+			})
+		}
 		if err != nil {
-			errChan <- err
+			errCh <- err
+			return
 		}
-		userChan <- users
+		usersCh <- page
 	}()
 	select {
-	case <-timeoutCtx.Done():
-		return nil, errors.New("get all users timedout")
-	case users := <-userChan:
-		return users, nil
-	case err := <-errChan:
+	case page := <-usersCh:
+		resp := &ListUsersResp{
+			Users: []UserProfile{},
+		}
+		if len(page) > int(req.Limit) {
+			page = page[:req.Limit]
+			last := page[len(page)-1]
+			resp.NextCursor, err = encodeUserCursor(userCursor{
+				Sort:      req.Sort,
+				UserId:    last.UserID,
+				CreatedAt: last.CreatedAt,
+				Username:  last.Username,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, user := range page {
+			resp.Users = append(resp.Users, toUserProfile(user))
+		}
+		return resp, nil
+	case err := <-errCh:
 		return nil, err
+	case <-timeoutCtx.Done():
+		return nil, timeoutCtx.Err()
+	}
+}
+
+func (u *UserService) GetUser(ctx context.Context, userId int32) (UserProfile, error) {
+	user, err := u.getUserRow(ctx, userId)
+	if err != nil {
+		return UserProfile{}, err
+	}
+	return toUserProfile(user), nil
+}
 
+// GetOwnProfile returns the profile of the signed in user, email included.
+func (u *UserService) GetOwnProfile(ctx context.Context, userId int32) (OwnProfile, error) {
+	user, err := u.getUserRow(ctx, userId)
+	if err != nil {
+		return OwnProfile{}, err
 	}
+	return toOwnProfile(user), nil
 }
 
-func (u *UserService) GetUser(ctx context.Context, userId int32) (users.User, error) {
+func (u *UserService) getUserRow(ctx context.Context, userId int32) (users.User, error) {
 	// Create a new context with a timeout of 200 milliseconds
 	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
 	defer cancel() // Make sure to call cancel to release resources when done
@@ -91,7 +180,7 @@ func (u *UserService) GetUser(ctx context.Context, userId int32) (users.User, er
 	errChan := make(chan error)
 
 	go func() {
-		user, err := u.userDbQuries.GetUserById(timeoutCtx, userId)
+		user, err := readThrough(timeoutCtx, u.profileCache, userProfileCacheKey(userId), u.loadUser(userId))
 		if err != nil {
 			errChan <- err
 			return
@@ -109,80 +198,126 @@ func (u *UserService) GetUser(ctx context.Context, userId int32) (users.User, er
 	}
 }
 
-func (u *UserService) UpdateUser(ctx context.Context, userId int32, updateUserInput UpdateUserInput) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+// SearchUsers fuzzy matches the query against username, first and last name
+// using the trigram indexes and returns the page ranked by similarity. Users
+// with a block relationship to the viewer are left out.
+func (u *UserService) SearchUsers(ctx context.Context, req SearchUsersReq) (*SearchUsersResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
 	defer cancel()
+
+	offset, limit := calculateOffsetAndLimit(req.PageNo, req.PageSize)
+	usersCh := make(chan []users.SearchUsersRow)
 	errCh := make(chan error)
-	successCh := make(chan interface{})
 	go func() {
-		err := u.userDbQuries.UpdateUser(timeoutCtx, users.UpdateUserParams{
-			UserID:  userId,
-			Column2: updateUserInput.Username,
-			Column3: updateUserInput.FirstName,
-			Column4: updateUserInput.LastName,
+		results, err := u.userDbQuries.SearchUsers(timeoutCtx, users.SearchUsersParams{
+			Query:      req.Query,
+			ViewerID:   req.ViewerId,
+			PageOffset: offset,
+			PageLimit:  limit,
 		})
 		if err != nil {
 			errCh <- err
 			return
 		}
-		successCh <- struct{}{}
+		usersCh <- results
 	}()
 	select {
+	case results := <-usersCh:
+		isLastPage := len(results) <= int(req.PageSize)
+		if !isLastPage {
+			results = results[:req.PageSize]
+		}
+		if results == nil {
+			results = []users.SearchUsersRow{}
+		}
+		return &SearchUsersResp{
+			Users:      results,
+			IsLastPage: isLastPage,
+		}, nil
 	case err := <-errCh:
-		return err
-	case <-successCh:
-		return nil
+		return nil, err
 	case <-timeoutCtx.Done():
-		return timeoutCtx.Err()
+		return nil, timeoutCtx.Err()
 	}
 }
 
-func (u *UserService) DeleteUser(ctx context.Context, userId int32) error {
-	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+// UpdateUser changes the profile names and, subject to the handle policy
+// and rename cool-down, the username. The authentication service reserves
+// the new handle before the rename is committed here and is asked to revert
+// it when the commit fails. A rename keeps the old handle in the username
+// history so it redirects to the profile for a while.
+func (u *UserService) UpdateUser(ctx context.Context, userId int32, updateUserInput UpdateUserInput) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
 	defer cancel()
+
+	var renamed *rabbitmq_producer.UserRenamedMsg
+	if updateUserInput.Username != "" {
+		var err error
+		renamed, err = u.reserveUsername(timeoutCtx, userId, updateUserInput.Username)
+		if err != nil {
+			return err
+		}
+	}
+	committed := false
+	defer func() {
+		if renamed != nil && !committed {
+			u.releaseUsername(renamed)
+		}
+	}()
+
 	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 	txQuries := u.userDbQuries.WithTx(tx)
+
 	errCh := make(chan error)
-	successCh := make(chan interface{})
+	successCh := make(chan struct{})
 	go func() {
-		err := txQuries.DeleteUser(timeoutCtx, userId)
-		if err != nil {
-			errCh <- err
-			return
+		if renamed != nil {
+			err := u.applyRename(timeoutCtx, txQuries, renamed)
+			if err != nil {
+				errCh <- err
+				return
+			}
 		}
-		msgBytes, err := json.Marshal(rabbitmq_producer.UserDeletedMsg{
-			UserId: userId,
+		err := txQuries.UpdateUser(timeoutCtx, users.UpdateUserParams{
+			UserID:  userId,
+			Column3: updateUserInput.FirstName,
+			Column4: updateUserInput.LastName,
 		})
 		if err != nil {
 			errCh <- err
 			return
 		}
-		u.rabbitmqPorducer.Publish("user.deleted", msgBytes)
 		successCh <- struct{}{}
 	}()
 	select {
 	case err := <-errCh:
-		tx.Rollback()
 		return err
 	case <-successCh:
-		tx.Commit()
+		err := tx.Commit()
+		if err != nil {
+			return err
+		}
+		committed = true
+		u.invalidateUserCache(ctx, userId)
+		if renamed != nil {
+			return u.publishUserRenamed(renamed)
+		}
 		return nil
 	case <-timeoutCtx.Done():
-		tx.Rollback()
 		return timeoutCtx.Err()
 	}
 }
 
 func (u *UserService) GetUserProfileImage(ctx context.Context, userId int32) (int32, error) {
-	mediaId, err := u.userDbQuries.GetUserProfileImageByUserId(ctx, userId)
+	mediaId, err := readThrough(ctx, u.profileCache, userProfileImageCacheKey(userId), u.loadUserProfileImageId(userId))
 	if err != nil {
 		return 0, err
 	}
-	return mediaId.Int32, nil
+	return mediaId, nil
 }
 
 func (u *UserService) UpdateUserProfileImageId(ctx context.Context, userId int32, mediaId int32) error {
@@ -196,5 +331,29 @@ func (u *UserService) UpdateUserProfileImageId(ctx context.Context, userId int32
 	if err != nil {
 		return err
 	}
+	u.invalidateUserCache(ctx, userId)
+	return nil
+}
+
+func (u *UserService) GetUserBannerImage(ctx context.Context, userId int32) (int32, error) {
+	mediaId, err := readThrough(ctx, u.profileCache, userBannerImageCacheKey(userId), u.loadUserBannerImageId(userId))
+	if err != nil {
+		return 0, err
+	}
+	return mediaId, nil
+}
+
+func (u *UserService) UpdateUserBannerImageId(ctx context.Context, userId int32, mediaId int32) error {
+	err := u.userDbQuries.UpdateUserBannerImage(ctx, users.UpdateUserBannerImageParams{
+		UserID: userId,
+		BannerImageID: sql.NullInt32{
+			Int32: mediaId,
+			Valid: mediaId > 0,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	u.invalidateUserCache(ctx, userId)
 	return nil
 }
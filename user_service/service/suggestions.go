@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	suggestionsCacheTTL = time.Hour
+	maxSuggestions      = 50
+)
+
+func suggestionsCacheKey(userId int32) string {
+	return fmt.Sprintf("user:%d:suggestions", userId)
+}
+
+// GetSuggestedUsers returns "people you may know" for the user, served from
+// the precomputed redis entry when present.
+func (u *UserService) GetSuggestedUsers(ctx context.Context, userId int32, limit int) ([]users.GetSuggestedUsersRow, error) {
+	var suggestions []users.GetSuggestedUsersRow
+	cached, err := u.redisClient.Get(ctx, suggestionsCacheKey(userId)).Bytes()
+	switch {
+	case err == nil:
+		err = json.Unmarshal(cached, &suggestions)
+		if err != nil {
+			log.Println(err)
+			suggestions, err = u.RefreshSuggestions(ctx, userId)
+		}
+	case errors.Is(err, redis.Nil):
+		suggestions, err = u.RefreshSuggestions(ctx, userId)
+	default:
+		// redis is unavailable, compute without caching
+		log.Println(err)
+		suggestions, err = u.RefreshSuggestions(ctx, userId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions, nil
+}
+
+// RefreshSuggestions recomputes suggestions from friends-of-friends, mutual
+// followers and recently joined users and stores them in redis.
+func (u *UserService) RefreshSuggestions(ctx context.Context, userId int32) ([]users.GetSuggestedUsersRow, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+
+	suggestions, err := u.userDbQuries.GetSuggestedUsers(timeoutCtx, users.GetSuggestedUsersParams{
+		UserID:     userId,
+		MaxResults: maxSuggestions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if suggestions == nil {
+		suggestions = []users.GetSuggestedUsersRow{}
+	}
+	msgBytes, err := json.Marshal(suggestions)
+	if err != nil {
+		return nil, err
+	}
+	err = u.redisClient.Set(ctx, suggestionsCacheKey(userId), msgBytes, suggestionsCacheTTL).Err()
+	if err != nil {
+		log.Println(err)
+	}
+	return suggestions, nil
+}
+
+func (u *UserService) invalidateSuggestions(ctx context.Context, userIds ...int32) {
+	keys := make([]string, 0, len(userIds))
+	for _, userId := range userIds {
+		keys = append(keys, suggestionsCacheKey(userId))
+	}
+	err := u.redisClient.Del(ctx, keys...).Err()
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+// HandleFollowChanged refreshes the follower's suggestions and drops the
+// followee's, whose mutual follower counts are now stale.
+func (u *UserService) HandleFollowChanged(ctx context.Context, followerId int32, followeeId int32) error {
+	u.invalidateSuggestions(ctx, followeeId)
+	_, err := u.RefreshSuggestions(ctx, followerId)
+	return err
+}
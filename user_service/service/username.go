@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	rpc_client "github.com/BernardN38/socialstream-backend/user_service/rpc/client"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/lib/pq"
+)
+
+const (
+	// usernameChangeCooldown is the minimum time between two renames
+	usernameChangeCooldown = 30 * 24 * time.Hour
+	// usernameHoldPeriod is how long an old handle keeps redirecting to its
+	// owner and cannot be claimed by anyone else
+	usernameHoldPeriod = 60 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidUsername  = errors.New("username must be 5 to 30 characters of letters, numbers or underscores")
+	ErrReservedUsername = errors.New("username is reserved")
+	ErrUsernameTaken    = errors.New("username is not available")
+	ErrUsernameCooldown = errors.New("username was changed too recently")
+	ErrRenameConflict   = errors.New("username was changed by another request")
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{5,30}$`)
+
+// reservedUsernames can never be taken, compared case-insensitively.
+// Keep in sync with the authentication service.
+var reservedUsernames = map[string]struct{}{
+	"admin":         {},
+	"administrator": {},
+	"api":           {},
+	"help":          {},
+	"moderator":     {},
+	"official":      {},
+	"root":          {},
+	"security":      {},
+	"settings":      {},
+	"socialstream":  {},
+	"staff":         {},
+	"support":       {},
+	"system":        {},
+	"null":          {},
+	"undefined":     {},
+}
+
+// ValidateUsername applies the handle policy shared with the authentication
+// service.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return ErrInvalidUsername
+	}
+	if _, ok := reservedUsernames[strings.ToLower(username)]; ok {
+		return ErrReservedUsername
+	}
+	return nil
+}
+
+// GetUserByUsername looks the handle up case-insensitively. A handle given
+// up by a recent rename resolves to its owner's current username, reported
+// through redirectTo so callers can point clients at the new profile.
+func (u *UserService) GetUserByUsername(ctx context.Context, username string) (profile UserProfile, redirectTo string, err error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	user, err := u.userDbQuries.GetUserByUsername(timeoutCtx, username)
+	if err == nil {
+		return toUserProfile(user), "", nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return UserProfile{}, "", err
+	}
+	redirect, err := u.userDbQuries.GetUsernameRedirect(timeoutCtx, username)
+	if err != nil {
+		return UserProfile{}, "", err
+	}
+	return UserProfile{}, redirect.Username, nil
+}
+
+// reserveUsername checks the rename against the policy and cool-down and has
+// the authentication service, which owns handles, apply it. It returns the
+// rename for applyRename, nil when the name did not change. The caller must
+// releaseUsername when its own copy of the rename is not committed.
+func (u *UserService) reserveUsername(ctx context.Context, userId int32, username string) (*rabbitmq_producer.UserRenamedMsg, error) {
+	user, err := u.userDbQuries.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == username {
+		return nil, nil
+	}
+	// grandfathered handles are checked on a change of case too, the
+	// authentication service enforces the format on every update
+	err = ValidateUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	renamed := &rabbitmq_producer.UserRenamedMsg{
+		UserId:      userId,
+		OldUsername: user.Username,
+		NewUsername: username,
+	}
+	// a change of case only is not a new handle, it is neither held nor
+	// subject to the cool-down
+	if !strings.EqualFold(user.Username, username) {
+		if user.UsernameChangedAt.Valid && time.Since(user.UsernameChangedAt.Time) < usernameChangeCooldown {
+			return nil, ErrUsernameCooldown
+		}
+		held, err := u.userDbQuries.IsUsernameHeldByOther(ctx, users.IsUsernameHeldByOtherParams{
+			Username: username,
+			UserID:   userId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			return nil, ErrUsernameTaken
+		}
+		renamed.HeldUntil = time.Now().Add(usernameHoldPeriod)
+	}
+	rejected, err := u.rpcClient.RenameUser(renameUserReq(renamed))
+	if err != nil {
+		return nil, err
+	}
+	switch rejected {
+	case "":
+		return renamed, nil
+	case "invalid":
+		return nil, ErrInvalidUsername
+	case "reserved":
+		return nil, ErrReservedUsername
+	case "taken":
+		return nil, ErrUsernameTaken
+	case "conflict":
+		return nil, ErrRenameConflict
+	default:
+		return nil, fmt.Errorf("rename rejected: %s", rejected)
+	}
+}
+
+// applyRename writes a reserved rename within the caller's transaction.
+func (u *UserService) applyRename(ctx context.Context, txQuries *users.Queries, renamed *rabbitmq_producer.UserRenamedMsg) error {
+	user, err := txQuries.GetUserForUpdate(ctx, renamed.UserId)
+	if err != nil {
+		return err
+	}
+	if user.Username != renamed.OldUsername {
+		return ErrRenameConflict
+	}
+	caseOnly := renamed.HeldUntil.IsZero()
+	err = txQuries.RenameUser(ctx, users.RenameUserParams{
+		UserID:        renamed.UserId,
+		Username:      renamed.NewUsername,
+		StartCooldown: !caseOnly,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrUsernameTaken
+		}
+		return err
+	}
+	if caseOnly {
+		return nil
+	}
+	return txQuries.CreateUsernameHistory(ctx, users.CreateUsernameHistoryParams{
+		UserID:    renamed.UserId,
+		Username:  renamed.OldUsername,
+		HeldUntil: renamed.HeldUntil,
+	})
+}
+
+// releaseUsername hands a reserved rename back to the authentication service.
+// A failure is logged, the handles then differ until the user renames again.
+func (u *UserService) releaseUsername(renamed *rabbitmq_producer.UserRenamedMsg) {
+	err := u.rpcClient.RevertRename(renameUserReq(renamed))
+	if err != nil {
+		log.Printf("revert rename of user %d: %v", renamed.UserId, err)
+	}
+}
+
+func renameUserReq(renamed *rabbitmq_producer.UserRenamedMsg) rpc_client.RenameUserReq {
+	return rpc_client.RenameUserReq{
+		UserId:      renamed.UserId,
+		OldUsername: renamed.OldUsername,
+		NewUsername: renamed.NewUsername,
+		HeldUntil:   renamed.HeldUntil,
+	}
+}
+
+func (u *UserService) publishUserRenamed(renamed *rabbitmq_producer.UserRenamedMsg) error {
+	msgBytes, err := json.Marshal(renamed)
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish("user.renamed", msgBytes)
+}
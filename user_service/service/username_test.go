@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     error
+	}{
+		{name: "valid", username: "jane_doe", want: nil},
+		{name: "digits", username: "user12345", want: nil},
+		{name: "min length", username: "abcde", want: nil},
+		{name: "max length", username: strings.Repeat("a", 30), want: nil},
+		{name: "too short", username: "abcd", want: ErrInvalidUsername},
+		{name: "too long", username: strings.Repeat("a", 31), want: ErrInvalidUsername},
+		{name: "empty", username: "", want: ErrInvalidUsername},
+		{name: "dot", username: "jane.doe", want: ErrInvalidUsername},
+		{name: "space", username: "jane doe", want: ErrInvalidUsername},
+		{name: "leading at", username: "@janedoe", want: ErrInvalidUsername},
+		{name: "non ascii", username: "jänedoe", want: ErrInvalidUsername},
+		{name: "reserved", username: "admin", want: ErrReservedUsername},
+		{name: "reserved support", username: "support", want: ErrReservedUsername},
+		{name: "reserved any case", username: "SuPPort", want: ErrReservedUsername},
+		{name: "reserved prefix is fine", username: "support_team", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsername(tt.username)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("ValidateUsername(%q) = %v, want %v", tt.username, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenameUser(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	fake := SetupRpcServer(t, userService)
+	createTestUser(t, userService, 1, "oldhandle", "Renamed", "User")
+	createTestUser(t, userService, 2, "otheruser", "Other", "User")
+
+	err := userService.UpdateUser(ctx, 1, UpdateUserInput{Username: "newhandle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the old handle redirects to the new one, in any case
+	_, redirectTo, err := userService.GetUserByUsername(ctx, "OldHandle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redirectTo != "newhandle" {
+		t.Errorf("redirect = %q, want newhandle", redirectTo)
+	}
+	// nobody else can claim the held handle
+	err = userService.UpdateUser(ctx, 2, UpdateUserInput{Username: "oldhandle"})
+	if !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("claiming a held handle = %v, want %v", err, ErrUsernameTaken)
+	}
+	err = userService.UpdateUser(ctx, 2, UpdateUserInput{Username: "NEWHANDLE"})
+	if !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("claiming a taken handle = %v, want %v", err, ErrUsernameTaken)
+	}
+	err = userService.UpdateUser(ctx, 1, UpdateUserInput{Username: "thirdhandle"})
+	if !errors.Is(err, ErrUsernameCooldown) {
+		t.Errorf("second rename = %v, want %v", err, ErrUsernameCooldown)
+	}
+	// the authentication service applied the rename of a taken handle
+	// before it failed here, so it is handed back
+	if got := fake.Reverted(); !reflect.DeepEqual(got, []string{"NEWHANDLE"}) {
+		t.Errorf("reverted renames %v, want [NEWHANDLE]", got)
+	}
+	want := []string{"user.renamed"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	rabbitmq_producer "github.com/BernardN38/socialstream-backend/user_service/rabbitmq/producer"
+	"github.com/BernardN38/socialstream-backend/user_service/sql/users"
+	"github.com/lib/pq"
+)
+
+const (
+	verificationPending  = "pending"
+	verificationApproved = "approved"
+	verificationRejected = "rejected"
+)
+
+var (
+	ErrInvalidBadge               = errors.New("badge must be notable or official")
+	ErrAlreadyVerified            = errors.New("user is already verified")
+	ErrNotVerified                = errors.New("user is not verified")
+	ErrVerificationPending        = errors.New("a verification request is already pending")
+	ErrVerificationReviewed       = errors.New("verification request was already reviewed")
+	ErrVerificationRequestMissing = errors.New("verification request not found")
+)
+
+func validBadge(badge string) bool {
+	return badge == BadgeNotable || badge == BadgeOfficial
+}
+
+// RequestVerification files a request for admin review. A user can only
+// have one pending request at a time.
+func (u *UserService) RequestVerification(ctx context.Context, userId int32, badge string, reason string) (users.VerificationRequest, error) {
+	if !validBadge(badge) {
+		return users.VerificationRequest{}, ErrInvalidBadge
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return users.VerificationRequest{}, err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	user, err := txQuries.GetUserForUpdate(timeoutCtx, userId)
+	if err != nil {
+		return users.VerificationRequest{}, err
+	}
+	if user.VerifiedBadge != "" {
+		return users.VerificationRequest{}, ErrAlreadyVerified
+	}
+	request, err := txQuries.CreateVerificationRequest(timeoutCtx, users.CreateVerificationRequestParams{
+		UserID: userId,
+		Badge:  badge,
+		Reason: reason,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return users.VerificationRequest{}, ErrVerificationPending
+		}
+		return users.VerificationRequest{}, err
+	}
+	err = txQuries.CreateVerificationAudit(timeoutCtx, users.CreateVerificationAuditParams{
+		UserID:    userId,
+		Action:    "requested",
+		Badge:     badge,
+		ActorID:   userId,
+		RequestID: sql.NullInt32{Int32: request.RequestID, Valid: true},
+	})
+	if err != nil {
+		return users.VerificationRequest{}, err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return users.VerificationRequest{}, err
+	}
+	return request, nil
+}
+
+func (u *UserService) GetVerificationStatus(ctx context.Context, userId int32) (*VerificationStatus, error) {
+	user, err := u.GetUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	status := &VerificationStatus{
+		Badge: user.VerifiedBadge,
+	}
+	if user.VerifiedAt.Valid {
+		status.VerifiedAt = &user.VerifiedAt.Time
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	request, err := u.userDbQuries.GetLatestVerificationRequest(timeoutCtx, userId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		status.LatestRequest = &request
+	}
+	return status, nil
+}
+
+func (u *UserService) ListVerificationRequests(ctx context.Context, status string, pageNo int32, pageSize int32) (*ListVerificationRequestsResp, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	offset, limit := calculateOffsetAndLimit(pageNo, pageSize)
+	requests, err := u.userDbQuries.ListVerificationRequests(timeoutCtx, users.ListVerificationRequestsParams{
+		Status: status,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	isLastPage := len(requests) <= int(pageSize)
+	if !isLastPage {
+		requests = requests[:pageSize]
+	}
+	if requests == nil {
+		requests = []users.VerificationRequest{}
+	}
+	return &ListVerificationRequestsResp{
+		Requests:   requests,
+		IsLastPage: isLastPage,
+	}, nil
+}
+
+// ReviewVerificationRequest approves or rejects a pending request. Approving
+// grants the requested badge in the same transaction.
+func (u *UserService) ReviewVerificationRequest(ctx context.Context, adminId int32, requestId int32, approve bool, note string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	request, err := txQuries.GetVerificationRequestForUpdate(timeoutCtx, requestId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVerificationRequestMissing
+	}
+	if err != nil {
+		return err
+	}
+	if request.Status != verificationPending {
+		return ErrVerificationReviewed
+	}
+	status, action := verificationRejected, "rejected"
+	if approve {
+		status, action = verificationApproved, "approved"
+	}
+	err = txQuries.ReviewVerificationRequest(timeoutCtx, users.ReviewVerificationRequestParams{
+		RequestID:  requestId,
+		Status:     status,
+		ReviewerID: sql.NullInt32{Int32: adminId, Valid: true},
+		ReviewNote: note,
+	})
+	if err != nil {
+		return err
+	}
+	err = txQuries.CreateVerificationAudit(timeoutCtx, users.CreateVerificationAuditParams{
+		UserID:    request.UserID,
+		Action:    action,
+		Badge:     request.Badge,
+		ActorID:   adminId,
+		RequestID: sql.NullInt32{Int32: requestId, Valid: true},
+		Note:      note,
+	})
+	if err != nil {
+		return err
+	}
+	if approve {
+		err = u.grantBadge(timeoutCtx, txQuries, adminId, request.UserID, request.Badge, requestId, note)
+		if err != nil {
+			return err
+		}
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	if !approve {
+		return nil
+	}
+	return u.publishVerificationEvent(ctx, "user.verified", request.UserID, request.Badge)
+}
+
+// GrantVerification gives a badge directly, without a request. Granting
+// replaces any badge the user already holds.
+func (u *UserService) GrantVerification(ctx context.Context, adminId int32, userId int32, badge string, note string) error {
+	if !validBadge(badge) {
+		return ErrInvalidBadge
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	err = u.grantBadge(timeoutCtx, txQuries, adminId, userId, badge, 0, note)
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return u.publishVerificationEvent(ctx, "user.verified", userId, badge)
+}
+
+func (u *UserService) RevokeVerification(ctx context.Context, adminId int32, userId int32, note string) error {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancel()
+
+	tx, err := u.userDb.BeginTx(timeoutCtx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	txQuries := u.userDbQuries.WithTx(tx)
+
+	user, err := txQuries.GetUserForUpdate(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	if user.VerifiedBadge == "" {
+		return ErrNotVerified
+	}
+	err = txQuries.ClearUserVerifiedBadge(timeoutCtx, userId)
+	if err != nil {
+		return err
+	}
+	err = txQuries.CreateVerificationAudit(timeoutCtx, users.CreateVerificationAuditParams{
+		UserID:  userId,
+		Action:  "revoked",
+		Badge:   user.VerifiedBadge,
+		ActorID: adminId,
+		Note:    note,
+	})
+	if err != nil {
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return u.publishVerificationEvent(ctx, "user.unverified", userId, "")
+}
+
+func (u *UserService) GetVerificationAudit(ctx context.Context, userId int32) ([]users.VerificationAudit, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+
+	entries, err := u.userDbQuries.ListVerificationAudit(timeoutCtx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []users.VerificationAudit{}
+	}
+	return entries, nil
+}
+
+// grantBadge sets the badge and records it in the audit trail. requestId is
+// 0 for grants made without a request.
+func (u *UserService) grantBadge(ctx context.Context, txQuries *users.Queries, adminId int32, userId int32, badge string, requestId int32, note string) error {
+	rows, err := txQuries.SetUserVerifiedBadge(ctx, users.SetUserVerifiedBadgeParams{
+		UserID:        userId,
+		VerifiedBadge: badge,
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return txQuries.CreateVerificationAudit(ctx, users.CreateVerificationAuditParams{
+		UserID:    userId,
+		Action:    "granted",
+		Badge:     badge,
+		ActorID:   adminId,
+		RequestID: sql.NullInt32{Int32: requestId, Valid: requestId > 0},
+		Note:      note,
+	})
+}
+
+func (u *UserService) publishVerificationEvent(ctx context.Context, topic string, userId int32, badge string) error {
+	u.invalidateUserCache(ctx, userId)
+	msgBytes, err := json.Marshal(rabbitmq_producer.UserVerifiedMsg{
+		UserId: userId,
+		Badge:  badge,
+	})
+	if err != nil {
+		return err
+	}
+	return u.rabbitmqPorducer.Publish(topic, msgBytes)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestVerificationReview(t *testing.T) {
+	ctx := context.Background()
+	userService, producer := newTestUserService(t)
+	createTestUser(t, userService, 1, "verifyme", "Verify", "Me")
+	createTestUser(t, userService, 2, "reviewer", "Review", "Er")
+
+	_, err := userService.RequestVerification(ctx, 1, "famous", "")
+	if !errors.Is(err, ErrInvalidBadge) {
+		t.Errorf("requesting an unknown badge = %v, want %v", err, ErrInvalidBadge)
+	}
+	request, err := userService.RequestVerification(ctx, 1, BadgeNotable, "I write books")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = userService.RequestVerification(ctx, 1, BadgeOfficial, "")
+	if !errors.Is(err, ErrVerificationPending) {
+		t.Errorf("second pending request = %v, want %v", err, ErrVerificationPending)
+	}
+	err = userService.ReviewVerificationRequest(ctx, 2, request.RequestID, true, "looks good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.ReviewVerificationRequest(ctx, 2, request.RequestID, false, "")
+	if !errors.Is(err, ErrVerificationReviewed) {
+		t.Errorf("reviewing twice = %v, want %v", err, ErrVerificationReviewed)
+	}
+	status, err := userService.GetVerificationStatus(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Badge != BadgeNotable || status.VerifiedAt == nil {
+		t.Errorf("status after approval = %+v, want a notable badge", status)
+	}
+	_, err = userService.RequestVerification(ctx, 1, BadgeOfficial, "")
+	if !errors.Is(err, ErrAlreadyVerified) {
+		t.Errorf("requesting while verified = %v, want %v", err, ErrAlreadyVerified)
+	}
+	err = userService.RevokeVerification(ctx, 2, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = userService.RevokeVerification(ctx, 2, 1, "")
+	if !errors.Is(err, ErrNotVerified) {
+		t.Errorf("revoking twice = %v, want %v", err, ErrNotVerified)
+	}
+
+	entries, err := userService.GetVerificationAudit(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := []string{}
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	sort.Strings(actions)
+	wantActions := []string{"approved", "granted", "requested", "revoked"}
+	if !reflect.DeepEqual(actions, wantActions) {
+		t.Errorf("audit actions = %v, want %v", actions, wantActions)
+	}
+	// the trail outlives the purged account
+	err = userService.userDbQuries.DeleteUser(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err = userService.GetVerificationAudit(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(wantActions) {
+		t.Errorf("audit after purge has %d entries, want %d", len(entries), len(wantActions))
+	}
+	want := []string{"user.verified", "user.unverified"}
+	if got := producer.Published(); !reflect.DeepEqual(got, want) {
+		t.Errorf("published %v, want %v", got, want)
+	}
+}
@@ -1,22 +1,88 @@
 -- name: GetUserById :one
 SELECT *
 FROM users
-WHERE user_id = $1 LIMIT 1;
+WHERE user_id = $1 AND deactivated_at IS NULL LIMIT 1;
 
 -- name: GetUserByUsername :one
 SELECT *
 FROM users
-WHERE username = $1 LIMIT 1;
+WHERE LOWER(username) = LOWER(sqlc.arg(username)::text) AND deactivated_at IS NULL LIMIT 1;
+
+-- name: GetUsernameRedirect :one
+SELECT u.user_id, u.username
+FROM username_history h
+JOIN users u ON u.user_id = h.user_id
+WHERE LOWER(h.username) = LOWER(sqlc.arg(username)::text)
+  AND h.held_until > NOW()
+  AND u.deactivated_at IS NULL
+ORDER BY h.changed_at DESC
+LIMIT 1;
+
+-- name: IsUsernameHeldByOther :one
+SELECT EXISTS (
+    SELECT 1 FROM username_history
+    WHERE LOWER(username) = LOWER(sqlc.arg(username)::text)
+      AND held_until > NOW()
+      AND user_id <> sqlc.arg(user_id)::int
+)::bool;
+
+-- name: GetUserForUpdate :one
+SELECT *
+FROM users
+WHERE user_id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: RenameUser :exec
+-- a change of case only does not start the rename cool-down
+UPDATE users
+SET username = $2,
+    username_changed_at = CASE WHEN sqlc.arg(start_cooldown)::bool THEN NOW() ELSE username_changed_at END
+WHERE user_id = $1;
+
+-- name: CreateUsernameHistory :exec
+INSERT INTO username_history(user_id, username, held_until)
+VALUES ($1, $2, $3);
 
 -- name: GetUserProfileImageByUserId :one
 SELECT profile_image_id
 FROM users
 WHERE user_id = $1 LIMIT 1;
 
--- name: ListUsers :many
+-- name: GetUserBannerImageByUserId :one
+SELECT banner_image_id
+FROM users
+WHERE user_id = $1 LIMIT 1;
+
+-- name: ListUsersById :many
+SELECT *
+FROM users
+WHERE user_id > sqlc.arg(after_id)::int
+  AND deactivated_at IS NULL
+  AND (sqlc.arg(username_prefix)::text = '' OR username ILIKE sqlc.arg(username_prefix)::text || '%')
+  AND (sqlc.narg(joined_after)::timestamptz IS NULL OR created_at > sqlc.narg(joined_after)::timestamptz)
+ORDER BY user_id
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListUsersByNewest :many
+SELECT *
+FROM users
+WHERE (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, user_id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.arg(after_id)::int))
+  AND deactivated_at IS NULL
+  AND (sqlc.arg(username_prefix)::text = '' OR username ILIKE sqlc.arg(username_prefix)::text || '%')
+  AND (sqlc.narg(joined_after)::timestamptz IS NULL OR created_at > sqlc.narg(joined_after)::timestamptz)
+ORDER BY created_at DESC, user_id DESC
+LIMIT sqlc.arg(page_limit)::int;
+
+-- name: ListUsersByUsername :many
 SELECT *
 FROM users
-ORDER BY user_id;
+WHERE username > sqlc.arg(after_username)::text
+  AND deactivated_at IS NULL
+  AND (sqlc.arg(username_prefix)::text = '' OR username ILIKE sqlc.arg(username_prefix)::text || '%')
+  AND (sqlc.narg(joined_after)::timestamptz IS NULL OR created_at > sqlc.narg(joined_after)::timestamptz)
+ORDER BY username
+LIMIT sqlc.arg(page_limit)::int;
 
 -- name: CreateUser :one
 INSERT INTO users(user_id, username,email, firstname,lastname)
@@ -32,7 +98,360 @@ WHERE user_id = $1;
 -- name: UpdateUserProfileImage :exec
 UPDATE users SET profile_image_id = $2 WHERE user_id = $1;
 
+-- name: UpdateUserBannerImage :exec
+UPDATE users SET banner_image_id = $2 WHERE user_id = $1;
+
 -- name: DeleteUser :exec
 DELETE
 FROM users
-WHERE user_id = $1;
\ No newline at end of file
+WHERE user_id = $1;
+
+-- name: DeactivateUser :execrows
+UPDATE users SET deactivated_at = NOW(), purge_after = sqlc.arg(purge_after)::timestamptz
+WHERE user_id = sqlc.arg(user_id)::int AND deactivated_at IS NULL;
+
+-- name: ReactivateUser :execrows
+UPDATE users SET deactivated_at = NULL, purge_after = NULL
+WHERE user_id = $1 AND deactivated_at IS NOT NULL;
+
+-- name: GetUsersDueForPurge :many
+SELECT user_id
+FROM users
+WHERE purge_after <= NOW()
+ORDER BY purge_after
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: SearchUsers :many
+SELECT user_id, username, firstname, lastname, profile_image_id,
+       GREATEST(
+           similarity(username, sqlc.arg(query)::text),
+           similarity(firstname, sqlc.arg(query)::text),
+           similarity(lastname, sqlc.arg(query)::text),
+           similarity(firstname || ' ' || lastname, sqlc.arg(query)::text)
+       )::real AS rank
+FROM users
+WHERE (username % sqlc.arg(query)::text
+    OR firstname % sqlc.arg(query)::text
+    OR lastname % sqlc.arg(query)::text
+    OR username ILIKE sqlc.arg(query)::text || '%')
+  AND deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = sqlc.arg(viewer_id)::int AND b.blocked_id = users.user_id)
+         OR (b.blocker_id = users.user_id AND b.blocked_id = sqlc.arg(viewer_id)::int)
+  )
+ORDER BY rank DESC, user_id
+LIMIT sqlc.arg(page_limit)::int OFFSET sqlc.arg(page_offset)::int;
+
+
+-- name: CreateFollow :execrows
+INSERT INTO follows(follower_id, followee_id)
+SELECT $1, $2
+WHERE NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = $1 AND blocked_id = $2)
+       OR (blocker_id = $2 AND blocked_id = $1)
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = $1 AND followee_id = $2)
+   OR (follower_id = $2 AND followee_id = $1);
+
+-- name: GetFollowedAmong :many
+SELECT followee_id FROM follows
+WHERE follower_id = sqlc.arg(follower_id)::int
+  AND followee_id = ANY(sqlc.arg(followee_ids)::int[]);
+
+-- name: GetFollowsPage :many
+-- every follow in primary key order, used to backfill copies of the graph
+SELECT follower_id, followee_id FROM follows
+WHERE (follower_id, followee_id) > (sqlc.arg(after_follower_id)::int, sqlc.arg(after_followee_id)::int)
+ORDER BY follower_id, followee_id
+LIMIT $1;
+
+-- name: CreateBlock :execrows
+INSERT INTO blocks(blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: DeleteBlock :execrows
+DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: CreateMute :execrows
+INSERT INTO mutes(muter_id, muted_id) VALUES ($1, $2) ON CONFLICT DO NOTHING;
+
+-- name: DeleteMute :execrows
+DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetSuggestedUsers :many
+WITH excluded AS (
+    SELECT sqlc.arg(user_id)::int AS user_id
+    UNION SELECT followee_id FROM follows WHERE follower_id = sqlc.arg(user_id)::int
+    UNION SELECT blocked_id FROM blocks WHERE blocker_id = sqlc.arg(user_id)::int
+    UNION SELECT blocker_id FROM blocks WHERE blocked_id = sqlc.arg(user_id)::int
+    UNION SELECT muted_id FROM mutes WHERE muter_id = sqlc.arg(user_id)::int
+), friends_of_friends AS (
+    SELECT f2.followee_id AS user_id, COUNT(*) AS score
+    FROM follows f1
+    JOIN follows f2 ON f2.follower_id = f1.followee_id
+    WHERE f1.follower_id = sqlc.arg(user_id)::int
+    GROUP BY f2.followee_id
+), mutual_followers AS (
+    SELECT f2.followee_id AS user_id, COUNT(*) AS score
+    FROM follows f1
+    JOIN follows f2 ON f2.follower_id = f1.follower_id
+    WHERE f1.followee_id = sqlc.arg(user_id)::int
+    GROUP BY f2.followee_id
+), recently_joined AS (
+    SELECT user_id
+    FROM users
+    WHERE created_at > NOW() - INTERVAL '14 days'
+      AND deactivated_at IS NULL
+    ORDER BY created_at DESC
+    LIMIT 100
+)
+SELECT u.user_id, u.username, u.firstname, u.lastname, u.profile_image_id,
+       COALESCE(fof.score, 0)::int AS mutual_follows,
+       COALESCE(mf.score, 0)::int AS mutual_followers,
+       (rj.user_id IS NOT NULL)::bool AS recently_joined
+FROM users u
+LEFT JOIN friends_of_friends fof ON fof.user_id = u.user_id
+LEFT JOIN mutual_followers mf ON mf.user_id = u.user_id
+LEFT JOIN recently_joined rj ON rj.user_id = u.user_id
+WHERE (fof.user_id IS NOT NULL OR mf.user_id IS NOT NULL OR rj.user_id IS NOT NULL)
+  AND u.user_id NOT IN (SELECT user_id FROM excluded)
+  AND u.deactivated_at IS NULL
+ORDER BY COALESCE(fof.score, 0) * 3 + COALESCE(mf.score, 0) * 2 + (rj.user_id IS NOT NULL)::int DESC, u.user_id
+LIMIT sqlc.arg(max_results)::int;
+
+-- name: GetUserCardsByIds :many
+SELECT user_id, username, firstname, lastname, profile_image_id, verified_badge
+FROM users
+WHERE user_id = ANY(sqlc.arg(user_ids)::int[])
+  AND deactivated_at IS NULL;
+
+-- name: GetMentionableUsers :many
+-- active users the author may mention, blocks in either direction excluded
+SELECT u.user_id, u.username
+FROM users u
+WHERE LOWER(u.username) = ANY(sqlc.arg(usernames)::text[])
+  AND u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = u.user_id AND b.blocked_id = sqlc.arg(author_id)::int)
+         OR (b.blocker_id = sqlc.arg(author_id)::int AND b.blocked_id = u.user_id)
+  );
+
+-- name: GetUserRecord :one
+SELECT *
+FROM users
+WHERE user_id = $1 LIMIT 1;
+
+-- name: ListUserRelationships :many
+SELECT 'following'::text AS kind, followee_id AS other_user_id, created_at FROM follows WHERE follower_id = sqlc.arg(user_id)::int
+UNION ALL
+SELECT 'follower'::text, follower_id, created_at FROM follows WHERE followee_id = sqlc.arg(user_id)::int
+UNION ALL
+SELECT 'blocked'::text, blocked_id, created_at FROM blocks WHERE blocker_id = sqlc.arg(user_id)::int
+UNION ALL
+SELECT 'muted'::text, muted_id, created_at FROM mutes WHERE muter_id = sqlc.arg(user_id)::int
+ORDER BY kind, created_at;
+
+-- name: CreateDataExport :one
+INSERT INTO data_exports(user_id, parts_expected)
+VALUES ($1, $2) RETURNING *;
+
+-- name: GetDataExport :one
+SELECT * FROM data_exports WHERE export_id = $1;
+
+-- name: GetActiveDataExport :one
+SELECT * FROM data_exports
+WHERE user_id = $1 AND status IN ('pending', 'assembling')
+LIMIT 1;
+
+-- name: CreateDataExportPart :execrows
+INSERT INTO data_export_parts(export_id, service, payload)
+VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;
+
+-- name: IncrementDataExportParts :one
+UPDATE data_exports SET parts_received = parts_received + 1
+WHERE export_id = $1 AND status = 'pending'
+RETURNING parts_received, parts_expected;
+
+-- name: ClaimDataExportForAssembly :execrows
+UPDATE data_exports SET status = 'assembling'
+WHERE export_id = $1 AND status = 'pending' AND parts_received >= parts_expected;
+
+-- name: ListDataExportParts :many
+SELECT * FROM data_export_parts WHERE export_id = $1 ORDER BY service;
+
+-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', object_key = $2, completed_at = NOW(), expires_at = sqlc.arg(expires_at)::timestamptz
+WHERE export_id = $1;
+
+-- name: FailDataExport :exec
+UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW()
+WHERE export_id = $1 AND status IN ('pending', 'assembling');
+
+-- name: FailStaleDataExports :execrows
+UPDATE data_exports SET status = 'failed', error = 'timed out waiting for services', completed_at = NOW()
+WHERE status IN ('pending', 'assembling') AND created_at < sqlc.arg(created_before)::timestamptz;
+
+-- name: GetExpiredDataExports :many
+SELECT export_id, object_key FROM data_exports
+WHERE status = 'ready' AND expires_at <= NOW()
+LIMIT $1;
+
+-- name: ExpireDataExport :exec
+UPDATE data_exports SET status = 'expired', object_key = NULL WHERE export_id = $1;
+
+-- name: DeleteDataExportParts :exec
+DELETE FROM data_export_parts WHERE export_id = $1;
+
+-- name: UpdatePresenceVisibility :execrows
+UPDATE users SET presence_visibility = $2 WHERE user_id = $1;
+
+-- name: GetPresenceAccess :many
+SELECT u.user_id, u.presence_visibility,
+       EXISTS (
+           SELECT 1 FROM follows f
+           WHERE f.follower_id = sqlc.arg(viewer_id)::int AND f.followee_id = u.user_id
+       )::bool AS viewer_follows,
+       EXISTS (
+           SELECT 1 FROM blocks b
+           WHERE (b.blocker_id = sqlc.arg(viewer_id)::int AND b.blocked_id = u.user_id)
+              OR (b.blocker_id = u.user_id AND b.blocked_id = sqlc.arg(viewer_id)::int)
+       )::bool AS blocked
+FROM users u
+WHERE u.user_id = ANY(sqlc.arg(user_ids)::int[])
+  AND u.deactivated_at IS NULL;
+
+-- name: CountListsByOwner :one
+SELECT COUNT(*) FROM lists WHERE owner_id = $1;
+
+-- name: CreateList :one
+INSERT INTO lists(owner_id, name, description, is_private)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetList :one
+SELECT l.*, (SELECT COUNT(*) FROM list_members lm WHERE lm.list_id = l.list_id)::int AS member_count
+FROM lists l
+JOIN users o ON o.user_id = l.owner_id
+WHERE l.list_id = $1 AND o.deactivated_at IS NULL;
+
+-- name: GetListForUpdate :one
+SELECT * FROM lists WHERE list_id = $1 FOR UPDATE;
+
+-- name: GetListsByOwner :many
+SELECT l.*, (SELECT COUNT(*) FROM list_members lm WHERE lm.list_id = l.list_id)::int AS member_count
+FROM lists l
+JOIN users o ON o.user_id = l.owner_id
+WHERE l.owner_id = sqlc.arg(owner_id)::int
+  AND o.deactivated_at IS NULL
+  AND (NOT l.is_private OR sqlc.arg(include_private)::bool)
+ORDER BY l.created_at DESC;
+
+-- name: UpdateList :exec
+UPDATE lists
+SET name = $2, description = $3, is_private = $4, updated_at = NOW()
+WHERE list_id = $1;
+
+-- name: DeleteList :exec
+DELETE FROM lists WHERE list_id = $1;
+
+-- name: CountListMembers :one
+SELECT COUNT(*) FROM list_members WHERE list_id = $1;
+
+-- name: CreateListMember :execrows
+-- members cannot be blocked by or blocking the list owner
+INSERT INTO list_members(list_id, user_id)
+SELECT sqlc.arg(list_id)::int, u.user_id
+FROM users u
+WHERE u.user_id = sqlc.arg(user_id)::int
+  AND u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = u.user_id AND b.blocked_id = sqlc.arg(owner_id)::int)
+         OR (b.blocker_id = sqlc.arg(owner_id)::int AND b.blocked_id = u.user_id)
+  )
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteListMember :execrows
+DELETE FROM list_members WHERE list_id = $1 AND user_id = $2;
+
+-- name: DeleteListMembershipsBetween :many
+-- removes either user from lists owned by the other, used when blocking
+WITH removed AS (
+    DELETE FROM list_members lm
+    WHERE (lm.user_id = sqlc.arg(user_b)::int AND lm.list_id IN (SELECT list_id FROM lists WHERE owner_id = sqlc.arg(user_a)::int))
+       OR (lm.user_id = sqlc.arg(user_a)::int AND lm.list_id IN (SELECT list_id FROM lists WHERE owner_id = sqlc.arg(user_b)::int))
+    RETURNING lm.list_id, lm.user_id
+)
+SELECT r.list_id, r.user_id, l.owner_id, l.is_private
+FROM removed r
+JOIN lists l ON l.list_id = r.list_id;
+
+-- name: GetListMembers :many
+SELECT u.user_id, u.username, u.firstname, u.lastname, u.profile_image_id, u.verified_badge, lm.added_at
+FROM list_members lm
+JOIN users u ON u.user_id = lm.user_id
+WHERE lm.list_id = $1 AND u.deactivated_at IS NULL
+ORDER BY lm.added_at DESC, u.user_id
+LIMIT $2 OFFSET $3;
+
+-- name: GetListMemberIds :many
+SELECT lm.user_id
+FROM list_members lm
+JOIN users u ON u.user_id = lm.user_id
+WHERE lm.list_id = $1 AND u.deactivated_at IS NULL;
+
+-- name: GetAllListMemberIds :many
+-- deactivated members included, used when a list changes privacy
+SELECT user_id FROM list_members WHERE list_id = $1 ORDER BY user_id;
+
+-- name: CreateVerificationRequest :one
+INSERT INTO verification_requests(user_id, badge, reason)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetLatestVerificationRequest :one
+SELECT * FROM verification_requests
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: GetVerificationRequestForUpdate :one
+SELECT * FROM verification_requests WHERE request_id = $1 FOR UPDATE;
+
+-- name: ListVerificationRequests :many
+SELECT * FROM verification_requests
+WHERE status = $1
+ORDER BY created_at, request_id
+LIMIT $2 OFFSET $3;
+
+-- name: ReviewVerificationRequest :exec
+UPDATE verification_requests
+SET status = $2, reviewer_id = $3, review_note = $4, reviewed_at = NOW()
+WHERE request_id = $1;
+
+-- name: SetUserVerifiedBadge :execrows
+UPDATE users SET verified_badge = $2, verified_at = NOW()
+WHERE user_id = $1 AND deactivated_at IS NULL;
+
+-- name: ClearUserVerifiedBadge :exec
+UPDATE users SET verified_badge = '', verified_at = NULL WHERE user_id = $1;
+
+-- name: CreateVerificationAudit :exec
+INSERT INTO verification_audit(user_id, action, badge, actor_id, request_id, note)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: ListVerificationAudit :many
+SELECT * FROM verification_audit
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC;
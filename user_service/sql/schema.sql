@@ -1,3 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE users
 (
     user_id int PRIMARY KEY,
@@ -5,5 +7,136 @@ CREATE TABLE users
     email      text NOT NULL UNIQUE,
     firstname text NOT NULL,
     lastname text NOT NULL,
-    profile_image_id int
-);
\ No newline at end of file
+    profile_image_id int,
+    banner_image_id int,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deactivated_at TIMESTAMPTZ,
+    purge_after TIMESTAMPTZ,
+    username_changed_at TIMESTAMPTZ,
+    presence_visibility text NOT NULL DEFAULT 'everyone'
+        CHECK (presence_visibility IN ('everyone', 'followers', 'nobody')),
+    verified_badge text NOT NULL DEFAULT ''
+        CHECK (verified_badge IN ('', 'notable', 'official')),
+    verified_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+CREATE INDEX idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+CREATE INDEX idx_users_firstname_trgm ON users USING gin (firstname gin_trgm_ops);
+CREATE INDEX idx_users_lastname_trgm ON users USING gin (lastname gin_trgm_ops);
+CREATE INDEX idx_users_created_at ON users(created_at, user_id);
+CREATE INDEX idx_users_purge_after ON users(purge_after) WHERE purge_after IS NOT NULL;
+
+CREATE TABLE follows
+(
+    follower_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    followee_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+CREATE INDEX idx_follows_followee_id ON follows(followee_id);
+
+CREATE TABLE blocks
+(
+    blocker_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    blocked_id int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+CREATE INDEX idx_blocks_blocked_id ON blocks(blocked_id);
+
+CREATE TABLE mutes
+(
+    muter_id   int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    muted_id   int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (muter_id, muted_id),
+    CHECK (muter_id <> muted_id)
+);
+
+CREATE TABLE data_exports
+(
+    export_id      SERIAL PRIMARY KEY,
+    user_id        int NOT NULL,
+    status         text NOT NULL DEFAULT 'pending',
+    parts_expected int NOT NULL,
+    parts_received int NOT NULL DEFAULT 0,
+    object_key     text,
+    error          text,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at   TIMESTAMPTZ,
+    expires_at     TIMESTAMPTZ
+);
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id, created_at DESC);
+CREATE INDEX idx_data_exports_expires_at ON data_exports(expires_at) WHERE status = 'ready';
+CREATE UNIQUE INDEX idx_data_exports_active ON data_exports(user_id) WHERE status IN ('pending', 'assembling');
+
+CREATE TABLE data_export_parts
+(
+    export_id   int NOT NULL REFERENCES data_exports(export_id) ON DELETE CASCADE,
+    service     text NOT NULL,
+    payload     bytea NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (export_id, service)
+);
+
+CREATE TABLE username_history
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    username   text NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    held_until TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_username_history_username ON username_history(LOWER(username), held_until);
+CREATE INDEX idx_username_history_user_id ON username_history(user_id, changed_at DESC);
+
+CREATE TABLE lists
+(
+    list_id      SERIAL PRIMARY KEY,
+    owner_id     int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name         text NOT NULL CHECK (char_length(name) BETWEEN 1 AND 50),
+    description  text NOT NULL DEFAULT '' CHECK (char_length(description) <= 160),
+    is_private   boolean NOT NULL DEFAULT false,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_lists_owner_id ON lists(owner_id, created_at DESC);
+
+CREATE TABLE list_members
+(
+    list_id  int NOT NULL REFERENCES lists(list_id) ON DELETE CASCADE,
+    user_id  int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    added_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (list_id, user_id)
+);
+CREATE INDEX idx_list_members_user_id ON list_members(user_id);
+
+CREATE TABLE verification_requests
+(
+    request_id  SERIAL PRIMARY KEY,
+    user_id     int NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    badge       text NOT NULL CHECK (badge IN ('notable', 'official')),
+    reason      text NOT NULL CHECK (char_length(reason) <= 1000),
+    status      text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewer_id int,
+    review_note text NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX idx_verification_requests_pending ON verification_requests(user_id) WHERE status = 'pending';
+CREATE INDEX idx_verification_requests_status ON verification_requests(status, created_at);
+
+CREATE TABLE verification_audit
+(
+    id         SERIAL PRIMARY KEY,
+    user_id    int NOT NULL,
+    action     text NOT NULL CHECK (action IN ('requested', 'approved', 'rejected', 'granted', 'revoked')),
+    badge      text NOT NULL DEFAULT '',
+    actor_id   int NOT NULL,
+    request_id int REFERENCES verification_requests(request_id) ON DELETE SET NULL,
+    note       text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_verification_audit_user_id ON verification_audit(user_id, created_at DESC);
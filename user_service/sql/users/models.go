@@ -1,18 +1,112 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.19.1
+//   sqlc v1.22.0
 
 package users
 
 import (
 	"database/sql"
+	"time"
 )
 
+type Block struct {
+	BlockerID int32     `json:"blockerId"`
+	BlockedID int32     `json:"blockedId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type DataExport struct {
+	ExportID      int32          `json:"exportId"`
+	UserID        int32          `json:"userId"`
+	Status        string         `json:"status"`
+	PartsExpected int32          `json:"partsExpected"`
+	PartsReceived int32          `json:"partsReceived"`
+	ObjectKey     sql.NullString `json:"objectKey"`
+	Error         sql.NullString `json:"error"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	CompletedAt   sql.NullTime   `json:"completedAt"`
+	ExpiresAt     sql.NullTime   `json:"expiresAt"`
+}
+
+type DataExportPart struct {
+	ExportID   int32     `json:"exportId"`
+	Service    string    `json:"service"`
+	Payload    []byte    `json:"payload"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+type Follow struct {
+	FollowerID int32     `json:"followerId"`
+	FolloweeID int32     `json:"followeeId"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type List struct {
+	ListID      int32     `json:"listId"`
+	OwnerID     int32     `json:"ownerId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPrivate   bool      `json:"isPrivate"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type ListMember struct {
+	ListID  int32     `json:"listId"`
+	UserID  int32     `json:"userId"`
+	AddedAt time.Time `json:"addedAt"`
+}
+
+type Mute struct {
+	MuterID   int32     `json:"muterId"`
+	MutedID   int32     `json:"mutedId"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type User struct {
-	UserID         int32         `json:"userId"`
-	Username       string        `json:"username"`
-	Email          string        `json:"email"`
-	Firstname      string        `json:"firstname"`
-	Lastname       string        `json:"lastname"`
-	ProfileImageID sql.NullInt32 `json:"profileImageId"`
+	UserID             int32         `json:"userId"`
+	Username           string        `json:"username"`
+	Email              string        `json:"email"`
+	Firstname          string        `json:"firstname"`
+	Lastname           string        `json:"lastname"`
+	ProfileImageID     sql.NullInt32 `json:"profileImageId"`
+	BannerImageID      sql.NullInt32 `json:"bannerImageId"`
+	CreatedAt          time.Time     `json:"createdAt"`
+	DeactivatedAt      sql.NullTime  `json:"deactivatedAt"`
+	PurgeAfter         sql.NullTime  `json:"purgeAfter"`
+	UsernameChangedAt  sql.NullTime  `json:"usernameChangedAt"`
+	PresenceVisibility string        `json:"presenceVisibility"`
+	VerifiedBadge      string        `json:"verifiedBadge"`
+	VerifiedAt         sql.NullTime  `json:"verifiedAt"`
+}
+
+type UsernameHistory struct {
+	ID        int32     `json:"id"`
+	UserID    int32     `json:"userId"`
+	Username  string    `json:"username"`
+	ChangedAt time.Time `json:"changedAt"`
+	HeldUntil time.Time `json:"heldUntil"`
+}
+
+type VerificationAudit struct {
+	ID        int32         `json:"id"`
+	UserID    int32         `json:"userId"`
+	Action    string        `json:"action"`
+	Badge     string        `json:"badge"`
+	ActorID   int32         `json:"actorId"`
+	RequestID sql.NullInt32 `json:"requestId"`
+	Note      string        `json:"note"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+type VerificationRequest struct {
+	RequestID  int32         `json:"requestId"`
+	UserID     int32         `json:"userId"`
+	Badge      string        `json:"badge"`
+	Reason     string        `json:"reason"`
+	Status     string        `json:"status"`
+	ReviewerID sql.NullInt32 `json:"reviewerId"`
+	ReviewNote string        `json:"reviewNote"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	ReviewedAt sql.NullTime  `json:"reviewedAt"`
 }
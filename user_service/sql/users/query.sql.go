@@ -1,6 +1,6 @@
 // Code generated by sqlc. DO NOT EDIT.
 // versions:
-//   sqlc v1.19.1
+//   sqlc v1.22.0
 // source: query.sql
 
 package users
@@ -8,11 +8,242 @@ package users
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const claimDataExportForAssembly = `-- name: ClaimDataExportForAssembly :execrows
+UPDATE data_exports SET status = 'assembling'
+WHERE export_id = $1 AND status = 'pending' AND parts_received >= parts_expected
+`
+
+func (q *Queries) ClaimDataExportForAssembly(ctx context.Context, exportID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimDataExportForAssembly, exportID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const clearUserVerifiedBadge = `-- name: ClearUserVerifiedBadge :exec
+UPDATE users SET verified_badge = '', verified_at = NULL WHERE user_id = $1
+`
+
+func (q *Queries) ClearUserVerifiedBadge(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, clearUserVerifiedBadge, userID)
+	return err
+}
+
+const completeDataExport = `-- name: CompleteDataExport :exec
+UPDATE data_exports
+SET status = 'ready', object_key = $2, completed_at = NOW(), expires_at = $3::timestamptz
+WHERE export_id = $1
+`
+
+type CompleteDataExportParams struct {
+	ExportID  int32          `json:"exportId"`
+	ObjectKey sql.NullString `json:"objectKey"`
+	ExpiresAt time.Time      `json:"expiresAt"`
+}
+
+func (q *Queries) CompleteDataExport(ctx context.Context, arg CompleteDataExportParams) error {
+	_, err := q.db.ExecContext(ctx, completeDataExport, arg.ExportID, arg.ObjectKey, arg.ExpiresAt)
+	return err
+}
+
+const countListMembers = `-- name: CountListMembers :one
+SELECT COUNT(*) FROM list_members WHERE list_id = $1
+`
+
+func (q *Queries) CountListMembers(ctx context.Context, listID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countListMembers, listID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countListsByOwner = `-- name: CountListsByOwner :one
+SELECT COUNT(*) FROM lists WHERE owner_id = $1
+`
+
+func (q *Queries) CountListsByOwner(ctx context.Context, ownerID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countListsByOwner, ownerID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createBlock = `-- name: CreateBlock :execrows
+INSERT INTO blocks(blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type CreateBlockParams struct {
+	BlockerID int32 `json:"blockerId"`
+	BlockedID int32 `json:"blockedId"`
+}
+
+func (q *Queries) CreateBlock(ctx context.Context, arg CreateBlockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createBlock, arg.BlockerID, arg.BlockedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createDataExport = `-- name: CreateDataExport :one
+INSERT INTO data_exports(user_id, parts_expected)
+VALUES ($1, $2) RETURNING export_id, user_id, status, parts_expected, parts_received, object_key, error, created_at, completed_at, expires_at
+`
+
+type CreateDataExportParams struct {
+	UserID        int32 `json:"userId"`
+	PartsExpected int32 `json:"partsExpected"`
+}
+
+func (q *Queries) CreateDataExport(ctx context.Context, arg CreateDataExportParams) (DataExport, error) {
+	row := q.db.QueryRowContext(ctx, createDataExport, arg.UserID, arg.PartsExpected)
+	var i DataExport
+	err := row.Scan(
+		&i.ExportID,
+		&i.UserID,
+		&i.Status,
+		&i.PartsExpected,
+		&i.PartsReceived,
+		&i.ObjectKey,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const createDataExportPart = `-- name: CreateDataExportPart :execrows
+INSERT INTO data_export_parts(export_id, service, payload)
+VALUES ($1, $2, $3) ON CONFLICT DO NOTHING
+`
+
+type CreateDataExportPartParams struct {
+	ExportID int32  `json:"exportId"`
+	Service  string `json:"service"`
+	Payload  []byte `json:"payload"`
+}
+
+func (q *Queries) CreateDataExportPart(ctx context.Context, arg CreateDataExportPartParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createDataExportPart, arg.ExportID, arg.Service, arg.Payload)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createFollow = `-- name: CreateFollow :execrows
+INSERT INTO follows(follower_id, followee_id)
+SELECT $1, $2
+WHERE NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = $1 AND blocked_id = $2)
+       OR (blocker_id = $2 AND blocked_id = $1)
 )
+ON CONFLICT DO NOTHING
+`
+
+type CreateFollowParams struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) CreateFollow(ctx context.Context, arg CreateFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createFollow, arg.FollowerID, arg.FolloweeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createList = `-- name: CreateList :one
+INSERT INTO lists(owner_id, name, description, is_private)
+VALUES ($1, $2, $3, $4)
+RETURNING list_id, owner_id, name, description, is_private, created_at, updated_at
+`
+
+type CreateListParams struct {
+	OwnerID     int32  `json:"ownerId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"isPrivate"`
+}
+
+func (q *Queries) CreateList(ctx context.Context, arg CreateListParams) (List, error) {
+	row := q.db.QueryRowContext(ctx, createList,
+		arg.OwnerID,
+		arg.Name,
+		arg.Description,
+		arg.IsPrivate,
+	)
+	var i List
+	err := row.Scan(
+		&i.ListID,
+		&i.OwnerID,
+		&i.Name,
+		&i.Description,
+		&i.IsPrivate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createListMember = `-- name: CreateListMember :execrows
+INSERT INTO list_members(list_id, user_id)
+SELECT $1::int, u.user_id
+FROM users u
+WHERE u.user_id = $2::int
+  AND u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = u.user_id AND b.blocked_id = $3::int)
+         OR (b.blocker_id = $3::int AND b.blocked_id = u.user_id)
+  )
+ON CONFLICT DO NOTHING
+`
+
+type CreateListMemberParams struct {
+	ListID  int32 `json:"listId"`
+	UserID  int32 `json:"userId"`
+	OwnerID int32 `json:"ownerId"`
+}
+
+// members cannot be blocked by or blocking the list owner
+func (q *Queries) CreateListMember(ctx context.Context, arg CreateListMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createListMember, arg.ListID, arg.UserID, arg.OwnerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createMute = `-- name: CreateMute :execrows
+INSERT INTO mutes(muter_id, muted_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+`
+
+type CreateMuteParams struct {
+	MuterID int32 `json:"muterId"`
+	MutedID int32 `json:"mutedId"`
+}
+
+func (q *Queries) CreateMute(ctx context.Context, arg CreateMuteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createMute, arg.MuterID, arg.MutedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
 
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(user_id, username,email, firstname,lastname)
-VALUES ($1, $2, $3, $4, $5) RETURNING user_id, username, email, firstname, lastname, profile_image_id
+VALUES ($1, $2, $3, $4, $5) RETURNING user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
 `
 
 type CreateUserParams struct {
@@ -39,96 +270,231 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Firstname,
 		&i.Lastname,
 		&i.ProfileImageID,
+		&i.BannerImageID,
+		&i.CreatedAt,
+		&i.DeactivatedAt,
+		&i.PurgeAfter,
+		&i.UsernameChangedAt,
+		&i.PresenceVisibility,
+		&i.VerifiedBadge,
+		&i.VerifiedAt,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE
-FROM users
-WHERE user_id = $1
+const createUsernameHistory = `-- name: CreateUsernameHistory :exec
+INSERT INTO username_history(user_id, username, held_until)
+VALUES ($1, $2, $3)
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, userID int32) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, userID)
+type CreateUsernameHistoryParams struct {
+	UserID    int32     `json:"userId"`
+	Username  string    `json:"username"`
+	HeldUntil time.Time `json:"heldUntil"`
+}
+
+func (q *Queries) CreateUsernameHistory(ctx context.Context, arg CreateUsernameHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, createUsernameHistory, arg.UserID, arg.Username, arg.HeldUntil)
 	return err
 }
 
-const getUserById = `-- name: GetUserById :one
-SELECT user_id, username, email, firstname, lastname, profile_image_id
-FROM users
-WHERE user_id = $1 LIMIT 1
+const createVerificationAudit = `-- name: CreateVerificationAudit :exec
+INSERT INTO verification_audit(user_id, action, badge, actor_id, request_id, note)
+VALUES ($1, $2, $3, $4, $5, $6)
 `
 
-func (q *Queries) GetUserById(ctx context.Context, userID int32) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserById, userID)
-	var i User
-	err := row.Scan(
-		&i.UserID,
-		&i.Username,
-		&i.Email,
-		&i.Firstname,
-		&i.Lastname,
-		&i.ProfileImageID,
+type CreateVerificationAuditParams struct {
+	UserID    int32         `json:"userId"`
+	Action    string        `json:"action"`
+	Badge     string        `json:"badge"`
+	ActorID   int32         `json:"actorId"`
+	RequestID sql.NullInt32 `json:"requestId"`
+	Note      string        `json:"note"`
+}
+
+func (q *Queries) CreateVerificationAudit(ctx context.Context, arg CreateVerificationAuditParams) error {
+	_, err := q.db.ExecContext(ctx, createVerificationAudit,
+		arg.UserID,
+		arg.Action,
+		arg.Badge,
+		arg.ActorID,
+		arg.RequestID,
+		arg.Note,
 	)
-	return i, err
+	return err
 }
 
-const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT user_id, username, email, firstname, lastname, profile_image_id
-FROM users
-WHERE username = $1 LIMIT 1
+const createVerificationRequest = `-- name: CreateVerificationRequest :one
+INSERT INTO verification_requests(user_id, badge, reason)
+VALUES ($1, $2, $3)
+RETURNING request_id, user_id, badge, reason, status, reviewer_id, review_note, created_at, reviewed_at
 `
 
-func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
-	var i User
+type CreateVerificationRequestParams struct {
+	UserID int32  `json:"userId"`
+	Badge  string `json:"badge"`
+	Reason string `json:"reason"`
+}
+
+func (q *Queries) CreateVerificationRequest(ctx context.Context, arg CreateVerificationRequestParams) (VerificationRequest, error) {
+	row := q.db.QueryRowContext(ctx, createVerificationRequest, arg.UserID, arg.Badge, arg.Reason)
+	var i VerificationRequest
 	err := row.Scan(
+		&i.RequestID,
 		&i.UserID,
-		&i.Username,
-		&i.Email,
-		&i.Firstname,
-		&i.Lastname,
-		&i.ProfileImageID,
+		&i.Badge,
+		&i.Reason,
+		&i.Status,
+		&i.ReviewerID,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
 	)
 	return i, err
 }
 
-const getUserProfileImageByUserId = `-- name: GetUserProfileImageByUserId :one
-SELECT profile_image_id
-FROM users
-WHERE user_id = $1 LIMIT 1
+const deactivateUser = `-- name: DeactivateUser :execrows
+UPDATE users SET deactivated_at = NOW(), purge_after = $1::timestamptz
+WHERE user_id = $2::int AND deactivated_at IS NULL
 `
 
-func (q *Queries) GetUserProfileImageByUserId(ctx context.Context, userID int32) (sql.NullInt32, error) {
-	row := q.db.QueryRowContext(ctx, getUserProfileImageByUserId, userID)
-	var profile_image_id sql.NullInt32
-	err := row.Scan(&profile_image_id)
-	return profile_image_id, err
+type DeactivateUserParams struct {
+	PurgeAfter time.Time `json:"purgeAfter"`
+	UserID     int32     `json:"userId"`
 }
 
-const listUsers = `-- name: ListUsers :many
-SELECT user_id, username, email, firstname, lastname, profile_image_id
-FROM users
-ORDER BY user_id
+func (q *Queries) DeactivateUser(ctx context.Context, arg DeactivateUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deactivateUser, arg.PurgeAfter, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBlock = `-- name: DeleteBlock :execrows
+DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2
+`
+
+type DeleteBlockParams struct {
+	BlockerID int32 `json:"blockerId"`
+	BlockedID int32 `json:"blockedId"`
+}
+
+func (q *Queries) DeleteBlock(ctx context.Context, arg DeleteBlockParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBlock, arg.BlockerID, arg.BlockedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteDataExportParts = `-- name: DeleteDataExportParts :exec
+DELETE FROM data_export_parts WHERE export_id = $1
+`
+
+func (q *Queries) DeleteDataExportParts(ctx context.Context, exportID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteDataExportParts, exportID)
+	return err
+}
+
+const deleteFollow = `-- name: DeleteFollow :execrows
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2
+`
+
+type DeleteFollowParams struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFollow, arg.FollowerID, arg.FolloweeID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFollowsBetween = `-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = $1 AND followee_id = $2)
+   OR (follower_id = $2 AND followee_id = $1)
+`
+
+type DeleteFollowsBetweenParams struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+func (q *Queries) DeleteFollowsBetween(ctx context.Context, arg DeleteFollowsBetweenParams) error {
+	_, err := q.db.ExecContext(ctx, deleteFollowsBetween, arg.FollowerID, arg.FolloweeID)
+	return err
+}
+
+const deleteList = `-- name: DeleteList :exec
+DELETE FROM lists WHERE list_id = $1
+`
+
+func (q *Queries) DeleteList(ctx context.Context, listID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteList, listID)
+	return err
+}
+
+const deleteListMember = `-- name: DeleteListMember :execrows
+DELETE FROM list_members WHERE list_id = $1 AND user_id = $2
+`
+
+type DeleteListMemberParams struct {
+	ListID int32 `json:"listId"`
+	UserID int32 `json:"userId"`
+}
+
+func (q *Queries) DeleteListMember(ctx context.Context, arg DeleteListMemberParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteListMember, arg.ListID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteListMembershipsBetween = `-- name: DeleteListMembershipsBetween :many
+WITH removed AS (
+    DELETE FROM list_members lm
+    WHERE (lm.user_id = $1::int AND lm.list_id IN (SELECT list_id FROM lists WHERE owner_id = $2::int))
+       OR (lm.user_id = $2::int AND lm.list_id IN (SELECT list_id FROM lists WHERE owner_id = $1::int))
+    RETURNING lm.list_id, lm.user_id
+)
+SELECT r.list_id, r.user_id, l.owner_id, l.is_private
+FROM removed r
+JOIN lists l ON l.list_id = r.list_id
 `
 
-func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, listUsers)
+type DeleteListMembershipsBetweenParams struct {
+	UserB int32 `json:"userB"`
+	UserA int32 `json:"userA"`
+}
+
+type DeleteListMembershipsBetweenRow struct {
+	ListID    int32 `json:"listId"`
+	UserID    int32 `json:"userId"`
+	OwnerID   int32 `json:"ownerId"`
+	IsPrivate bool  `json:"isPrivate"`
+}
+
+// removes either user from lists owned by the other, used when blocking
+func (q *Queries) DeleteListMembershipsBetween(ctx context.Context, arg DeleteListMembershipsBetweenParams) ([]DeleteListMembershipsBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteListMembershipsBetween, arg.UserB, arg.UserA)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []DeleteListMembershipsBetweenRow
 	for rows.Next() {
-		var i User
+		var i DeleteListMembershipsBetweenRow
 		if err := rows.Scan(
+			&i.ListID,
 			&i.UserID,
-			&i.Username,
-			&i.Email,
-			&i.Firstname,
-			&i.Lastname,
-			&i.ProfileImageID,
+			&i.OwnerID,
+			&i.IsPrivate,
 		); err != nil {
 			return nil, err
 		}
@@ -143,28 +509,1533 @@ func (q *Queries) ListUsers(ctx context.Context) ([]User, error) {
 	return items, nil
 }
 
-const updateUser = `-- name: UpdateUser :exec
-UPDATE users SET 
-username = COALESCE(nullif($2, ''), username),
-firstname = COALESCE(nullif($3, ''), firstname), 
-lastname = COALESCE(nullif($4, ''),  lastname)
+const deleteMute = `-- name: DeleteMute :execrows
+DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2
+`
+
+type DeleteMuteParams struct {
+	MuterID int32 `json:"muterId"`
+	MutedID int32 `json:"mutedId"`
+}
+
+func (q *Queries) DeleteMute(ctx context.Context, arg DeleteMuteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMute, arg.MuterID, arg.MutedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteUser = `-- name: DeleteUser :exec
+DELETE
+FROM users
 WHERE user_id = $1
 `
 
-type UpdateUserParams struct {
-	UserID  int32       `json:"userId"`
-	Column2 interface{} `json:"column2"`
-	Column3 interface{} `json:"column3"`
-	Column4 interface{} `json:"column4"`
+func (q *Queries) DeleteUser(ctx context.Context, userID int32) error {
+	_, err := q.db.ExecContext(ctx, deleteUser, userID)
+	return err
 }
 
-func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
-	_, err := q.db.ExecContext(ctx, updateUser,
-		arg.UserID,
-		arg.Column2,
-		arg.Column3,
-		arg.Column4,
+const expireDataExport = `-- name: ExpireDataExport :exec
+UPDATE data_exports SET status = 'expired', object_key = NULL WHERE export_id = $1
+`
+
+func (q *Queries) ExpireDataExport(ctx context.Context, exportID int32) error {
+	_, err := q.db.ExecContext(ctx, expireDataExport, exportID)
+	return err
+}
+
+const failDataExport = `-- name: FailDataExport :exec
+UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW()
+WHERE export_id = $1 AND status IN ('pending', 'assembling')
+`
+
+type FailDataExportParams struct {
+	ExportID int32          `json:"exportId"`
+	Error    sql.NullString `json:"error"`
+}
+
+func (q *Queries) FailDataExport(ctx context.Context, arg FailDataExportParams) error {
+	_, err := q.db.ExecContext(ctx, failDataExport, arg.ExportID, arg.Error)
+	return err
+}
+
+const failStaleDataExports = `-- name: FailStaleDataExports :execrows
+UPDATE data_exports SET status = 'failed', error = 'timed out waiting for services', completed_at = NOW()
+WHERE status IN ('pending', 'assembling') AND created_at < $1::timestamptz
+`
+
+func (q *Queries) FailStaleDataExports(ctx context.Context, createdBefore time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, failStaleDataExports, createdBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getActiveDataExport = `-- name: GetActiveDataExport :one
+SELECT export_id, user_id, status, parts_expected, parts_received, object_key, error, created_at, completed_at, expires_at FROM data_exports
+WHERE user_id = $1 AND status IN ('pending', 'assembling')
+LIMIT 1
+`
+
+func (q *Queries) GetActiveDataExport(ctx context.Context, userID int32) (DataExport, error) {
+	row := q.db.QueryRowContext(ctx, getActiveDataExport, userID)
+	var i DataExport
+	err := row.Scan(
+		&i.ExportID,
+		&i.UserID,
+		&i.Status,
+		&i.PartsExpected,
+		&i.PartsReceived,
+		&i.ObjectKey,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
 	)
+	return i, err
+}
+
+const getAllListMemberIds = `-- name: GetAllListMemberIds :many
+SELECT user_id FROM list_members WHERE list_id = $1 ORDER BY user_id
+`
+
+// deactivated members included, used when a list changes privacy
+func (q *Queries) GetAllListMemberIds(ctx context.Context, listID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getAllListMemberIds, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var user_id int32
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDataExport = `-- name: GetDataExport :one
+SELECT export_id, user_id, status, parts_expected, parts_received, object_key, error, created_at, completed_at, expires_at FROM data_exports WHERE export_id = $1
+`
+
+func (q *Queries) GetDataExport(ctx context.Context, exportID int32) (DataExport, error) {
+	row := q.db.QueryRowContext(ctx, getDataExport, exportID)
+	var i DataExport
+	err := row.Scan(
+		&i.ExportID,
+		&i.UserID,
+		&i.Status,
+		&i.PartsExpected,
+		&i.PartsReceived,
+		&i.ObjectKey,
+		&i.Error,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getExpiredDataExports = `-- name: GetExpiredDataExports :many
+SELECT export_id, object_key FROM data_exports
+WHERE status = 'ready' AND expires_at <= NOW()
+LIMIT $1
+`
+
+type GetExpiredDataExportsRow struct {
+	ExportID  int32          `json:"exportId"`
+	ObjectKey sql.NullString `json:"objectKey"`
+}
+
+func (q *Queries) GetExpiredDataExports(ctx context.Context, limit int32) ([]GetExpiredDataExportsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getExpiredDataExports, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetExpiredDataExportsRow
+	for rows.Next() {
+		var i GetExpiredDataExportsRow
+		if err := rows.Scan(&i.ExportID, &i.ObjectKey); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedAmong = `-- name: GetFollowedAmong :many
+SELECT followee_id FROM follows
+WHERE follower_id = $1::int
+  AND followee_id = ANY($2::int[])
+`
+
+type GetFollowedAmongParams struct {
+	FollowerID  int32   `json:"followerId"`
+	FolloweeIds []int32 `json:"followeeIds"`
+}
+
+func (q *Queries) GetFollowedAmong(ctx context.Context, arg GetFollowedAmongParams) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedAmong, arg.FollowerID, pq.Array(arg.FolloweeIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var followee_id int32
+		if err := rows.Scan(&followee_id); err != nil {
+			return nil, err
+		}
+		items = append(items, followee_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowsPage = `-- name: GetFollowsPage :many
+SELECT follower_id, followee_id FROM follows
+WHERE (follower_id, followee_id) > ($2::int, $3::int)
+ORDER BY follower_id, followee_id
+LIMIT $1
+`
+
+type GetFollowsPageParams struct {
+	Limit           int32 `json:"limit"`
+	AfterFollowerID int32 `json:"afterFollowerId"`
+	AfterFolloweeID int32 `json:"afterFolloweeId"`
+}
+
+type GetFollowsPageRow struct {
+	FollowerID int32 `json:"followerId"`
+	FolloweeID int32 `json:"followeeId"`
+}
+
+// every follow in primary key order, used to backfill copies of the graph
+func (q *Queries) GetFollowsPage(ctx context.Context, arg GetFollowsPageParams) ([]GetFollowsPageRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowsPage, arg.Limit, arg.AfterFollowerID, arg.AfterFolloweeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowsPageRow
+	for rows.Next() {
+		var i GetFollowsPageRow
+		if err := rows.Scan(&i.FollowerID, &i.FolloweeID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestVerificationRequest = `-- name: GetLatestVerificationRequest :one
+SELECT request_id, user_id, badge, reason, status, reviewer_id, review_note, created_at, reviewed_at FROM verification_requests
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestVerificationRequest(ctx context.Context, userID int32) (VerificationRequest, error) {
+	row := q.db.QueryRowContext(ctx, getLatestVerificationRequest, userID)
+	var i VerificationRequest
+	err := row.Scan(
+		&i.RequestID,
+		&i.UserID,
+		&i.Badge,
+		&i.Reason,
+		&i.Status,
+		&i.ReviewerID,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const getList = `-- name: GetList :one
+SELECT l.list_id, l.owner_id, l.name, l.description, l.is_private, l.created_at, l.updated_at, (SELECT COUNT(*) FROM list_members lm WHERE lm.list_id = l.list_id)::int AS member_count
+FROM lists l
+JOIN users o ON o.user_id = l.owner_id
+WHERE l.list_id = $1 AND o.deactivated_at IS NULL
+`
+
+type GetListRow struct {
+	ListID      int32     `json:"listId"`
+	OwnerID     int32     `json:"ownerId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPrivate   bool      `json:"isPrivate"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	MemberCount int32     `json:"memberCount"`
+}
+
+func (q *Queries) GetList(ctx context.Context, listID int32) (GetListRow, error) {
+	row := q.db.QueryRowContext(ctx, getList, listID)
+	var i GetListRow
+	err := row.Scan(
+		&i.ListID,
+		&i.OwnerID,
+		&i.Name,
+		&i.Description,
+		&i.IsPrivate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MemberCount,
+	)
+	return i, err
+}
+
+const getListForUpdate = `-- name: GetListForUpdate :one
+SELECT list_id, owner_id, name, description, is_private, created_at, updated_at FROM lists WHERE list_id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetListForUpdate(ctx context.Context, listID int32) (List, error) {
+	row := q.db.QueryRowContext(ctx, getListForUpdate, listID)
+	var i List
+	err := row.Scan(
+		&i.ListID,
+		&i.OwnerID,
+		&i.Name,
+		&i.Description,
+		&i.IsPrivate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getListMemberIds = `-- name: GetListMemberIds :many
+SELECT lm.user_id
+FROM list_members lm
+JOIN users u ON u.user_id = lm.user_id
+WHERE lm.list_id = $1 AND u.deactivated_at IS NULL
+`
+
+func (q *Queries) GetListMemberIds(ctx context.Context, listID int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getListMemberIds, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var user_id int32
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getListMembers = `-- name: GetListMembers :many
+SELECT u.user_id, u.username, u.firstname, u.lastname, u.profile_image_id, u.verified_badge, lm.added_at
+FROM list_members lm
+JOIN users u ON u.user_id = lm.user_id
+WHERE lm.list_id = $1 AND u.deactivated_at IS NULL
+ORDER BY lm.added_at DESC, u.user_id
+LIMIT $2 OFFSET $3
+`
+
+type GetListMembersParams struct {
+	ListID int32 `json:"listId"`
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type GetListMembersRow struct {
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Firstname      string        `json:"firstname"`
+	Lastname       string        `json:"lastname"`
+	ProfileImageID sql.NullInt32 `json:"profileImageId"`
+	VerifiedBadge  string        `json:"verifiedBadge"`
+	AddedAt        time.Time     `json:"addedAt"`
+}
+
+func (q *Queries) GetListMembers(ctx context.Context, arg GetListMembersParams) ([]GetListMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getListMembers, arg.ListID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetListMembersRow
+	for rows.Next() {
+		var i GetListMembersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.VerifiedBadge,
+			&i.AddedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getListsByOwner = `-- name: GetListsByOwner :many
+SELECT l.list_id, l.owner_id, l.name, l.description, l.is_private, l.created_at, l.updated_at, (SELECT COUNT(*) FROM list_members lm WHERE lm.list_id = l.list_id)::int AS member_count
+FROM lists l
+JOIN users o ON o.user_id = l.owner_id
+WHERE l.owner_id = $1::int
+  AND o.deactivated_at IS NULL
+  AND (NOT l.is_private OR $2::bool)
+ORDER BY l.created_at DESC
+`
+
+type GetListsByOwnerParams struct {
+	OwnerID        int32 `json:"ownerId"`
+	IncludePrivate bool  `json:"includePrivate"`
+}
+
+type GetListsByOwnerRow struct {
+	ListID      int32     `json:"listId"`
+	OwnerID     int32     `json:"ownerId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPrivate   bool      `json:"isPrivate"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	MemberCount int32     `json:"memberCount"`
+}
+
+func (q *Queries) GetListsByOwner(ctx context.Context, arg GetListsByOwnerParams) ([]GetListsByOwnerRow, error) {
+	rows, err := q.db.QueryContext(ctx, getListsByOwner, arg.OwnerID, arg.IncludePrivate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetListsByOwnerRow
+	for rows.Next() {
+		var i GetListsByOwnerRow
+		if err := rows.Scan(
+			&i.ListID,
+			&i.OwnerID,
+			&i.Name,
+			&i.Description,
+			&i.IsPrivate,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.MemberCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMentionableUsers = `-- name: GetMentionableUsers :many
+SELECT u.user_id, u.username
+FROM users u
+WHERE LOWER(u.username) = ANY($1::text[])
+  AND u.deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = u.user_id AND b.blocked_id = $2::int)
+         OR (b.blocker_id = $2::int AND b.blocked_id = u.user_id)
+  )
+`
+
+type GetMentionableUsersParams struct {
+	Usernames []string `json:"usernames"`
+	AuthorID  int32    `json:"authorId"`
+}
+
+type GetMentionableUsersRow struct {
+	UserID   int32  `json:"userId"`
+	Username string `json:"username"`
+}
+
+// active users the author may mention, blocks in either direction excluded
+func (q *Queries) GetMentionableUsers(ctx context.Context, arg GetMentionableUsersParams) ([]GetMentionableUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMentionableUsers, pq.Array(arg.Usernames), arg.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMentionableUsersRow
+	for rows.Next() {
+		var i GetMentionableUsersRow
+		if err := rows.Scan(&i.UserID, &i.Username); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPresenceAccess = `-- name: GetPresenceAccess :many
+SELECT u.user_id, u.presence_visibility,
+       EXISTS (
+           SELECT 1 FROM follows f
+           WHERE f.follower_id = $1::int AND f.followee_id = u.user_id
+       )::bool AS viewer_follows,
+       EXISTS (
+           SELECT 1 FROM blocks b
+           WHERE (b.blocker_id = $1::int AND b.blocked_id = u.user_id)
+              OR (b.blocker_id = u.user_id AND b.blocked_id = $1::int)
+       )::bool AS blocked
+FROM users u
+WHERE u.user_id = ANY($2::int[])
+  AND u.deactivated_at IS NULL
+`
+
+type GetPresenceAccessParams struct {
+	ViewerID int32   `json:"viewerId"`
+	UserIds  []int32 `json:"userIds"`
+}
+
+type GetPresenceAccessRow struct {
+	UserID             int32  `json:"userId"`
+	PresenceVisibility string `json:"presenceVisibility"`
+	ViewerFollows      bool   `json:"viewerFollows"`
+	Blocked            bool   `json:"blocked"`
+}
+
+func (q *Queries) GetPresenceAccess(ctx context.Context, arg GetPresenceAccessParams) ([]GetPresenceAccessRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPresenceAccess, arg.ViewerID, pq.Array(arg.UserIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPresenceAccessRow
+	for rows.Next() {
+		var i GetPresenceAccessRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.PresenceVisibility,
+			&i.ViewerFollows,
+			&i.Blocked,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSuggestedUsers = `-- name: GetSuggestedUsers :many
+WITH excluded AS (
+    SELECT $2::int AS user_id
+    UNION SELECT followee_id FROM follows WHERE follower_id = $2::int
+    UNION SELECT blocked_id FROM blocks WHERE blocker_id = $2::int
+    UNION SELECT blocker_id FROM blocks WHERE blocked_id = $2::int
+    UNION SELECT muted_id FROM mutes WHERE muter_id = $2::int
+), friends_of_friends AS (
+    SELECT f2.followee_id AS user_id, COUNT(*) AS score
+    FROM follows f1
+    JOIN follows f2 ON f2.follower_id = f1.followee_id
+    WHERE f1.follower_id = $2::int
+    GROUP BY f2.followee_id
+), mutual_followers AS (
+    SELECT f2.followee_id AS user_id, COUNT(*) AS score
+    FROM follows f1
+    JOIN follows f2 ON f2.follower_id = f1.follower_id
+    WHERE f1.followee_id = $2::int
+    GROUP BY f2.followee_id
+), recently_joined AS (
+    SELECT user_id
+    FROM users
+    WHERE created_at > NOW() - INTERVAL '14 days'
+      AND deactivated_at IS NULL
+    ORDER BY created_at DESC
+    LIMIT 100
+)
+SELECT u.user_id, u.username, u.firstname, u.lastname, u.profile_image_id,
+       COALESCE(fof.score, 0)::int AS mutual_follows,
+       COALESCE(mf.score, 0)::int AS mutual_followers,
+       (rj.user_id IS NOT NULL)::bool AS recently_joined
+FROM users u
+LEFT JOIN friends_of_friends fof ON fof.user_id = u.user_id
+LEFT JOIN mutual_followers mf ON mf.user_id = u.user_id
+LEFT JOIN recently_joined rj ON rj.user_id = u.user_id
+WHERE (fof.user_id IS NOT NULL OR mf.user_id IS NOT NULL OR rj.user_id IS NOT NULL)
+  AND u.user_id NOT IN (SELECT user_id FROM excluded)
+  AND u.deactivated_at IS NULL
+ORDER BY COALESCE(fof.score, 0) * 3 + COALESCE(mf.score, 0) * 2 + (rj.user_id IS NOT NULL)::int DESC, u.user_id
+LIMIT $1::int
+`
+
+type GetSuggestedUsersParams struct {
+	MaxResults int32 `json:"maxResults"`
+	UserID     int32 `json:"userId"`
+}
+
+type GetSuggestedUsersRow struct {
+	UserID          int32         `json:"userId"`
+	Username        string        `json:"username"`
+	Firstname       string        `json:"firstname"`
+	Lastname        string        `json:"lastname"`
+	ProfileImageID  sql.NullInt32 `json:"profileImageId"`
+	MutualFollows   int32         `json:"mutualFollows"`
+	MutualFollowers int32         `json:"mutualFollowers"`
+	RecentlyJoined  bool          `json:"recentlyJoined"`
+}
+
+func (q *Queries) GetSuggestedUsers(ctx context.Context, arg GetSuggestedUsersParams) ([]GetSuggestedUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getSuggestedUsers, arg.MaxResults, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSuggestedUsersRow
+	for rows.Next() {
+		var i GetSuggestedUsersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.MutualFollows,
+			&i.MutualFollowers,
+			&i.RecentlyJoined,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserBannerImageByUserId = `-- name: GetUserBannerImageByUserId :one
+SELECT banner_image_id
+FROM users
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserBannerImageByUserId(ctx context.Context, userID int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getUserBannerImageByUserId, userID)
+	var banner_image_id sql.NullInt32
+	err := row.Scan(&banner_image_id)
+	return banner_image_id, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE user_id = $1 AND deactivated_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, userID int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, userID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Email,
+		&i.Firstname,
+		&i.Lastname,
+		&i.ProfileImageID,
+		&i.BannerImageID,
+		&i.CreatedAt,
+		&i.DeactivatedAt,
+		&i.PurgeAfter,
+		&i.UsernameChangedAt,
+		&i.PresenceVisibility,
+		&i.VerifiedBadge,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE LOWER(username) = LOWER($1::text) AND deactivated_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Email,
+		&i.Firstname,
+		&i.Lastname,
+		&i.ProfileImageID,
+		&i.BannerImageID,
+		&i.CreatedAt,
+		&i.DeactivatedAt,
+		&i.PurgeAfter,
+		&i.UsernameChangedAt,
+		&i.PresenceVisibility,
+		&i.VerifiedBadge,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getUserCardsByIds = `-- name: GetUserCardsByIds :many
+SELECT user_id, username, firstname, lastname, profile_image_id, verified_badge
+FROM users
+WHERE user_id = ANY($1::int[])
+  AND deactivated_at IS NULL
+`
+
+type GetUserCardsByIdsRow struct {
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Firstname      string        `json:"firstname"`
+	Lastname       string        `json:"lastname"`
+	ProfileImageID sql.NullInt32 `json:"profileImageId"`
+	VerifiedBadge  string        `json:"verifiedBadge"`
+}
+
+func (q *Queries) GetUserCardsByIds(ctx context.Context, userIds []int32) ([]GetUserCardsByIdsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserCardsByIds, pq.Array(userIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserCardsByIdsRow
+	for rows.Next() {
+		var i GetUserCardsByIdsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.VerifiedBadge,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE user_id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, userID int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserForUpdate, userID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Email,
+		&i.Firstname,
+		&i.Lastname,
+		&i.ProfileImageID,
+		&i.BannerImageID,
+		&i.CreatedAt,
+		&i.DeactivatedAt,
+		&i.PurgeAfter,
+		&i.UsernameChangedAt,
+		&i.PresenceVisibility,
+		&i.VerifiedBadge,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getUserProfileImageByUserId = `-- name: GetUserProfileImageByUserId :one
+SELECT profile_image_id
+FROM users
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserProfileImageByUserId(ctx context.Context, userID int32) (sql.NullInt32, error) {
+	row := q.db.QueryRowContext(ctx, getUserProfileImageByUserId, userID)
+	var profile_image_id sql.NullInt32
+	err := row.Scan(&profile_image_id)
+	return profile_image_id, err
+}
+
+const getUserRecord = `-- name: GetUserRecord :one
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE user_id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserRecord(ctx context.Context, userID int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserRecord, userID)
+	var i User
+	err := row.Scan(
+		&i.UserID,
+		&i.Username,
+		&i.Email,
+		&i.Firstname,
+		&i.Lastname,
+		&i.ProfileImageID,
+		&i.BannerImageID,
+		&i.CreatedAt,
+		&i.DeactivatedAt,
+		&i.PurgeAfter,
+		&i.UsernameChangedAt,
+		&i.PresenceVisibility,
+		&i.VerifiedBadge,
+		&i.VerifiedAt,
+	)
+	return i, err
+}
+
+const getUsernameRedirect = `-- name: GetUsernameRedirect :one
+SELECT u.user_id, u.username
+FROM username_history h
+JOIN users u ON u.user_id = h.user_id
+WHERE LOWER(h.username) = LOWER($1::text)
+  AND h.held_until > NOW()
+  AND u.deactivated_at IS NULL
+ORDER BY h.changed_at DESC
+LIMIT 1
+`
+
+type GetUsernameRedirectRow struct {
+	UserID   int32  `json:"userId"`
+	Username string `json:"username"`
+}
+
+func (q *Queries) GetUsernameRedirect(ctx context.Context, username string) (GetUsernameRedirectRow, error) {
+	row := q.db.QueryRowContext(ctx, getUsernameRedirect, username)
+	var i GetUsernameRedirectRow
+	err := row.Scan(&i.UserID, &i.Username)
+	return i, err
+}
+
+const getUsersDueForPurge = `-- name: GetUsersDueForPurge :many
+SELECT user_id
+FROM users
+WHERE purge_after <= NOW()
+ORDER BY purge_after
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) GetUsersDueForPurge(ctx context.Context, limit int32) ([]int32, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersDueForPurge, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var user_id int32
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getVerificationRequestForUpdate = `-- name: GetVerificationRequestForUpdate :one
+SELECT request_id, user_id, badge, reason, status, reviewer_id, review_note, created_at, reviewed_at FROM verification_requests WHERE request_id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetVerificationRequestForUpdate(ctx context.Context, requestID int32) (VerificationRequest, error) {
+	row := q.db.QueryRowContext(ctx, getVerificationRequestForUpdate, requestID)
+	var i VerificationRequest
+	err := row.Scan(
+		&i.RequestID,
+		&i.UserID,
+		&i.Badge,
+		&i.Reason,
+		&i.Status,
+		&i.ReviewerID,
+		&i.ReviewNote,
+		&i.CreatedAt,
+		&i.ReviewedAt,
+	)
+	return i, err
+}
+
+const incrementDataExportParts = `-- name: IncrementDataExportParts :one
+UPDATE data_exports SET parts_received = parts_received + 1
+WHERE export_id = $1 AND status = 'pending'
+RETURNING parts_received, parts_expected
+`
+
+type IncrementDataExportPartsRow struct {
+	PartsReceived int32 `json:"partsReceived"`
+	PartsExpected int32 `json:"partsExpected"`
+}
+
+func (q *Queries) IncrementDataExportParts(ctx context.Context, exportID int32) (IncrementDataExportPartsRow, error) {
+	row := q.db.QueryRowContext(ctx, incrementDataExportParts, exportID)
+	var i IncrementDataExportPartsRow
+	err := row.Scan(&i.PartsReceived, &i.PartsExpected)
+	return i, err
+}
+
+const isUsernameHeldByOther = `-- name: IsUsernameHeldByOther :one
+SELECT EXISTS (
+    SELECT 1 FROM username_history
+    WHERE LOWER(username) = LOWER($1::text)
+      AND held_until > NOW()
+      AND user_id <> $2::int
+)::bool
+`
+
+type IsUsernameHeldByOtherParams struct {
+	Username string `json:"username"`
+	UserID   int32  `json:"userId"`
+}
+
+func (q *Queries) IsUsernameHeldByOther(ctx context.Context, arg IsUsernameHeldByOtherParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUsernameHeldByOther, arg.Username, arg.UserID)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listDataExportParts = `-- name: ListDataExportParts :many
+SELECT export_id, service, payload, received_at FROM data_export_parts WHERE export_id = $1 ORDER BY service
+`
+
+func (q *Queries) ListDataExportParts(ctx context.Context, exportID int32) ([]DataExportPart, error) {
+	rows, err := q.db.QueryContext(ctx, listDataExportParts, exportID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DataExportPart
+	for rows.Next() {
+		var i DataExportPart
+		if err := rows.Scan(
+			&i.ExportID,
+			&i.Service,
+			&i.Payload,
+			&i.ReceivedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserRelationships = `-- name: ListUserRelationships :many
+SELECT 'following'::text AS kind, followee_id AS other_user_id, created_at FROM follows WHERE follower_id = $1::int
+UNION ALL
+SELECT 'follower'::text, follower_id, created_at FROM follows WHERE followee_id = $1::int
+UNION ALL
+SELECT 'blocked'::text, blocked_id, created_at FROM blocks WHERE blocker_id = $1::int
+UNION ALL
+SELECT 'muted'::text, muted_id, created_at FROM mutes WHERE muter_id = $1::int
+ORDER BY kind, created_at
+`
+
+type ListUserRelationshipsRow struct {
+	Kind        string    `json:"kind"`
+	OtherUserID int32     `json:"otherUserId"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func (q *Queries) ListUserRelationships(ctx context.Context, userID int32) ([]ListUserRelationshipsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUserRelationships, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserRelationshipsRow
+	for rows.Next() {
+		var i ListUserRelationshipsRow
+		if err := rows.Scan(&i.Kind, &i.OtherUserID, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersById = `-- name: ListUsersById :many
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE user_id > $1::int
+  AND deactivated_at IS NULL
+  AND ($2::text = '' OR username ILIKE $2::text || '%')
+  AND ($3::timestamptz IS NULL OR created_at > $3::timestamptz)
+ORDER BY user_id
+LIMIT $4::int
+`
+
+type ListUsersByIdParams struct {
+	AfterID        int32        `json:"afterId"`
+	UsernamePrefix string       `json:"usernamePrefix"`
+	JoinedAfter    sql.NullTime `json:"joinedAfter"`
+	PageLimit      int32        `json:"pageLimit"`
+}
+
+func (q *Queries) ListUsersById(ctx context.Context, arg ListUsersByIdParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersById,
+		arg.AfterID,
+		arg.UsernamePrefix,
+		arg.JoinedAfter,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Email,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.BannerImageID,
+			&i.CreatedAt,
+			&i.DeactivatedAt,
+			&i.PurgeAfter,
+			&i.UsernameChangedAt,
+			&i.PresenceVisibility,
+			&i.VerifiedBadge,
+			&i.VerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByNewest = `-- name: ListUsersByNewest :many
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE ($1::timestamptz IS NULL
+       OR (created_at, user_id) < ($1::timestamptz, $2::int))
+  AND deactivated_at IS NULL
+  AND ($3::text = '' OR username ILIKE $3::text || '%')
+  AND ($4::timestamptz IS NULL OR created_at > $4::timestamptz)
+ORDER BY created_at DESC, user_id DESC
+LIMIT $5::int
+`
+
+type ListUsersByNewestParams struct {
+	AfterCreatedAt sql.NullTime `json:"afterCreatedAt"`
+	AfterID        int32        `json:"afterId"`
+	UsernamePrefix string       `json:"usernamePrefix"`
+	JoinedAfter    sql.NullTime `json:"joinedAfter"`
+	PageLimit      int32        `json:"pageLimit"`
+}
+
+func (q *Queries) ListUsersByNewest(ctx context.Context, arg ListUsersByNewestParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByNewest,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.UsernamePrefix,
+		arg.JoinedAfter,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Email,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.BannerImageID,
+			&i.CreatedAt,
+			&i.DeactivatedAt,
+			&i.PurgeAfter,
+			&i.UsernameChangedAt,
+			&i.PresenceVisibility,
+			&i.VerifiedBadge,
+			&i.VerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByUsername = `-- name: ListUsersByUsername :many
+SELECT user_id, username, email, firstname, lastname, profile_image_id, banner_image_id, created_at, deactivated_at, purge_after, username_changed_at, presence_visibility, verified_badge, verified_at
+FROM users
+WHERE username > $1::text
+  AND deactivated_at IS NULL
+  AND ($2::text = '' OR username ILIKE $2::text || '%')
+  AND ($3::timestamptz IS NULL OR created_at > $3::timestamptz)
+ORDER BY username
+LIMIT $4::int
+`
+
+type ListUsersByUsernameParams struct {
+	AfterUsername  string       `json:"afterUsername"`
+	UsernamePrefix string       `json:"usernamePrefix"`
+	JoinedAfter    sql.NullTime `json:"joinedAfter"`
+	PageLimit      int32        `json:"pageLimit"`
+}
+
+func (q *Queries) ListUsersByUsername(ctx context.Context, arg ListUsersByUsernameParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByUsername,
+		arg.AfterUsername,
+		arg.UsernamePrefix,
+		arg.JoinedAfter,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Email,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.BannerImageID,
+			&i.CreatedAt,
+			&i.DeactivatedAt,
+			&i.PurgeAfter,
+			&i.UsernameChangedAt,
+			&i.PresenceVisibility,
+			&i.VerifiedBadge,
+			&i.VerifiedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVerificationAudit = `-- name: ListVerificationAudit :many
+SELECT id, user_id, action, badge, actor_id, request_id, note, created_at FROM verification_audit
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListVerificationAudit(ctx context.Context, userID int32) ([]VerificationAudit, error) {
+	rows, err := q.db.QueryContext(ctx, listVerificationAudit, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VerificationAudit
+	for rows.Next() {
+		var i VerificationAudit
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Action,
+			&i.Badge,
+			&i.ActorID,
+			&i.RequestID,
+			&i.Note,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listVerificationRequests = `-- name: ListVerificationRequests :many
+SELECT request_id, user_id, badge, reason, status, reviewer_id, review_note, created_at, reviewed_at FROM verification_requests
+WHERE status = $1
+ORDER BY created_at, request_id
+LIMIT $2 OFFSET $3
+`
+
+type ListVerificationRequestsParams struct {
+	Status string `json:"status"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListVerificationRequests(ctx context.Context, arg ListVerificationRequestsParams) ([]VerificationRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listVerificationRequests, arg.Status, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []VerificationRequest
+	for rows.Next() {
+		var i VerificationRequest
+		if err := rows.Scan(
+			&i.RequestID,
+			&i.UserID,
+			&i.Badge,
+			&i.Reason,
+			&i.Status,
+			&i.ReviewerID,
+			&i.ReviewNote,
+			&i.CreatedAt,
+			&i.ReviewedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const reactivateUser = `-- name: ReactivateUser :execrows
+UPDATE users SET deactivated_at = NULL, purge_after = NULL
+WHERE user_id = $1 AND deactivated_at IS NOT NULL
+`
+
+func (q *Queries) ReactivateUser(ctx context.Context, userID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reactivateUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const renameUser = `-- name: RenameUser :exec
+UPDATE users
+SET username = $2,
+    username_changed_at = CASE WHEN $3::bool THEN NOW() ELSE username_changed_at END
+WHERE user_id = $1
+`
+
+type RenameUserParams struct {
+	UserID        int32  `json:"userId"`
+	Username      string `json:"username"`
+	StartCooldown bool   `json:"startCooldown"`
+}
+
+// a change of case only does not start the rename cool-down
+func (q *Queries) RenameUser(ctx context.Context, arg RenameUserParams) error {
+	_, err := q.db.ExecContext(ctx, renameUser, arg.UserID, arg.Username, arg.StartCooldown)
+	return err
+}
+
+const reviewVerificationRequest = `-- name: ReviewVerificationRequest :exec
+UPDATE verification_requests
+SET status = $2, reviewer_id = $3, review_note = $4, reviewed_at = NOW()
+WHERE request_id = $1
+`
+
+type ReviewVerificationRequestParams struct {
+	RequestID  int32         `json:"requestId"`
+	Status     string        `json:"status"`
+	ReviewerID sql.NullInt32 `json:"reviewerId"`
+	ReviewNote string        `json:"reviewNote"`
+}
+
+func (q *Queries) ReviewVerificationRequest(ctx context.Context, arg ReviewVerificationRequestParams) error {
+	_, err := q.db.ExecContext(ctx, reviewVerificationRequest,
+		arg.RequestID,
+		arg.Status,
+		arg.ReviewerID,
+		arg.ReviewNote,
+	)
+	return err
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT user_id, username, firstname, lastname, profile_image_id,
+       GREATEST(
+           similarity(username, $1::text),
+           similarity(firstname, $1::text),
+           similarity(lastname, $1::text),
+           similarity(firstname || ' ' || lastname, $1::text)
+       )::real AS rank
+FROM users
+WHERE (username % $1::text
+    OR firstname % $1::text
+    OR lastname % $1::text
+    OR username ILIKE $1::text || '%')
+  AND deactivated_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM blocks b
+      WHERE (b.blocker_id = $2::int AND b.blocked_id = users.user_id)
+         OR (b.blocker_id = users.user_id AND b.blocked_id = $2::int)
+  )
+ORDER BY rank DESC, user_id
+LIMIT $4::int OFFSET $3::int
+`
+
+type SearchUsersParams struct {
+	Query      string `json:"query"`
+	ViewerID   int32  `json:"viewerId"`
+	PageOffset int32  `json:"pageOffset"`
+	PageLimit  int32  `json:"pageLimit"`
+}
+
+type SearchUsersRow struct {
+	UserID         int32         `json:"userId"`
+	Username       string        `json:"username"`
+	Firstname      string        `json:"firstname"`
+	Lastname       string        `json:"lastname"`
+	ProfileImageID sql.NullInt32 `json:"profileImageId"`
+	Rank           float32       `json:"rank"`
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers,
+		arg.Query,
+		arg.ViewerID,
+		arg.PageOffset,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchUsersRow
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Firstname,
+			&i.Lastname,
+			&i.ProfileImageID,
+			&i.Rank,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setUserVerifiedBadge = `-- name: SetUserVerifiedBadge :execrows
+UPDATE users SET verified_badge = $2, verified_at = NOW()
+WHERE user_id = $1 AND deactivated_at IS NULL
+`
+
+type SetUserVerifiedBadgeParams struct {
+	UserID        int32  `json:"userId"`
+	VerifiedBadge string `json:"verifiedBadge"`
+}
+
+func (q *Queries) SetUserVerifiedBadge(ctx context.Context, arg SetUserVerifiedBadgeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserVerifiedBadge, arg.UserID, arg.VerifiedBadge)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateList = `-- name: UpdateList :exec
+UPDATE lists
+SET name = $2, description = $3, is_private = $4, updated_at = NOW()
+WHERE list_id = $1
+`
+
+type UpdateListParams struct {
+	ListID      int32  `json:"listId"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"isPrivate"`
+}
+
+func (q *Queries) UpdateList(ctx context.Context, arg UpdateListParams) error {
+	_, err := q.db.ExecContext(ctx, updateList,
+		arg.ListID,
+		arg.Name,
+		arg.Description,
+		arg.IsPrivate,
+	)
+	return err
+}
+
+const updatePresenceVisibility = `-- name: UpdatePresenceVisibility :execrows
+UPDATE users SET presence_visibility = $2 WHERE user_id = $1
+`
+
+type UpdatePresenceVisibilityParams struct {
+	UserID             int32  `json:"userId"`
+	PresenceVisibility string `json:"presenceVisibility"`
+}
+
+func (q *Queries) UpdatePresenceVisibility(ctx context.Context, arg UpdatePresenceVisibilityParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updatePresenceVisibility, arg.UserID, arg.PresenceVisibility)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updateUser = `-- name: UpdateUser :exec
+UPDATE users SET 
+username = COALESCE(nullif($2, ''), username),
+firstname = COALESCE(nullif($3, ''), firstname), 
+lastname = COALESCE(nullif($4, ''),  lastname)
+WHERE user_id = $1
+`
+
+type UpdateUserParams struct {
+	UserID  int32       `json:"userId"`
+	Column2 interface{} `json:"column2"`
+	Column3 interface{} `json:"column3"`
+	Column4 interface{} `json:"column4"`
+}
+
+func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
+	_, err := q.db.ExecContext(ctx, updateUser,
+		arg.UserID,
+		arg.Column2,
+		arg.Column3,
+		arg.Column4,
+	)
+	return err
+}
+
+const updateUserBannerImage = `-- name: UpdateUserBannerImage :exec
+UPDATE users SET banner_image_id = $2 WHERE user_id = $1
+`
+
+type UpdateUserBannerImageParams struct {
+	UserID        int32         `json:"userId"`
+	BannerImageID sql.NullInt32 `json:"bannerImageId"`
+}
+
+func (q *Queries) UpdateUserBannerImage(ctx context.Context, arg UpdateUserBannerImageParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserBannerImage, arg.UserID, arg.BannerImageID)
 	return err
 }
 